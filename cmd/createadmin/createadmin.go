@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cleysonph/hyperprof/config"
+	"github.com/cleysonph/hyperprof/internal/database"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/service"
+	"github.com/joho/godotenv"
+	"golang.org/x/term"
+)
+
+// readPassword returns the password of the administrator, so it never shows
+// up in the shell history or the process list. It is taken from
+// ADMIN_PASSWORD when set, otherwise it is asked for twice on a terminal or
+// read from the first line of stdin.
+func readPassword() (password string, confirmation string, err error) {
+	if password := os.Getenv("ADMIN_PASSWORD"); password != "" {
+		return password, password, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", "", errors.New("no password given on stdin")
+		}
+		password := strings.TrimRight(line, "\r\n")
+		return password, password, nil
+	}
+
+	fmt.Fprint(os.Stderr, "Senha: ")
+	passwordBytes, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", "", err
+	}
+	fmt.Fprint(os.Stderr, "Confirme a senha: ")
+	confirmationBytes, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", "", err
+	}
+	return string(passwordBytes), string(confirmationBytes), nil
+}
+
+func main() {
+	nome := flag.String("nome", "", "nome do administrador")
+	email := flag.String("email", "", "email do administrador")
+	flag.Parse()
+
+	password, confirmation, err := readPassword()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error reading the password:", err)
+		os.Exit(1)
+	}
+
+	if config.IsDev() {
+		if err := godotenv.Load(); err != nil {
+			fmt.Fprintln(os.Stderr, "Error loading .env file:", err)
+			os.Exit(1)
+		}
+	}
+
+	config.Init()
+
+	database.InitMySQL(config.Dsn)
+	defer database.Close()
+
+	administrador, err := service.CreateAdministrador(&model.Administrador{
+		Nome:     *nome,
+		Email:    *email,
+		Password: password,
+	}, confirmation)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if validationErr, ok := err.(*model.ValidationError); ok {
+			for field, messages := range validationErr.Errors {
+				for _, message := range messages {
+					fmt.Fprintf(os.Stderr, "  %s %s\n", field, message)
+				}
+			}
+		}
+		os.Exit(1)
+	}
+
+	fmt.Printf("Administrador %s created with ID %d\n", administrador.Email, administrador.ID)
+}
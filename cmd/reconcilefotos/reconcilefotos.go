@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cleysonph/hyperprof/config"
+	"github.com/cleysonph/hyperprof/internal/app"
+	"github.com/cleysonph/hyperprof/internal/database"
+	"github.com/cleysonph/hyperprof/internal/service"
+	"github.com/cleysonph/hyperprof/internal/storage"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", true, "lista os arquivos sem removê-los, use -dry-run=false para removê-los")
+	grace := flag.Duration("grace", time.Hour, "ignora os arquivos modificados há menos tempo, cujo envio pode estar em andamento")
+	flag.Parse()
+
+	if config.IsDev() {
+		if err := godotenv.Load(); err != nil {
+			fmt.Fprintln(os.Stderr, "Error loading .env file:", err)
+			os.Exit(1)
+		}
+	}
+
+	config.Init()
+
+	database.InitMySQL(config.Dsn)
+	defer database.Close()
+
+	fileStorage, err := app.NewStorage()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	storage.Init(fileStorage)
+
+	orphans, err := service.ReconcileFotos(*dryRun, *grace)
+	for _, key := range orphans {
+		fmt.Println(key)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *dryRun {
+		fmt.Printf("%d unreferenced files found\n", len(orphans))
+	} else {
+		fmt.Printf("%d unreferenced files removed\n", len(orphans))
+	}
+}
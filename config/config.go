@@ -4,19 +4,71 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 )
 
 const Version = "0.0.1"
 
 var (
-	Port            int
-	Env             string = "dev"
-	Dsn             string
-	Host            string
-	TokenSecret     string
-	TokenDuration   int64
-	RefreshSecret   string
-	RefreshDuration int64
+	Port               int
+	Env                string = "dev"
+	Dsn                string
+	Host               string
+	TokenSecret        string
+	TokenDuration      int64
+	RefreshSecret      string
+	RefreshDuration    int64
+	Location           *time.Location = time.Local
+	ManageSecret       string
+	CancellationWindow int64
+	AppURL             string
+	Mailer             string = "log"
+	MailFrom           string
+	MailDir            string
+	SMTPHost           string
+	SMTPPort           int
+	SMTPUsername       string
+	SMTPPassword       string
+	ResetDuration      int64
+	ResetResendDelay   int64
+	VerifyDuration     int64
+	VerifyResendDelay  int64
+	MfaDuration        int64
+	TrustProxy         bool
+	JwtKeysDir         string
+	JwtActiveKid       string
+	JanitorInterval    int64
+	LoginTracker       string
+	LoginMaxAttempts   int64
+	LoginMaxIPAttempts int64
+	LoginLockout       int64
+	LoginMaxLockout    int64
+	RateLimitStore     string
+	RateLimitDefault   string
+	RateLimitUser      string
+	RateLimitAuth      string
+	RateLimitBooking   string
+	RedisAddr          string
+	RedisPassword      string
+	RedisDB            int
+	OidcRedirectURL    string
+	GoogleIssuer       string
+	GoogleClientID     string
+	GoogleSecret       string
+	MicrosoftIssuer    string
+	MicrosoftClientID  string
+	MicrosoftSecret    string
+	SearchEngine       string
+	PublicURL          string
+	StorageDriver      string
+	StorageDir         string
+	S3Endpoint         string
+	S3Region           string
+	S3Bucket           string
+	S3AccessKey        string
+	S3SecretKey        string
+	S3PathStyle        bool
+	S3PresignExpiry    int64
 )
 
 func Init() {
@@ -28,6 +80,57 @@ func Init() {
 	TokenDuration = stringToInt64(os.Getenv("TOKEN_DURATION"))
 	RefreshSecret = os.Getenv("REFRESH_SECRET")
 	RefreshDuration = stringToInt64(os.Getenv("REFRESH_DURATION"))
+	Location = stringToLocation(os.Getenv("TIMEZONE"))
+	ManageSecret = os.Getenv("MANAGE_SECRET")
+	CancellationWindow = stringToInt64(os.Getenv("CANCELLATION_WINDOW"))
+	AppURL = os.Getenv("APP_URL")
+	Mailer = os.Getenv("MAILER")
+	MailFrom = os.Getenv("MAIL_FROM")
+	MailDir = os.Getenv("MAIL_DIR")
+	SMTPHost = os.Getenv("SMTP_HOST")
+	SMTPPort = stringToInt(os.Getenv("SMTP_PORT"))
+	SMTPUsername = os.Getenv("SMTP_USERNAME")
+	SMTPPassword = os.Getenv("SMTP_PASSWORD")
+	ResetDuration = stringToInt64(os.Getenv("RESET_DURATION"))
+	ResetResendDelay = stringToInt64(os.Getenv("RESET_RESEND_DELAY"))
+	VerifyDuration = stringToInt64(os.Getenv("VERIFY_DURATION"))
+	VerifyResendDelay = stringToInt64(os.Getenv("VERIFY_RESEND_DELAY"))
+	MfaDuration = stringToInt64(os.Getenv("MFA_DURATION"))
+	TrustProxy = os.Getenv("TRUST_PROXY") == "true"
+	JwtKeysDir = os.Getenv("JWT_KEYS_DIR")
+	JwtActiveKid = os.Getenv("JWT_ACTIVE_KID")
+	JanitorInterval = stringToInt64(os.Getenv("JANITOR_INTERVAL"))
+	LoginTracker = os.Getenv("LOGIN_TRACKER")
+	LoginMaxAttempts = stringToInt64(os.Getenv("LOGIN_MAX_ATTEMPTS"))
+	LoginMaxIPAttempts = stringToInt64(os.Getenv("LOGIN_MAX_IP_ATTEMPTS"))
+	LoginLockout = stringToInt64(os.Getenv("LOGIN_LOCKOUT"))
+	LoginMaxLockout = stringToInt64(os.Getenv("LOGIN_MAX_LOCKOUT"))
+	RateLimitStore = os.Getenv("RATE_LIMIT_STORE")
+	RateLimitDefault = os.Getenv("RATE_LIMIT_DEFAULT")
+	RateLimitUser = os.Getenv("RATE_LIMIT_USER")
+	RateLimitAuth = os.Getenv("RATE_LIMIT_AUTH")
+	RateLimitBooking = os.Getenv("RATE_LIMIT_BOOKING")
+	RedisAddr = os.Getenv("REDIS_ADDR")
+	RedisPassword = os.Getenv("REDIS_PASSWORD")
+	RedisDB = stringToInt(os.Getenv("REDIS_DB"))
+	OidcRedirectURL = os.Getenv("OIDC_REDIRECT_URL")
+	GoogleIssuer = os.Getenv("OIDC_GOOGLE_ISSUER")
+	GoogleClientID = os.Getenv("OIDC_GOOGLE_CLIENT_ID")
+	GoogleSecret = os.Getenv("OIDC_GOOGLE_CLIENT_SECRET")
+	MicrosoftIssuer = os.Getenv("OIDC_MICROSOFT_ISSUER")
+	MicrosoftClientID = os.Getenv("OIDC_MICROSOFT_CLIENT_ID")
+	MicrosoftSecret = os.Getenv("OIDC_MICROSOFT_CLIENT_SECRET")
+	SearchEngine = os.Getenv("SEARCH_ENGINE")
+	PublicURL = os.Getenv("PUBLIC_URL")
+	StorageDriver = os.Getenv("STORAGE_DRIVER")
+	StorageDir = os.Getenv("STORAGE_DIR")
+	S3Endpoint = os.Getenv("S3_ENDPOINT")
+	S3Region = os.Getenv("S3_REGION")
+	S3Bucket = os.Getenv("S3_BUCKET")
+	S3AccessKey = os.Getenv("S3_ACCESS_KEY")
+	S3SecretKey = os.Getenv("S3_SECRET_KEY")
+	S3PathStyle = os.Getenv("S3_PATH_STYLE") == "true"
+	S3PresignExpiry = stringToInt64(os.Getenv("S3_PRESIGN_EXPIRY"))
 }
 
 func stringToInt(s string) int {
@@ -46,6 +149,18 @@ func stringToInt64(s string) int64 {
 	return i
 }
 
+func stringToLocation(s string) *time.Location {
+	if s == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(s)
+	if err != nil {
+		fmt.Println("Error loading location, falling back to local time")
+		return time.Local
+	}
+	return loc
+}
+
 func Addr() string {
 	return fmt.Sprintf("%s:%d", Host, Port)
 }
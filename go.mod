@@ -1,20 +1,24 @@
 module github.com/cleysonph/hyperprof
 
-go 1.19
+go 1.23
 
 require github.com/go-sql-driver/mysql v1.6.0
 
 require (
+	github.com/gen2brain/webp v0.5.5
 	github.com/golang-jwt/jwt/v4 v4.4.2
 	github.com/google/uuid v1.3.0
 	github.com/gorilla/mux v1.8.0
 	github.com/joho/godotenv v1.4.0
 	github.com/rs/zerolog v1.28.0
 	golang.org/x/crypto v0.1.0
+	golang.org/x/term v0.1.0
 )
 
 require (
+	github.com/ebitengine/purego v0.8.3 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.16 // indirect
+	github.com/tetratelabs/wazero v1.9.0 // indirect
 	golang.org/x/sys v0.1.0 // indirect
 )
@@ -1,12 +1,26 @@
 package app
 
 import (
+	"context"
+	"fmt"
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/cleysonph/hyperprof/config"
 	"github.com/cleysonph/hyperprof/internal/database"
+	"github.com/cleysonph/hyperprof/internal/lockout"
+	"github.com/cleysonph/hyperprof/internal/mailer"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/oidc"
+	"github.com/cleysonph/hyperprof/internal/ratelimit"
+	"github.com/cleysonph/hyperprof/internal/search"
+	"github.com/cleysonph/hyperprof/internal/service"
+	"github.com/cleysonph/hyperprof/internal/storage"
 	"github.com/cleysonph/hyperprof/internal/transport/middleware"
 	"github.com/cleysonph/hyperprof/internal/transport/rest"
 	"github.com/joho/godotenv"
@@ -14,6 +28,10 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// shutdownTimeout bounds how long in-flight requests may take to finish once
+// the server is asked to stop.
+const shutdownTimeout = 10 * time.Second
+
 func Run() {
 	if config.IsDev() {
 		if err := godotenv.Load(); err != nil {
@@ -24,23 +42,206 @@ func Run() {
 
 	config.Init()
 
+	if err := service.LoadSigningKeys(); err != nil {
+		log.Fatal().Err(err).Msg("failed to load signing keys")
+	}
+
 	database.InitMySQL(config.Dsn)
 	defer database.Close()
 
+	messageMailer, err := newMailer()
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to configure the mailer")
+	}
+	mailer.Init(messageMailer)
+	lockout.Init(newLoginTracker())
+	ratelimit.Init(newRateLimitStore())
+	search.Init(newSearcher())
+	registerOidcProviders()
+
+	fileStorage, err := NewStorage()
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to configure the storage")
+	}
+	storage.Init(fileStorage)
+
 	listener, err := net.Listen("tcp", config.Addr())
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to listen")
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	tasks := []janitorTask{
+		{name: "invalidated_tokens", run: service.PurgeExpiredInvalidatedTokens},
+		{name: "login_attempts", run: service.PurgeStaleLoginAttempts},
+		{name: "oidc_states", run: service.PurgeExpiredOidcStates},
+	}
+	// MySQL searches the table itself, only the memory searcher has an index
+	// to build and to catch up with the changes of other instances.
+	if config.SearchEngine == "memory" {
+		if _, err := service.RebuildSearchIndex(); err != nil {
+			log.Fatal().Err(err).Msg("failed to build the search index")
+		}
+		tasks = append(tasks, janitorTask{name: "search_index", run: service.RebuildSearchIndex})
+	}
+	janitor := startJanitor(ctx, time.Duration(config.JanitorInterval)*time.Second, tasks...)
+
 	log.Info().Msgf("Listening on %s", listener.Addr().String())
-	handler := middleware.HttpCors(
-		middleware.HttpLogger(
-			rest.NewRouter(),
+	server := &http.Server{
+		Handler: middleware.HttpCors(
+			middleware.HttpLogger(
+				rest.NewRouter(),
+			),
 		),
-	)
+	}
 
-	err = http.Serve(listener, handler)
-	if err != nil {
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		log.Info().Msg("shutting down")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Error().Err(err).Msg("failed to shut down gracefully")
+		}
+	}()
+
+	err = server.Serve(listener)
+	if err != nil && err != http.ErrServerClosed {
 		log.Fatal().Err(err).Msg("failed to serve")
 	}
+
+	// Serve returns as soon as Shutdown is called, wait for the in-flight
+	// requests and the background workers before closing the database.
+	janitor.Wait()
+	<-shutdownDone
+}
+
+// newMailer returns the mailer selected by the configuration. The log mailer
+// never delivers anything, so it is refused outside development rather than
+// silently losing the emails.
+func newMailer() (mailer.Mailer, error) {
+	switch config.Mailer {
+	case "smtp":
+		return mailer.NewSMTPMailer(
+			config.SMTPHost,
+			config.SMTPPort,
+			config.SMTPUsername,
+			config.SMTPPassword,
+			config.MailFrom,
+		), nil
+	case "", "log":
+		if !config.IsDev() {
+			return nil, fmt.Errorf("MAILER must be smtp when ENV is %q", config.Env)
+		}
+		return mailer.NewLogMailer(config.MailDir), nil
+	default:
+		return nil, fmt.Errorf("unknown MAILER %q", config.Mailer)
+	}
+}
+
+func newLoginTracker() lockout.Tracker {
+	if config.LoginTracker == "database" {
+		return lockout.NewDatabaseTracker()
+	}
+	return lockout.NewMemoryTracker()
+}
+
+func newRateLimitStore() ratelimit.Store {
+	if config.RateLimitStore == "redis" {
+		return ratelimit.NewRedisStore(config.RedisAddr, config.RedisPassword, config.RedisDB)
+	}
+	return ratelimit.NewMemoryStore()
+}
+
+// newSearcher defaults to the MySQL searcher. The memory one only sees the
+// changes made by its own process, so it is meant for a single instance.
+func newSearcher() search.Searcher {
+	if config.SearchEngine == "memory" {
+		return search.NewMemorySearcher()
+	}
+	return search.NewMySQLSearcher()
+}
+
+// Default issuers of the providers professors can sign in with. Microsoft
+// accepts accounts of any tenant through its common endpoint.
+const (
+	defaultGoogleIssuer    = "https://accounts.google.com"
+	defaultMicrosoftIssuer = "https://login.microsoftonline.com/common/v2.0"
+)
+
+// registerOidcProviders enables the providers that have a client configured.
+// The issuers can point to a local provider during development.
+func registerOidcProviders() {
+	redirectURL := config.OidcRedirectURL
+	if redirectURL == "" {
+		redirectURL = config.AppURL + "/oidc/callback"
+	}
+
+	if config.GoogleClientID != "" {
+		oidc.Register(oidc.NewProvider(
+			model.OidcProviderGoogle,
+			withDefault(config.GoogleIssuer, defaultGoogleIssuer),
+			config.GoogleClientID,
+			config.GoogleSecret,
+			redirectURL,
+		))
+	}
+
+	if config.MicrosoftClientID != "" {
+		provider := oidc.NewProvider(
+			model.OidcProviderMicrosoft,
+			withDefault(config.MicrosoftIssuer, defaultMicrosoftIssuer),
+			config.MicrosoftClientID,
+			config.MicrosoftSecret,
+			redirectURL,
+		)
+		// Microsoft does not send email_verified, this optional claim must be
+		// enabled in the app registration instead
+		provider.EmailVerifiedClaim = "xms_edov"
+		oidc.Register(provider)
+	}
+}
+
+func withDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// arquivosPath is the route of the API serving the stored files, for the
+// storages whose files clients cannot download directly.
+const arquivosPath = "/api/arquivos"
+
+// NewStorage returns the storage selected by the configuration.
+func NewStorage() (storage.Storage, error) {
+	publicURL := config.PublicURL
+	if publicURL == "" {
+		publicURL = "http://" + config.Addr()
+	}
+	proxyURL := strings.TrimSuffix(publicURL, "/") + arquivosPath
+
+	if config.StorageDriver == "s3" {
+		return storage.NewS3Storage(storage.S3Config{
+			Endpoint:      config.S3Endpoint,
+			Region:        config.S3Region,
+			Bucket:        config.S3Bucket,
+			AccessKey:     config.S3AccessKey,
+			SecretKey:     config.S3SecretKey,
+			PathStyle:     config.S3PathStyle,
+			PresignExpiry: time.Duration(config.S3PresignExpiry) * time.Second,
+			ProxyURL:      proxyURL,
+		})
+	}
+
+	dir := config.StorageDir
+	if dir == "" {
+		dir = "uploads"
+	}
+	return storage.NewLocalStorage(dir, proxyURL), nil
 }
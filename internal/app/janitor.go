@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// defaultJanitorInterval is used when JANITOR_INTERVAL is not configured.
+const defaultJanitorInterval = time.Hour
+
+// janitorTask is a maintenance job run periodically in the background. It
+// returns how many records it removed, or rebuilt.
+type janitorTask struct {
+	name string
+	run  func() (int64, error)
+}
+
+// startJanitor runs every task once per interval until ctx is cancelled. The
+// returned WaitGroup is done once the worker has stopped.
+func startJanitor(ctx context.Context, interval time.Duration, tasks ...janitorTask) *sync.WaitGroup {
+	if interval <= 0 {
+		interval = defaultJanitorInterval
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			for _, task := range tasks {
+				if ctx.Err() != nil {
+					return
+				}
+				records, err := task.run()
+				if err != nil {
+					log.Error().Err(err).Str("task", task.name).Msg("janitor task failed")
+					continue
+				}
+				log.Debug().Str("task", task.name).Int64("records", records).Msg("janitor task finished")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return &wg
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+const findAdministradorByIDQuery = `
+SELECT
+	id,
+	nome,
+	email,
+	created_at,
+	updated_at
+FROM
+	administradores
+WHERE
+	id = ?
+LIMIT 1
+`
+
+func FindAdministradorByID(id int64) (*model.Administrador, error) {
+	row := db.QueryRow(findAdministradorByIDQuery, id)
+	administrador := &model.Administrador{}
+	err := row.Scan(
+		&administrador.ID,
+		&administrador.Nome,
+		&administrador.Email,
+		&administrador.CreatedAt,
+		&administrador.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return administrador, nil
+}
+
+const findAdministradorByEmailQuery = `
+SELECT
+	id,
+	nome,
+	email,
+	password,
+	created_at,
+	updated_at
+FROM
+	administradores
+WHERE
+	email = ?
+LIMIT 1
+`
+
+func FindAdministradorByEmail(email string) (*model.Administrador, error) {
+	row := db.QueryRow(findAdministradorByEmailQuery, email)
+	administrador := &model.Administrador{}
+	err := row.Scan(
+		&administrador.ID,
+		&administrador.Nome,
+		&administrador.Email,
+		&administrador.Password,
+		&administrador.CreatedAt,
+		&administrador.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return administrador, nil
+}
+
+const existsAdministradorByEmailQuery = `
+SELECT
+	email
+FROM
+	administradores
+WHERE
+	email = ?
+LIMIT 1
+`
+
+func ExistsAdministradorByEmail(email string) bool {
+	row := db.QueryRow(existsAdministradorByEmailQuery, email)
+	var administradorEmail string
+	err := row.Scan(&administradorEmail)
+	return err == nil && administradorEmail == email
+}
+
+const createAdministradorQuery = `
+INSERT INTO
+	administradores (nome, email, password)
+VALUES
+	(?, ?, ?)
+`
+
+func CreateAdministrador(administrador *model.Administrador) (*model.Administrador, error) {
+	result, err := db.Exec(
+		createAdministradorQuery,
+		administrador.Nome,
+		administrador.Email,
+		administrador.Password,
+	)
+	if err != nil {
+		return nil, err
+	}
+	administradorID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return FindAdministradorByID(administradorID)
+}
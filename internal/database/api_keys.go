@@ -0,0 +1,199 @@
+package database
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+// Scopes are stored as a comma separated list.
+const scopesSeparator = ","
+
+const createApiKeyQuery = `
+INSERT INTO
+	api_keys (administrador_id, nome, prefix, key_hash, scopes)
+VALUES
+	(?, ?, ?, ?, ?)
+`
+
+func CreateApiKey(apiKey *model.ApiKey) (*model.ApiKey, error) {
+	result, err := db.Exec(
+		createApiKeyQuery,
+		apiKey.AdministradorID,
+		apiKey.Nome,
+		apiKey.Prefix,
+		apiKey.KeyHash,
+		strings.Join(apiKey.Scopes, scopesSeparator),
+	)
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return FindApiKeyByID(id)
+}
+
+const findApiKeyByIDQuery = `
+SELECT
+	id,
+	administrador_id,
+	nome,
+	prefix,
+	key_hash,
+	scopes,
+	last_used_at,
+	last_used_ip,
+	revoked_at,
+	created_at,
+	updated_at
+FROM
+	api_keys
+WHERE
+	id = ?
+LIMIT 1
+`
+
+func FindApiKeyByID(id int64) (*model.ApiKey, error) {
+	return scanApiKey(db.QueryRow(findApiKeyByIDQuery, id))
+}
+
+const findActiveApiKeyByKeyHashQuery = `
+SELECT
+	id,
+	administrador_id,
+	nome,
+	prefix,
+	key_hash,
+	scopes,
+	last_used_at,
+	last_used_ip,
+	revoked_at,
+	created_at,
+	updated_at
+FROM
+	api_keys
+WHERE
+	key_hash = ?
+AND
+	revoked_at IS NULL
+LIMIT 1
+`
+
+func FindActiveApiKeyByKeyHash(keyHash string) (*model.ApiKey, error) {
+	return scanApiKey(db.QueryRow(findActiveApiKeyByKeyHashQuery, keyHash))
+}
+
+const findAllApiKeysQuery = `
+SELECT
+	id,
+	administrador_id,
+	nome,
+	prefix,
+	key_hash,
+	scopes,
+	last_used_at,
+	last_used_ip,
+	revoked_at,
+	created_at,
+	updated_at
+FROM
+	api_keys
+ORDER BY
+	created_at DESC
+`
+
+func FindAllApiKeys() ([]*model.ApiKey, error) {
+	rows, err := db.Query(findAllApiKeysQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	apiKeys := make([]*model.ApiKey, 0)
+	for rows.Next() {
+		apiKey, err := scanApiKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanApiKey(row scanner) (*model.ApiKey, error) {
+	apiKey := &model.ApiKey{}
+	var scopes string
+	err := row.Scan(
+		&apiKey.ID,
+		&apiKey.AdministradorID,
+		&apiKey.Nome,
+		&apiKey.Prefix,
+		&apiKey.KeyHash,
+		&scopes,
+		&apiKey.LastUsedAt,
+		&apiKey.LastUsedIP,
+		&apiKey.RevokedAt,
+		&apiKey.CreatedAt,
+		&apiKey.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	apiKey.Scopes = strings.Split(scopes, scopesSeparator)
+	return apiKey, nil
+}
+
+// Writes are skipped while the key was used recently, so busy integrations do
+// not update the row on every request.
+const touchApiKeyQuery = `
+UPDATE
+	api_keys
+SET
+	last_used_at = ?,
+	last_used_ip = ?
+WHERE
+	id = ?
+AND
+	(last_used_at IS NULL OR last_used_at < ?)
+`
+
+// TouchApiKey records that the key was used from ip at now, unless it was
+// already used after staleBefore.
+func TouchApiKey(id int64, ip string, now, staleBefore time.Time) error {
+	_, err := db.Exec(touchApiKeyQuery, now, ip, id, staleBefore)
+	return err
+}
+
+const revokeApiKeyQuery = `
+UPDATE
+	api_keys
+SET
+	revoked_at = CURRENT_TIMESTAMP
+WHERE
+	id = ?
+AND
+	revoked_at IS NULL
+`
+
+// RevokeApiKey returns false when the key did not exist or was already
+// revoked.
+func RevokeApiKey(id int64) (bool, error) {
+	result, err := db.Exec(revokeApiKeyQuery, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,217 @@
+package database
+
+import (
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+const createAvaliacaoQuery = `
+INSERT INTO
+	avaliacoes (aluno_id, professor_id, nota, comentario)
+VALUES
+	(?, ?, ?, ?)
+`
+
+// updateProfessorAvaliacoesQuery recomputes the rating of a professor from
+// their reviews. Setting updated_at to itself keeps a review from looking like
+// a change of the profile.
+const updateProfessorAvaliacoesQuery = `
+UPDATE
+	professores
+SET
+	avaliacao_media = (SELECT COALESCE(AVG(nota), 0) FROM avaliacoes WHERE professor_id = ?),
+	avaliacoes_count = (SELECT COUNT(*) FROM avaliacoes WHERE professor_id = ?),
+	updated_at = updated_at
+WHERE
+	id = ?
+`
+
+// CreateAvaliacao saves the review and updates the rating of the professor
+// along with it.
+func CreateAvaliacao(avaliacao *model.Avaliacao) (*model.Avaliacao, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		createAvaliacaoQuery,
+		avaliacao.AlunoID,
+		avaliacao.ProfessorID,
+		avaliacao.Nota,
+		avaliacao.Comentario,
+	)
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(
+		updateProfessorAvaliacoesQuery,
+		avaliacao.ProfessorID,
+		avaliacao.ProfessorID,
+		avaliacao.ProfessorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return FindAvaliacaoByID(id)
+}
+
+const findAvaliacaoByIDQuery = `
+SELECT
+	av.id,
+	av.aluno_id,
+	av.professor_id,
+	al.nome,
+	av.nota,
+	av.comentario,
+	av.resposta,
+	av.respondida_em,
+	av.created_at,
+	av.updated_at
+FROM
+	avaliacoes av
+INNER JOIN
+	alunos al ON al.id = av.aluno_id
+WHERE
+	av.id = ?
+LIMIT 1
+`
+
+func FindAvaliacaoByID(id int64) (*model.Avaliacao, error) {
+	return scanAvaliacao(db.QueryRow(findAvaliacaoByIDQuery, id))
+}
+
+const existsAvaliacaoByAlunoIDQuery = `
+SELECT
+	aluno_id
+FROM
+	avaliacoes
+WHERE
+	aluno_id = ?
+LIMIT 1
+`
+
+func ExistsAvaliacaoByAlunoID(alunoID int64) bool {
+	row := db.QueryRow(existsAvaliacaoByAlunoIDQuery, alunoID)
+	var id int64
+	err := row.Scan(&id)
+	return err == nil && id == alunoID
+}
+
+const countAvaliacoesByProfessorIDQuery = `
+SELECT
+	COUNT(*)
+FROM
+	avaliacoes
+WHERE
+	professor_id = ?
+`
+
+const findAvaliacoesByProfessorIDQuery = `
+SELECT
+	av.id,
+	av.aluno_id,
+	av.professor_id,
+	al.nome,
+	av.nota,
+	av.comentario,
+	av.resposta,
+	av.respondida_em,
+	av.created_at,
+	av.updated_at
+FROM
+	avaliacoes av
+INNER JOIN
+	alunos al ON al.id = av.aluno_id
+WHERE
+	av.professor_id = ?
+ORDER BY
+	av.created_at DESC,
+	av.id DESC
+LIMIT ? OFFSET ?
+`
+
+// FindAvaliacoesByProfessorID returns a page of the reviews of the
+// professor, newest first, along with how many there are in total.
+func FindAvaliacoesByProfessorID(professorID int64, limit, offset int32) ([]*model.Avaliacao, int64, error) {
+	var total int64
+	err := db.QueryRow(countAvaliacoesByProfessorIDQuery, professorID).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.Query(findAvaliacoesByProfessorIDQuery, professorID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	avaliacoes := make([]*model.Avaliacao, 0)
+	for rows.Next() {
+		avaliacao, err := scanAvaliacao(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		avaliacoes = append(avaliacoes, avaliacao)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return avaliacoes, total, nil
+}
+
+func scanAvaliacao(row scanner) (*model.Avaliacao, error) {
+	avaliacao := &model.Avaliacao{}
+	err := row.Scan(
+		&avaliacao.ID,
+		&avaliacao.AlunoID,
+		&avaliacao.ProfessorID,
+		&avaliacao.Autor,
+		&avaliacao.Nota,
+		&avaliacao.Comentario,
+		&avaliacao.Resposta,
+		&avaliacao.RespondidaEm,
+		&avaliacao.CreatedAt,
+		&avaliacao.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return avaliacao, nil
+}
+
+const answerAvaliacaoQuery = `
+UPDATE
+	avaliacoes
+SET
+	resposta = ?,
+	respondida_em = CURRENT_TIMESTAMP
+WHERE
+	id = ?
+AND
+	resposta IS NULL
+`
+
+// AnswerAvaliacao saves the reply of the professor. It reports false when the
+// review was already answered.
+func AnswerAvaliacao(id int64, resposta string) (bool, error) {
+	result, err := db.Exec(answerAvaliacaoQuery, resposta, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
@@ -2,10 +2,15 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
+// duplicateEntryErrorNumber is the MySQL error raised when a row violates a
+// unique key.
+const duplicateEntryErrorNumber = 1062
+
 var (
 	db  *sql.DB
 	err error
@@ -21,3 +26,10 @@ func InitMySQL(dsn string) {
 func Close() {
 	db.Close()
 }
+
+// IsDuplicateEntry reports whether err was raised by a row violating a
+// unique key.
+func IsDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == duplicateEntryErrorNumber
+}
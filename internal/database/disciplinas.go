@@ -0,0 +1,221 @@
+package database
+
+import (
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+const findAllDisciplinasQuery = `
+SELECT
+	id,
+	parent_id,
+	nome,
+	created_at,
+	updated_at
+FROM
+	disciplinas
+ORDER BY
+	nome ASC
+`
+
+func FindAllDisciplinas() ([]*model.Disciplina, error) {
+	rows, err := db.Query(findAllDisciplinasQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	disciplinas := make([]*model.Disciplina, 0)
+	for rows.Next() {
+		disciplina, err := scanDisciplina(rows)
+		if err != nil {
+			return nil, err
+		}
+		disciplinas = append(disciplinas, disciplina)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return disciplinas, nil
+}
+
+const findDisciplinaByIDQuery = `
+SELECT
+	id,
+	parent_id,
+	nome,
+	created_at,
+	updated_at
+FROM
+	disciplinas
+WHERE
+	id = ?
+LIMIT 1
+`
+
+func FindDisciplinaByID(id int64) (*model.Disciplina, error) {
+	return scanDisciplina(db.QueryRow(findDisciplinaByIDQuery, id))
+}
+
+func scanDisciplina(row scanner) (*model.Disciplina, error) {
+	disciplina := &model.Disciplina{}
+	err := row.Scan(
+		&disciplina.ID,
+		&disciplina.ParentID,
+		&disciplina.Nome,
+		&disciplina.CreatedAt,
+		&disciplina.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return disciplina, nil
+}
+
+const createDisciplinaQuery = `
+INSERT INTO
+	disciplinas (parent_id, nome)
+VALUES
+	(?, ?)
+`
+
+func CreateDisciplina(disciplina *model.Disciplina) (*model.Disciplina, error) {
+	result, err := db.Exec(
+		createDisciplinaQuery,
+		disciplina.ParentID,
+		disciplina.Nome,
+	)
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return FindDisciplinaByID(id)
+}
+
+const deleteDisciplinaByIDQuery = `
+DELETE FROM
+	disciplinas
+WHERE
+	id = ?
+`
+
+func DeleteDisciplinaByID(id int64) error {
+	_, err := db.Exec(deleteDisciplinaByIDQuery, id)
+	return err
+}
+
+const existsProfessorDisciplinaByDisciplinaIDQuery = `
+SELECT
+	disciplina_id
+FROM
+	professor_disciplinas
+WHERE
+	disciplina_id = ?
+LIMIT 1
+`
+
+// ExistsProfessorDisciplinaByDisciplinaID reports whether any professor
+// teaches the subject.
+func ExistsProfessorDisciplinaByDisciplinaID(disciplinaID int64) bool {
+	row := db.QueryRow(existsProfessorDisciplinaByDisciplinaIDQuery, disciplinaID)
+	var id int64
+	err := row.Scan(&id)
+	return err == nil && id == disciplinaID
+}
+
+const findProfessorDisciplinaIDsQuery = `
+SELECT
+	professor_id,
+	disciplina_id
+FROM
+	professor_disciplinas
+`
+
+// FindAllProfessorDisciplinaIDs returns the ids of the subjects taught by
+// every professor.
+func FindAllProfessorDisciplinaIDs() (map[int64][]int64, error) {
+	return findProfessorDisciplinaIDs(findProfessorDisciplinaIDsQuery)
+}
+
+// FindDisciplinaIDsByProfessorIDs returns the ids of the subjects taught by
+// each of the professores.
+func FindDisciplinaIDsByProfessorIDs(professorIDs []int64) (map[int64][]int64, error) {
+	if len(professorIDs) == 0 {
+		return map[int64][]int64{}, nil
+	}
+	query := findProfessorDisciplinaIDsQuery + "WHERE\n\tprofessor_id IN (" + placeholders(len(professorIDs)) + ")\n"
+	return findProfessorDisciplinaIDs(query, int64sToArgs(professorIDs)...)
+}
+
+func findProfessorDisciplinaIDs(query string, args ...interface{}) (map[int64][]int64, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	disciplinaIDs := map[int64][]int64{}
+	for rows.Next() {
+		var professorID, disciplinaID int64
+		if err := rows.Scan(&professorID, &disciplinaID); err != nil {
+			return nil, err
+		}
+		disciplinaIDs[professorID] = append(disciplinaIDs[professorID], disciplinaID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return disciplinaIDs, nil
+}
+
+const deleteProfessorDisciplinasQuery = `
+DELETE FROM
+	professor_disciplinas
+WHERE
+	professor_id = ?
+`
+
+const createProfessorDisciplinaQuery = `
+INSERT INTO
+	professor_disciplinas (professor_id, disciplina_id)
+VALUES
+	(?, ?)
+`
+
+const updateProfessorDisciplinasBuscaQuery = `
+UPDATE
+	professores
+SET
+	disciplinas_busca = ?
+WHERE
+	id = ?
+`
+
+// SetProfessorDisciplinas replaces the subjects taught by the professor with
+// disciplinaIDs. busca is the text of the subjects indexed by the FULLTEXT
+// search.
+func SetProfessorDisciplinas(professorID int64, disciplinaIDs []int64, busca string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(deleteProfessorDisciplinasQuery, professorID); err != nil {
+		return err
+	}
+	for _, disciplinaID := range disciplinaIDs {
+		if _, err := tx.Exec(createProfessorDisciplinaQuery, professorID, disciplinaID); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(updateProfessorDisciplinasBuscaQuery, busca, professorID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
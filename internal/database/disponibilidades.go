@@ -0,0 +1,340 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+const findDisponibilidadesByProfessorIDQuery = `
+SELECT
+	id,
+	professor_id,
+	dia_semana,
+	TIME_FORMAT(hora_inicio, '%H:%i'),
+	TIME_FORMAT(hora_fim, '%H:%i'),
+	created_at,
+	updated_at
+FROM
+	disponibilidades
+WHERE
+	professor_id = ?
+ORDER BY
+	dia_semana ASC,
+	hora_inicio ASC
+`
+
+func FindDisponibilidadesByProfessorID(professorID int64) ([]*model.Disponibilidade, error) {
+	rows, err := db.Query(findDisponibilidadesByProfessorIDQuery, professorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDisponibilidades(rows)
+}
+
+const findDisponibilidadesByProfessorIDAndDiaSemanaQuery = `
+SELECT
+	id,
+	professor_id,
+	dia_semana,
+	TIME_FORMAT(hora_inicio, '%H:%i'),
+	TIME_FORMAT(hora_fim, '%H:%i'),
+	created_at,
+	updated_at
+FROM
+	disponibilidades
+WHERE
+	professor_id = ?
+AND
+	dia_semana = ?
+ORDER BY
+	hora_inicio ASC
+`
+
+func FindDisponibilidadesByProfessorIDAndDiaSemana(professorID int64, diaSemana int32) ([]*model.Disponibilidade, error) {
+	rows, err := db.Query(findDisponibilidadesByProfessorIDAndDiaSemanaQuery, professorID, diaSemana)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDisponibilidades(rows)
+}
+
+func scanDisponibilidades(rows *sql.Rows) ([]*model.Disponibilidade, error) {
+	disponibilidades := make([]*model.Disponibilidade, 0)
+	for rows.Next() {
+		disponibilidade := &model.Disponibilidade{}
+		err := rows.Scan(
+			&disponibilidade.ID,
+			&disponibilidade.ProfessorID,
+			&disponibilidade.DiaSemana,
+			&disponibilidade.HoraInicio,
+			&disponibilidade.HoraFim,
+			&disponibilidade.CreatedAt,
+			&disponibilidade.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		disponibilidades = append(disponibilidades, disponibilidade)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return disponibilidades, nil
+}
+
+const findDisponibilidadeByIDQuery = `
+SELECT
+	id,
+	professor_id,
+	dia_semana,
+	TIME_FORMAT(hora_inicio, '%H:%i'),
+	TIME_FORMAT(hora_fim, '%H:%i'),
+	created_at,
+	updated_at
+FROM
+	disponibilidades
+WHERE
+	id = ?
+LIMIT 1
+`
+
+func FindDisponibilidadeByID(id int64) (*model.Disponibilidade, error) {
+	row := db.QueryRow(findDisponibilidadeByIDQuery, id)
+	disponibilidade := &model.Disponibilidade{}
+	err := row.Scan(
+		&disponibilidade.ID,
+		&disponibilidade.ProfessorID,
+		&disponibilidade.DiaSemana,
+		&disponibilidade.HoraInicio,
+		&disponibilidade.HoraFim,
+		&disponibilidade.CreatedAt,
+		&disponibilidade.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return disponibilidade, nil
+}
+
+const existsDisponibilidadeOverlapQuery = `
+SELECT
+	id
+FROM
+	disponibilidades
+WHERE
+	professor_id = ?
+AND
+	dia_semana = ?
+AND
+	hora_inicio < ?
+AND
+	hora_fim > ?
+LIMIT 1
+`
+
+func ExistsDisponibilidadeOverlap(professorID int64, diaSemana int32, horaInicio, horaFim string) bool {
+	row := db.QueryRow(existsDisponibilidadeOverlapQuery, professorID, diaSemana, horaFim, horaInicio)
+	var disponibilidadeID int64
+	err := row.Scan(&disponibilidadeID)
+	return err == nil && disponibilidadeID > 0
+}
+
+const createDisponibilidadeQuery = `
+INSERT INTO
+	disponibilidades (professor_id, dia_semana, hora_inicio, hora_fim)
+VALUES
+	(?, ?, ?, ?)
+`
+
+func CreateDisponibilidade(disponibilidade *model.Disponibilidade) (*model.Disponibilidade, error) {
+	result, err := db.Exec(
+		createDisponibilidadeQuery,
+		disponibilidade.ProfessorID,
+		disponibilidade.DiaSemana,
+		disponibilidade.HoraInicio,
+		disponibilidade.HoraFim,
+	)
+	if err != nil {
+		return nil, err
+	}
+	disponibilidadeID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return FindDisponibilidadeByID(disponibilidadeID)
+}
+
+const deleteDisponibilidadeByIDQuery = `
+DELETE FROM
+	disponibilidades
+WHERE
+	id = ?
+`
+
+func DeleteDisponibilidadeByID(id int64) error {
+	_, err := db.Exec(deleteDisponibilidadeByIDQuery, id)
+	return err
+}
+
+const findDisponibilidadeExcecoesByProfessorIDQuery = `
+SELECT
+	id,
+	professor_id,
+	inicio,
+	fim,
+	disponivel,
+	motivo,
+	created_at,
+	updated_at
+FROM
+	disponibilidade_excecoes
+WHERE
+	professor_id = ?
+ORDER BY
+	inicio ASC
+`
+
+func FindDisponibilidadeExcecoesByProfessorID(professorID int64) ([]*model.DisponibilidadeExcecao, error) {
+	rows, err := db.Query(findDisponibilidadeExcecoesByProfessorIDQuery, professorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDisponibilidadeExcecoes(rows)
+}
+
+const findDisponibilidadeExcecoesByProfessorIDAndPeriodoQuery = `
+SELECT
+	id,
+	professor_id,
+	inicio,
+	fim,
+	disponivel,
+	motivo,
+	created_at,
+	updated_at
+FROM
+	disponibilidade_excecoes
+WHERE
+	professor_id = ?
+AND
+	inicio < ?
+AND
+	fim > ?
+ORDER BY
+	inicio ASC
+`
+
+// FindDisponibilidadeExcecoesByProfessorIDAndPeriodo returns the exceptions
+// of a professor that overlap the period between inicio and fim.
+func FindDisponibilidadeExcecoesByProfessorIDAndPeriodo(professorID int64, inicio, fim time.Time) ([]*model.DisponibilidadeExcecao, error) {
+	rows, err := db.Query(findDisponibilidadeExcecoesByProfessorIDAndPeriodoQuery, professorID, fim, inicio)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDisponibilidadeExcecoes(rows)
+}
+
+func scanDisponibilidadeExcecoes(rows *sql.Rows) ([]*model.DisponibilidadeExcecao, error) {
+	excecoes := make([]*model.DisponibilidadeExcecao, 0)
+	for rows.Next() {
+		excecao := &model.DisponibilidadeExcecao{}
+		err := rows.Scan(
+			&excecao.ID,
+			&excecao.ProfessorID,
+			&excecao.Inicio,
+			&excecao.Fim,
+			&excecao.Disponivel,
+			&excecao.Motivo,
+			&excecao.CreatedAt,
+			&excecao.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		excecoes = append(excecoes, excecao)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return excecoes, nil
+}
+
+const findDisponibilidadeExcecaoByIDQuery = `
+SELECT
+	id,
+	professor_id,
+	inicio,
+	fim,
+	disponivel,
+	motivo,
+	created_at,
+	updated_at
+FROM
+	disponibilidade_excecoes
+WHERE
+	id = ?
+LIMIT 1
+`
+
+func FindDisponibilidadeExcecaoByID(id int64) (*model.DisponibilidadeExcecao, error) {
+	row := db.QueryRow(findDisponibilidadeExcecaoByIDQuery, id)
+	excecao := &model.DisponibilidadeExcecao{}
+	err := row.Scan(
+		&excecao.ID,
+		&excecao.ProfessorID,
+		&excecao.Inicio,
+		&excecao.Fim,
+		&excecao.Disponivel,
+		&excecao.Motivo,
+		&excecao.CreatedAt,
+		&excecao.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return excecao, nil
+}
+
+const createDisponibilidadeExcecaoQuery = `
+INSERT INTO
+	disponibilidade_excecoes (professor_id, inicio, fim, disponivel, motivo)
+VALUES
+	(?, ?, ?, ?, ?)
+`
+
+func CreateDisponibilidadeExcecao(excecao *model.DisponibilidadeExcecao) (*model.DisponibilidadeExcecao, error) {
+	result, err := db.Exec(
+		createDisponibilidadeExcecaoQuery,
+		excecao.ProfessorID,
+		excecao.Inicio,
+		excecao.Fim,
+		excecao.Disponivel,
+		excecao.Motivo,
+	)
+	if err != nil {
+		return nil, err
+	}
+	excecaoID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return FindDisponibilidadeExcecaoByID(excecaoID)
+}
+
+const deleteDisponibilidadeExcecaoByIDQuery = `
+DELETE FROM
+	disponibilidade_excecoes
+WHERE
+	id = ?
+`
+
+func DeleteDisponibilidadeExcecaoByID(id int64) error {
+	_, err := db.Exec(deleteDisponibilidadeExcecaoByIDQuery, id)
+	return err
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+const createEmailVerificationQuery = `
+INSERT INTO
+	email_verifications (professor_id, token_hash, expires_at)
+VALUES
+	(?, ?, ?)
+`
+
+func CreateEmailVerification(verification *model.EmailVerification) error {
+	_, err := db.Exec(
+		createEmailVerificationQuery,
+		verification.ProfessorID,
+		verification.TokenHash,
+		verification.ExpiresAt,
+	)
+	return err
+}
+
+const findValidEmailVerificationByTokenHashQuery = `
+SELECT
+	id,
+	professor_id,
+	token_hash,
+	expires_at,
+	used_at,
+	created_at
+FROM
+	email_verifications
+WHERE
+	token_hash = ?
+AND
+	used_at IS NULL
+AND
+	expires_at > ?
+LIMIT 1
+`
+
+// FindValidEmailVerificationByTokenHash returns the verification identified
+// by tokenHash as long as it was not used yet and has not expired.
+func FindValidEmailVerificationByTokenHash(tokenHash string) (*model.EmailVerification, error) {
+	row := db.QueryRow(findValidEmailVerificationByTokenHashQuery, tokenHash, time.Now())
+	verification := &model.EmailVerification{}
+	err := row.Scan(
+		&verification.ID,
+		&verification.ProfessorID,
+		&verification.TokenHash,
+		&verification.ExpiresAt,
+		&verification.UsedAt,
+		&verification.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return verification, nil
+}
+
+const existsRecentEmailVerificationQuery = `
+SELECT
+	id
+FROM
+	email_verifications
+WHERE
+	professor_id = ?
+AND
+	created_at > DATE_SUB(CURRENT_TIMESTAMP, INTERVAL ? SECOND)
+LIMIT 1
+`
+
+// ExistsRecentEmailVerification reports whether a verification was sent to
+// the professor in the last seconds.
+func ExistsRecentEmailVerification(professorID int64, seconds int64) bool {
+	row := db.QueryRow(existsRecentEmailVerificationQuery, professorID, seconds)
+	var verificationID int64
+	err := row.Scan(&verificationID)
+	return err == nil && verificationID > 0
+}
+
+const useEmailVerificationByIDQuery = `
+UPDATE
+	email_verifications
+SET
+	used_at = ?
+WHERE
+	id = ?
+AND
+	used_at IS NULL
+`
+
+const verifyProfessorEmailQuery = `
+UPDATE
+	professores
+SET
+	email_verified_at = CURRENT_TIMESTAMP
+WHERE
+	id = ?
+AND
+	email_verified_at IS NULL
+`
+
+const useEmailVerificationsByProfessorIDQuery = `
+UPDATE
+	email_verifications
+SET
+	used_at = ?
+WHERE
+	professor_id = ?
+AND
+	used_at IS NULL
+`
+
+// VerifyProfessorEmail consumes verification and marks the email of its
+// professor as verified, invalidating any other pending verification. It
+// returns sql.ErrNoRows when verification was used concurrently.
+func VerifyProfessorEmail(verification *model.EmailVerification) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(useEmailVerificationByIDQuery, now, verification.ID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	_, err = tx.Exec(verifyProfessorEmailQuery, verification.ProfessorID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(useEmailVerificationsByProfessorIDQuery, now, verification.ProfessorID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
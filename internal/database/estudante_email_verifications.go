@@ -0,0 +1,174 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+const createEstudanteEmailVerificationQuery = `
+INSERT INTO
+	estudante_email_verifications (estudante_id, token_hash, expires_at)
+VALUES
+	(?, ?, ?)
+`
+
+func CreateEstudanteEmailVerification(verification *model.EstudanteEmailVerification) error {
+	_, err := db.Exec(
+		createEstudanteEmailVerificationQuery,
+		verification.EstudanteID,
+		verification.TokenHash,
+		verification.ExpiresAt,
+	)
+	return err
+}
+
+const findValidEstudanteEmailVerificationByTokenHashQuery = `
+SELECT
+	id,
+	estudante_id,
+	token_hash,
+	expires_at,
+	used_at,
+	created_at
+FROM
+	estudante_email_verifications
+WHERE
+	token_hash = ?
+AND
+	used_at IS NULL
+AND
+	expires_at > ?
+LIMIT 1
+`
+
+// FindValidEstudanteEmailVerificationByTokenHash returns the verification
+// identified by tokenHash as long as it was not used yet and has not expired.
+func FindValidEstudanteEmailVerificationByTokenHash(tokenHash string) (*model.EstudanteEmailVerification, error) {
+	row := db.QueryRow(findValidEstudanteEmailVerificationByTokenHashQuery, tokenHash, time.Now())
+	verification := &model.EstudanteEmailVerification{}
+	err := row.Scan(
+		&verification.ID,
+		&verification.EstudanteID,
+		&verification.TokenHash,
+		&verification.ExpiresAt,
+		&verification.UsedAt,
+		&verification.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return verification, nil
+}
+
+const existsRecentEstudanteEmailVerificationQuery = `
+SELECT
+	id
+FROM
+	estudante_email_verifications
+WHERE
+	estudante_id = ?
+AND
+	created_at > DATE_SUB(CURRENT_TIMESTAMP, INTERVAL ? SECOND)
+LIMIT 1
+`
+
+// ExistsRecentEstudanteEmailVerification reports whether a verification was
+// sent to the student in the last seconds.
+func ExistsRecentEstudanteEmailVerification(estudanteID int64, seconds int64) bool {
+	row := db.QueryRow(existsRecentEstudanteEmailVerificationQuery, estudanteID, seconds)
+	var verificationID int64
+	err := row.Scan(&verificationID)
+	return err == nil && verificationID > 0
+}
+
+const useEstudanteEmailVerificationByIDQuery = `
+UPDATE
+	estudante_email_verifications
+SET
+	used_at = ?
+WHERE
+	id = ?
+AND
+	used_at IS NULL
+`
+
+const verifyEstudanteEmailQuery = `
+UPDATE
+	estudantes
+SET
+	email_verified_at = CURRENT_TIMESTAMP
+WHERE
+	id = ?
+AND
+	email_verified_at IS NULL
+`
+
+const useEstudanteEmailVerificationsByEstudanteIDQuery = `
+UPDATE
+	estudante_email_verifications
+SET
+	used_at = ?
+WHERE
+	estudante_id = ?
+AND
+	used_at IS NULL
+`
+
+const linkAlunosToEstudanteQuery = `
+UPDATE
+	alunos AS a
+INNER JOIN
+	estudantes AS e
+ON
+	a.email = e.email
+SET
+	a.estudante_id = e.id
+WHERE
+	e.id = ?
+AND
+	a.estudante_id IS NULL
+`
+
+// VerifyEstudanteEmail consumes verification, marks the email of its student
+// as verified and links every booking made with that email to them. Any other
+// pending verification is invalidated. It returns sql.ErrNoRows when
+// verification was used concurrently.
+func VerifyEstudanteEmail(verification *model.EstudanteEmailVerification) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(useEstudanteEmailVerificationByIDQuery, now, verification.ID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	_, err = tx.Exec(verifyEstudanteEmailQuery, verification.EstudanteID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(useEstudanteEmailVerificationsByEstudanteIDQuery, now, verification.EstudanteID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(linkAlunosToEstudanteQuery, verification.EstudanteID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
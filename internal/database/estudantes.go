@@ -0,0 +1,188 @@
+package database
+
+import (
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+const findEstudanteByIDQuery = `
+SELECT
+	id,
+	nome,
+	email,
+	email_verified_at,
+	created_at,
+	updated_at
+FROM
+	estudantes
+WHERE
+	id = ?
+LIMIT 1
+`
+
+func FindEstudanteByID(id int64) (*model.Estudante, error) {
+	row := db.QueryRow(findEstudanteByIDQuery, id)
+	estudante := &model.Estudante{}
+	err := row.Scan(
+		&estudante.ID,
+		&estudante.Nome,
+		&estudante.Email,
+		&estudante.EmailVerifiedAt,
+		&estudante.CreatedAt,
+		&estudante.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return estudante, nil
+}
+
+const findEstudanteByEmailQuery = `
+SELECT
+	id,
+	nome,
+	email,
+	password,
+	email_verified_at,
+	created_at,
+	updated_at
+FROM
+	estudantes
+WHERE
+	email = ?
+LIMIT 1
+`
+
+func FindEstudanteByEmail(email string) (*model.Estudante, error) {
+	row := db.QueryRow(findEstudanteByEmailQuery, email)
+	estudante := &model.Estudante{}
+	err := row.Scan(
+		&estudante.ID,
+		&estudante.Nome,
+		&estudante.Email,
+		&estudante.Password,
+		&estudante.EmailVerifiedAt,
+		&estudante.CreatedAt,
+		&estudante.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return estudante, nil
+}
+
+const existsEstudanteByEmailQuery = `
+SELECT
+	email
+FROM
+	estudantes
+WHERE
+	email = ?
+LIMIT 1
+`
+
+func ExistsEstudanteByEmail(email string) bool {
+	row := db.QueryRow(existsEstudanteByEmailQuery, email)
+	var estudanteEmail string
+	err := row.Scan(&estudanteEmail)
+	return err == nil && estudanteEmail == email
+}
+
+const createEstudanteQuery = `
+INSERT INTO
+	estudantes (nome, email, password)
+VALUES
+	(?, ?, ?)
+`
+
+func CreateEstudante(estudante *model.Estudante) (*model.Estudante, error) {
+	result, err := db.Exec(
+		createEstudanteQuery,
+		estudante.Nome,
+		estudante.Email,
+		estudante.Password,
+	)
+	if err != nil {
+		return nil, err
+	}
+	estudanteID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return FindEstudanteByID(estudanteID)
+}
+
+const findAlunosByEstudanteIDQuery = `
+SELECT
+	a.id,
+	a.nome,
+	a.email,
+	a.data_aula,
+	a.duracao,
+	a.status,
+	a.professor_id,
+	a.created_at,
+	a.updated_at,
+	p.id,
+	p.nome,
+	p.email,
+	p.idade,
+	p.descricao,
+	p.valor_hora,
+	p.duracao_aula,
+	p.foto_perfil,
+	p.created_at,
+	p.updated_at
+FROM
+	alunos as a
+INNER JOIN
+	professores as p
+ON
+	a.professor_id = p.id
+WHERE
+	a.estudante_id = ?
+AND
+	(? = '' OR a.status = ?)
+ORDER BY
+	a.data_aula DESC
+`
+
+func FindAlunosByEstudanteID(estudanteID int64, status string) ([]*model.Aluno, error) {
+	rows, err := db.Query(findAlunosByEstudanteIDQuery, estudanteID, status, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	alunos := make([]*model.Aluno, 0)
+	for rows.Next() {
+		aluno := &model.Aluno{Professor: &model.Professor{}}
+		err := rows.Scan(
+			&aluno.ID,
+			&aluno.Nome,
+			&aluno.Email,
+			&aluno.DataAula,
+			&aluno.Duracao,
+			&aluno.Status,
+			&aluno.ProfessorID,
+			&aluno.CreatedAt,
+			&aluno.UpdatedAt,
+			&aluno.Professor.ID,
+			&aluno.Professor.Nome,
+			&aluno.Professor.Email,
+			&aluno.Professor.Idade,
+			&aluno.Professor.Descricao,
+			&aluno.Professor.ValorHora,
+			&aluno.Professor.DuracaoAula,
+			&aluno.Professor.FotoPerfil,
+			&aluno.Professor.CreatedAt,
+			&aluno.Professor.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		alunos = append(alunos, aluno)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return alunos, nil
+}
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+const findLoginAttemptQuery = `
+SELECT
+	scope,
+	role,
+	identifier,
+	failures,
+	locked_until,
+	last_failure_at
+FROM
+	login_attempts
+WHERE
+	scope = ?
+AND
+	role = ?
+AND
+	identifier = ?
+LIMIT 1
+`
+
+func FindLoginAttempt(key model.LoginAttemptKey) (*model.LoginAttempt, error) {
+	row := db.QueryRow(findLoginAttemptQuery, key.Scope, key.Role, key.Identifier)
+	attempt := &model.LoginAttempt{}
+	err := row.Scan(
+		&attempt.Scope,
+		&attempt.Role,
+		&attempt.Identifier,
+		&attempt.Failures,
+		&attempt.LockedUntil,
+		&attempt.LastFailureAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// The count and the lock restart when the previous failure is older than the
+// window. MySQL applies the assignments in order, so last_failure_at must be
+// the last one.
+const addLoginFailureQuery = `
+INSERT INTO
+	login_attempts (scope, role, identifier, failures, last_failure_at)
+VALUES
+	(?, ?, ?, 1, ?)
+ON DUPLICATE KEY UPDATE
+	failures = IF(last_failure_at < ?, 1, failures + 1),
+	locked_until = IF(last_failure_at < ?, NULL, locked_until),
+	last_failure_at = VALUES(last_failure_at)
+`
+
+// AddLoginFailure records a failed login for key at now, starting the count
+// over when the previous failure happened before windowStart.
+func AddLoginFailure(key model.LoginAttemptKey, now, windowStart time.Time) (*model.LoginAttempt, error) {
+	_, err := db.Exec(
+		addLoginFailureQuery,
+		key.Scope,
+		key.Role,
+		key.Identifier,
+		now,
+		windowStart,
+		windowStart,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return FindLoginAttempt(key)
+}
+
+const lockLoginAttemptQuery = `
+UPDATE
+	login_attempts
+SET
+	locked_until = ?
+WHERE
+	scope = ?
+AND
+	role = ?
+AND
+	identifier = ?
+`
+
+func LockLoginAttempt(key model.LoginAttemptKey, until time.Time) error {
+	_, err := db.Exec(lockLoginAttemptQuery, until, key.Scope, key.Role, key.Identifier)
+	return err
+}
+
+const deleteLoginAttemptQuery = `
+DELETE FROM
+	login_attempts
+WHERE
+	scope = ?
+AND
+	role = ?
+AND
+	identifier = ?
+`
+
+func DeleteLoginAttempt(key model.LoginAttemptKey) error {
+	_, err := db.Exec(deleteLoginAttemptQuery, key.Scope, key.Role, key.Identifier)
+	return err
+}
+
+const findLockedLoginAttemptsQuery = `
+SELECT
+	scope,
+	role,
+	identifier,
+	failures,
+	locked_until,
+	last_failure_at
+FROM
+	login_attempts
+WHERE
+	locked_until > ?
+ORDER BY
+	locked_until DESC
+`
+
+func FindLockedLoginAttempts(now time.Time) ([]*model.LoginAttempt, error) {
+	rows, err := db.Query(findLockedLoginAttemptsQuery, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	attempts := make([]*model.LoginAttempt, 0)
+	for rows.Next() {
+		attempt := &model.LoginAttempt{}
+		err := rows.Scan(
+			&attempt.Scope,
+			&attempt.Role,
+			&attempt.Identifier,
+			&attempt.Failures,
+			&attempt.LockedUntil,
+			&attempt.LastFailureAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, attempt)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+const deleteStaleLoginAttemptsQuery = `
+DELETE FROM
+	login_attempts
+WHERE
+	last_failure_at < ?
+AND
+	(locked_until IS NULL OR locked_until < ?)
+`
+
+// DeleteStaleLoginAttempts removes the attempts that are neither locked nor
+// recent enough to count anymore.
+func DeleteStaleLoginAttempts(before time.Time) (int64, error) {
+	result, err := db.Exec(deleteStaleLoginAttemptsQuery, before, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"time"
+)
+
+const updateProfessorTotpSecretQuery = `
+UPDATE
+	professores
+SET
+	totp_secret = ?,
+	totp_last_step = 0
+WHERE
+	id = ?
+AND
+	totp_enabled_at IS NULL
+`
+
+// UpdateProfessorTotpSecret stores a secret that is pending confirmation. It
+// does nothing when the professor already has TOTP enabled.
+func UpdateProfessorTotpSecret(professorID int64, secret string) error {
+	_, err := db.Exec(updateProfessorTotpSecretQuery, secret, professorID)
+	return err
+}
+
+const enableProfessorTotpQuery = `
+UPDATE
+	professores
+SET
+	totp_enabled_at = CURRENT_TIMESTAMP
+WHERE
+	id = ?
+`
+
+const deleteRecoveryCodesByProfessorIDQuery = `
+DELETE FROM
+	recovery_codes
+WHERE
+	professor_id = ?
+`
+
+const createRecoveryCodeQuery = `
+INSERT INTO
+	recovery_codes (professor_id, code_hash)
+VALUES
+	(?, ?)
+`
+
+// EnableProfessorTotp turns on TOTP for the professor and replaces their
+// recovery codes by the ones in codeHashes.
+func EnableProfessorTotp(professorID int64, codeHashes []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(enableProfessorTotpQuery, professorID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(deleteRecoveryCodesByProfessorIDQuery, professorID)
+	if err != nil {
+		return err
+	}
+
+	for _, codeHash := range codeHashes {
+		_, err = tx.Exec(createRecoveryCodeQuery, professorID, codeHash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+const disableProfessorTotpQuery = `
+UPDATE
+	professores
+SET
+	totp_secret = NULL,
+	totp_enabled_at = NULL,
+	totp_last_step = 0
+WHERE
+	id = ?
+`
+
+// DisableProfessorTotp turns off TOTP for the professor and drops their
+// recovery codes.
+func DisableProfessorTotp(professorID int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(disableProfessorTotpQuery, professorID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(deleteRecoveryCodesByProfessorIDQuery, professorID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const useProfessorTotpStepQuery = `
+UPDATE
+	professores
+SET
+	totp_last_step = ?
+WHERE
+	id = ?
+AND
+	totp_last_step < ?
+`
+
+// UseProfessorTotpStep records step as the last time step a code was accepted
+// for. It reports false when a code for step, or a later one, was already
+// used, so the same code cannot be replayed.
+func UseProfessorTotpStep(professorID int64, step int64) (bool, error) {
+	result, err := db.Exec(useProfessorTotpStepQuery, step, professorID, step)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+const useRecoveryCodeQuery = `
+UPDATE
+	recovery_codes
+SET
+	used_at = ?
+WHERE
+	professor_id = ?
+AND
+	code_hash = ?
+AND
+	used_at IS NULL
+`
+
+// UseRecoveryCode consumes the recovery code identified by codeHash. It
+// reports false when no unused code matches.
+func UseRecoveryCode(professorID int64, codeHash string) (bool, error) {
+	result, err := db.Exec(useRecoveryCodeQuery, time.Now(), professorID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
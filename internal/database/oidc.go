@@ -0,0 +1,258 @@
+package database
+
+import (
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+const createOidcStateQuery = `
+INSERT INTO
+	oidc_states (provider, state_hash, nonce, code_verifier, expires_at)
+VALUES
+	(?, ?, ?, ?, ?)
+`
+
+func CreateOidcState(state *model.OidcState) error {
+	_, err := db.Exec(
+		createOidcStateQuery,
+		state.Provider,
+		state.StateHash,
+		state.Nonce,
+		state.CodeVerifier,
+		state.ExpiresAt,
+	)
+	return err
+}
+
+const findValidOidcStateByStateHashQuery = `
+SELECT
+	id,
+	provider,
+	state_hash,
+	nonce,
+	code_verifier,
+	expires_at,
+	used_at,
+	created_at
+FROM
+	oidc_states
+WHERE
+	state_hash = ?
+AND
+	used_at IS NULL
+AND
+	expires_at > ?
+LIMIT 1
+FOR UPDATE
+`
+
+const useOidcStateByIDQuery = `
+UPDATE
+	oidc_states
+SET
+	used_at = ?
+WHERE
+	id = ?
+`
+
+// UseOidcState consumes the state identified by stateHash as long as it was
+// not used yet and has not expired, so every login can only be completed
+// once.
+func UseOidcState(stateHash string) (*model.OidcState, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	row := tx.QueryRow(findValidOidcStateByStateHashQuery, stateHash, now)
+	state := &model.OidcState{}
+	err = row.Scan(
+		&state.ID,
+		&state.Provider,
+		&state.StateHash,
+		&state.Nonce,
+		&state.CodeVerifier,
+		&state.ExpiresAt,
+		&state.UsedAt,
+		&state.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(useOidcStateByIDQuery, now, state.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, tx.Commit()
+}
+
+const deleteExpiredOidcStatesQuery = `
+DELETE FROM
+	oidc_states
+WHERE
+	expires_at <= ?
+`
+
+func DeleteExpiredOidcStates(now time.Time) (int64, error) {
+	result, err := db.Exec(deleteExpiredOidcStatesQuery, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const findProfessorIdentityQuery = `
+SELECT
+	id,
+	professor_id,
+	provider,
+	subject,
+	email,
+	created_at
+FROM
+	professor_identities
+WHERE
+	provider = ?
+AND
+	subject = ?
+LIMIT 1
+`
+
+func FindProfessorIdentity(provider, subject string) (*model.ProfessorIdentity, error) {
+	row := db.QueryRow(findProfessorIdentityQuery, provider, subject)
+	identity := &model.ProfessorIdentity{}
+	err := row.Scan(
+		&identity.ID,
+		&identity.ProfessorID,
+		&identity.Provider,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// FindProfessorByIdentity returns the professor linked to the account subject
+// of provider, along with their credentials.
+func FindProfessorByIdentity(provider, subject string) (*model.Professor, error) {
+	identity, err := FindProfessorIdentity(provider, subject)
+	if err != nil {
+		return nil, err
+	}
+	professor, err := FindProfessorByID(identity.ProfessorID)
+	if err != nil {
+		return nil, err
+	}
+	return FindProfessorByEmail(professor.Email)
+}
+
+const createProfessorIdentityQuery = `
+INSERT INTO
+	professor_identities (professor_id, provider, subject, email)
+VALUES
+	(?, ?, ?, ?)
+`
+
+// The provider verified the email, so whoever registered it before without
+// confirming it is locked out: the password is replaced and the tokens
+// issued so far are revoked.
+const claimUnverifiedProfessorQuery = `
+UPDATE
+	professores
+SET
+	email_verified_at = CURRENT_TIMESTAMP,
+	password = ?,
+	token_version = token_version + 1
+WHERE
+	id = ?
+AND
+	email_verified_at IS NULL
+`
+
+// LinkProfessorIdentity links identity to an existing professor. When the
+// professor never verified their email, it is marked as verified and their
+// password is replaced by passwordHash.
+func LinkProfessorIdentity(identity *model.ProfessorIdentity, passwordHash string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		createProfessorIdentityQuery,
+		identity.ProfessorID,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(claimUnverifiedProfessorQuery, passwordHash, identity.ProfessorID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const createVerifiedProfessorQuery = `
+INSERT INTO
+	professores (nome, email, idade, descricao, valor_hora, duracao_aula, password, email_verified_at)
+VALUES
+	(?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+`
+
+// CreateProfessorWithIdentity creates a professor whose email was verified by
+// the provider of identity and links them together.
+func CreateProfessorWithIdentity(professor *model.Professor, identity *model.ProfessorIdentity) (*model.Professor, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		createVerifiedProfessorQuery,
+		professor.Nome,
+		professor.Email,
+		professor.Idade,
+		professor.Descricao,
+		professor.ValorHora,
+		professor.DuracaoAula,
+		professor.Password,
+	)
+	if err != nil {
+		return nil, err
+	}
+	professorID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(
+		createProfessorIdentityQuery,
+		professorID,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return FindProfessorByID(professorID)
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+const createPasswordResetQuery = `
+INSERT INTO
+	password_resets (professor_id, token_hash, expires_at)
+VALUES
+	(?, ?, ?)
+`
+
+func CreatePasswordReset(reset *model.PasswordReset) error {
+	_, err := db.Exec(
+		createPasswordResetQuery,
+		reset.ProfessorID,
+		reset.TokenHash,
+		reset.ExpiresAt,
+	)
+	return err
+}
+
+const existsRecentPasswordResetQuery = `
+SELECT
+	id
+FROM
+	password_resets
+WHERE
+	professor_id = ?
+AND
+	created_at > DATE_SUB(CURRENT_TIMESTAMP, INTERVAL ? SECOND)
+LIMIT 1
+`
+
+// ExistsRecentPasswordReset reports whether a reset was sent to the
+// professor in the last seconds.
+func ExistsRecentPasswordReset(professorID int64, seconds int64) bool {
+	row := db.QueryRow(existsRecentPasswordResetQuery, professorID, seconds)
+	var resetID int64
+	err := row.Scan(&resetID)
+	return err == nil && resetID > 0
+}
+
+const findValidPasswordResetByTokenHashQuery = `
+SELECT
+	id,
+	professor_id,
+	token_hash,
+	expires_at,
+	used_at,
+	created_at
+FROM
+	password_resets
+WHERE
+	token_hash = ?
+AND
+	used_at IS NULL
+AND
+	expires_at > ?
+LIMIT 1
+`
+
+// FindValidPasswordResetByTokenHash returns the reset identified by tokenHash
+// as long as it was not used yet and has not expired.
+func FindValidPasswordResetByTokenHash(tokenHash string) (*model.PasswordReset, error) {
+	row := db.QueryRow(findValidPasswordResetByTokenHashQuery, tokenHash, time.Now())
+	reset := &model.PasswordReset{}
+	err := row.Scan(
+		&reset.ID,
+		&reset.ProfessorID,
+		&reset.TokenHash,
+		&reset.ExpiresAt,
+		&reset.UsedAt,
+		&reset.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return reset, nil
+}
+
+const usePasswordResetByIDQuery = `
+UPDATE
+	password_resets
+SET
+	used_at = ?
+WHERE
+	id = ?
+AND
+	used_at IS NULL
+`
+
+const resetProfessorPasswordQuery = `
+UPDATE
+	professores
+SET
+	password = ?,
+	token_version = token_version + 1
+WHERE
+	id = ?
+`
+
+const usePasswordResetsByProfessorIDQuery = `
+UPDATE
+	password_resets
+SET
+	used_at = ?
+WHERE
+	professor_id = ?
+AND
+	used_at IS NULL
+`
+
+// ResetProfessorPassword consumes reset, stores the new password hash and
+// revokes every token issued to the professor. Any other pending reset of the
+// professor is consumed as well. It returns sql.ErrNoRows when reset was used
+// concurrently.
+func ResetProfessorPassword(reset *model.PasswordReset, password string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(usePasswordResetByIDQuery, now, reset.ID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	_, err = tx.Exec(resetProfessorPasswordQuery, password, reset.ProfessorID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(usePasswordResetsByProfessorIDQuery, now, reset.ProfessorID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -1,6 +1,11 @@
 package database
 
 import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
 	"github.com/cleysonph/hyperprof/internal/model"
 )
 
@@ -12,21 +17,113 @@ SELECT
 	idade,
 	descricao,
 	valor_hora,
+	duracao_aula,
 	foto_perfil,
+	suspended_at,
+	email_verified_at,
+	avaliacao_media,
+	avaliacoes_count,
 	created_at,
 	updated_at
 FROM
 	professores
-WHERE
-	LOWER(descricao) LIKE CONCAT('%', LOWER(?), '%')
-ORDER BY
-	created_at ASC
 `
 
-func FindAllProfessores(q string) ([]*model.Professor, error) {
-	rows, err := db.Query(findAllProfessoresQuery, q)
+const countAllProfessoresQuery = `
+SELECT
+	COUNT(*)
+FROM
+	professores
+`
+
+// professorSortOrders maps the sorts accepted by FindAllProfessores to their
+// ORDER BY clause. The id keeps the order stable between pages.
+var professorSortOrders = map[string]string{
+	model.ProfessorSortOldest:    "created_at ASC, id ASC",
+	model.ProfessorSortNewest:    "created_at DESC, id DESC",
+	model.ProfessorSortPriceAsc:  "valor_hora ASC, id ASC",
+	model.ProfessorSortPriceDesc: "valor_hora DESC, id DESC",
+	model.ProfessorSortRating:    "avaliacao_media DESC, avaliacoes_count DESC, id ASC",
+}
+
+// professorFilterWhere builds the WHERE clause matching filter, only listing
+// the professores visible in the catalogue.
+func professorFilterWhere(filter *model.ProfessorFilter) (string, []interface{}) {
+	conditions := []string{
+		"suspended_at IS NULL",
+		"email_verified_at IS NOT NULL",
+	}
+	args := []interface{}{}
+
+	if filter.Q != "" {
+		conditions = append(conditions, "id IN ("+placeholders(len(filter.IDs))+")")
+		args = append(args, int64sToArgs(filter.IDs)...)
+	}
+	if filter.Subject > 0 {
+		conditions = append(conditions, "id IN (SELECT professor_id FROM professor_disciplinas WHERE disciplina_id IN ("+placeholders(len(filter.DisciplinaIDs))+"))")
+		args = append(args, int64sToArgs(filter.DisciplinaIDs)...)
+	}
+	if filter.Nome != "" {
+		conditions = append(conditions, "LOWER(nome) LIKE CONCAT('%', LOWER(?), '%')")
+		args = append(args, filter.Nome)
+	}
+	if filter.ValorHoraMin > 0 {
+		conditions = append(conditions, "valor_hora >= ?")
+		args = append(args, filter.ValorHoraMin)
+	}
+	if filter.ValorHoraMax > 0 {
+		conditions = append(conditions, "valor_hora <= ?")
+		args = append(args, filter.ValorHoraMax)
+	}
+	if filter.IdadeMin > 0 {
+		conditions = append(conditions, "idade >= ?")
+		args = append(args, filter.IdadeMin)
+	}
+	if filter.IdadeMax > 0 {
+		conditions = append(conditions, "idade <= ?")
+		args = append(args, filter.IdadeMax)
+	}
+
+	return "WHERE\n\t" + strings.Join(conditions, "\nAND\n\t") + "\n", args
+}
+
+// placeholders returns n comma separated query placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func int64sToArgs(values []int64) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return args
+}
+
+// FindAllProfessores returns the requested page of the professores matching
+// filter along with how many match it in total.
+func FindAllProfessores(filter *model.ProfessorFilter) ([]*model.Professor, int64, error) {
+	where, args := professorFilterWhere(filter)
+
+	var total int64
+	err := db.QueryRow(countAllProfessoresQuery+where, args...).Scan(&total)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	orderBy, ok := professorSortOrders[filter.Sort]
+	if filter.Sort == model.ProfessorSortRelevance {
+		// FIELD returns the position of id among the ids found by the
+		// searcher, which are ranked best match first.
+		orderBy = "FIELD(id, " + placeholders(len(filter.IDs)) + ") ASC"
+		args = append(args, int64sToArgs(filter.IDs)...)
+	} else if !ok {
+		orderBy = professorSortOrders[model.ProfessorSortOldest]
+	}
+	query := findAllProfessoresQuery + where + "ORDER BY\n\t" + orderBy + "\nLIMIT ? OFFSET ?\n"
+	rows, err := db.Query(query, append(args, filter.Limit, filter.Offset())...)
+	if err != nil {
+		return nil, 0, err
 	}
 	defer rows.Close()
 
@@ -40,17 +137,69 @@ func FindAllProfessores(q string) ([]*model.Professor, error) {
 			&professor.Idade,
 			&professor.Descricao,
 			&professor.ValorHora,
+			&professor.DuracaoAula,
 			&professor.FotoPerfil,
+			&professor.SuspendedAt,
+			&professor.EmailVerifiedAt,
+			&professor.AvaliacaoMedia,
+			&professor.AvaliacoesCount,
 			&professor.CreatedAt,
 			&professor.UpdatedAt,
 		)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 		professores = append(professores, professor)
 	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
 
-	return professores, nil
+	return professores, total, nil
+}
+
+const searchProfessoresQuery = `
+SELECT
+	id,
+	MATCH(nome, descricao, disciplinas_busca) AGAINST (? IN BOOLEAN MODE) AS score
+FROM
+	professores
+WHERE
+	MATCH(nome, descricao, disciplinas_busca) AGAINST (? IN BOOLEAN MODE)
+	AND suspended_at IS NULL
+	AND email_verified_at IS NOT NULL
+ORDER BY
+	score DESC,
+	id ASC
+LIMIT ?
+`
+
+// SearchProfessores returns the ids of up to limit professores matching the
+// boolean mode FULLTEXT query against, along with their relevance, best match
+// first.
+func SearchProfessores(against string, limit int) ([]int64, []float64, error) {
+	rows, err := db.Query(searchProfessoresQuery, against, against, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	scores := []float64{}
+	for rows.Next() {
+		var id int64
+		var score float64
+		if err := rows.Scan(&id, &score); err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+		scores = append(scores, score)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return ids, scores, nil
 }
 
 const findProfessorByIDQuery = `
@@ -61,7 +210,12 @@ SELECT
 	idade,
 	descricao,
 	valor_hora,
+	duracao_aula,
 	foto_perfil,
+	suspended_at,
+	email_verified_at,
+	avaliacao_media,
+	avaliacoes_count,
 	created_at,
 	updated_at
 FROM
@@ -81,7 +235,67 @@ func FindProfessorByID(id int64) (*model.Professor, error) {
 		&professor.Idade,
 		&professor.Descricao,
 		&professor.ValorHora,
+		&professor.DuracaoAula,
 		&professor.FotoPerfil,
+		&professor.SuspendedAt,
+		&professor.EmailVerifiedAt,
+		&professor.AvaliacaoMedia,
+		&professor.AvaliacoesCount,
+		&professor.CreatedAt,
+		&professor.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return professor, nil
+}
+
+const findActiveProfessorByIDQuery = `
+SELECT
+	id,
+	nome,
+	email,
+	idade,
+	descricao,
+	valor_hora,
+	duracao_aula,
+	foto_perfil,
+	suspended_at,
+	email_verified_at,
+	avaliacao_media,
+	avaliacoes_count,
+	created_at,
+	updated_at
+FROM
+	professores
+WHERE
+	id = ?
+AND
+	suspended_at IS NULL
+AND
+	email_verified_at IS NOT NULL
+LIMIT 1
+`
+
+// FindActiveProfessorByID is like FindProfessorByID but ignores professors
+// that should not be visible to the public, such as suspended ones or the ones
+// that did not verify their email yet.
+func FindActiveProfessorByID(id int64) (*model.Professor, error) {
+	row := db.QueryRow(findActiveProfessorByIDQuery, id)
+	professor := &model.Professor{}
+	err := row.Scan(
+		&professor.ID,
+		&professor.Nome,
+		&professor.Email,
+		&professor.Idade,
+		&professor.Descricao,
+		&professor.ValorHora,
+		&professor.DuracaoAula,
+		&professor.FotoPerfil,
+		&professor.SuspendedAt,
+		&professor.EmailVerifiedAt,
+		&professor.AvaliacaoMedia,
+		&professor.AvaliacoesCount,
 		&professor.CreatedAt,
 		&professor.UpdatedAt,
 	)
@@ -99,8 +313,17 @@ SELECT
 	idade,
 	descricao,
 	valor_hora,
+	duracao_aula,
 	foto_perfil,
+	suspended_at,
+	email_verified_at,
+	avaliacao_media,
+	avaliacoes_count,
 	password,
+	token_version,
+	totp_secret,
+	totp_enabled_at,
+	totp_last_step,
 	created_at,
 	updated_at
 FROM
@@ -120,8 +343,17 @@ func FindProfessorByEmail(email string) (*model.Professor, error) {
 		&professor.Idade,
 		&professor.Descricao,
 		&professor.ValorHora,
+		&professor.DuracaoAula,
 		&professor.FotoPerfil,
+		&professor.SuspendedAt,
+		&professor.EmailVerifiedAt,
+		&professor.AvaliacaoMedia,
+		&professor.AvaliacoesCount,
 		&professor.Password,
+		&professor.TokenVersion,
+		&professor.TotpSecret,
+		&professor.TotpEnabledAt,
+		&professor.TotpLastStep,
 		&professor.CreatedAt,
 		&professor.UpdatedAt,
 	)
@@ -174,6 +406,10 @@ FROM
 	professores
 WHERE
 	id = ?
+AND
+	suspended_at IS NULL
+AND
+	email_verified_at IS NOT NULL
 LIMIT 1
 `
 
@@ -186,9 +422,9 @@ func ExistsProfessorByID(id int64) bool {
 
 const createProfessorQuery = `
 INSERT INTO
-	professores (nome, email, idade, descricao, valor_hora, password)
+	professores (nome, email, idade, descricao, valor_hora, duracao_aula, password)
 VALUES
-	(?, ?, ?, ?, ?, ?)
+	(?, ?, ?, ?, ?, ?, ?)
 `
 
 func CreateProfessor(professor *model.Professor) (*model.Professor, error) {
@@ -199,6 +435,7 @@ func CreateProfessor(professor *model.Professor) (*model.Professor, error) {
 		professor.Idade,
 		professor.Descricao,
 		professor.ValorHora,
+		professor.DuracaoAula,
 		professor.Password,
 	)
 	if err != nil {
@@ -220,6 +457,7 @@ SET
 	idade = ?,
 	descricao = ?,
 	valor_hora = ?,
+	duracao_aula = ?,
 	password = ?
 WHERE
 	id = ?
@@ -233,6 +471,7 @@ func UpdateProfessor(professor *model.Professor) (*model.Professor, error) {
 		professor.Idade,
 		professor.Descricao,
 		professor.ValorHora,
+		professor.DuracaoAula,
 		professor.Password,
 		professor.ID,
 	)
@@ -242,43 +481,187 @@ func UpdateProfessor(professor *model.Professor) (*model.Professor, error) {
 	return FindProfessorByID(professor.ID)
 }
 
-const updateProfessorFotoPerfilByEmailQuery = `
+const updateProfessorFotoPerfilByIDQuery = `
 UPDATE
 	professores
 SET
 	foto_perfil = ?
 WHERE
-	email = ?
+	id = ?
 `
 
-func UpdateProfessorFotoPerfilByEmail(email string, fotoPerfil string) error {
+func UpdateProfessorFotoPerfilByID(id int64, fotoPerfil string) error {
 	_, err := db.Exec(
-		updateProfessorFotoPerfilByEmailQuery,
+		updateProfessorFotoPerfilByIDQuery,
 		fotoPerfil,
-		email,
+		id,
 	)
 	return err
 }
 
-const deleteProfessorByEmailQuery = `
-DELETE FROM
+const findAllProfessorFotoPerfisQuery = `
+SELECT
+	foto_perfil
+FROM
 	professores
 WHERE
-	email = ?
+	foto_perfil IS NOT NULL
 `
 
-func DeleteProfessorByEmail(email string) error {
-	_, err := db.Exec(deleteProfessorByEmailQuery, email)
+// FindAllProfessorFotoPerfis returns the photos referenced by the professores,
+// whatever the state of their account.
+func FindAllProfessorFotoPerfis() ([]string, error) {
+	rows, err := db.Query(findAllProfessorFotoPerfisQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fotoPerfis := []string{}
+	for rows.Next() {
+		var fotoPerfil string
+		if err := rows.Scan(&fotoPerfil); err != nil {
+			return nil, err
+		}
+		fotoPerfis = append(fotoPerfis, fotoPerfil)
+	}
+	return fotoPerfis, rows.Err()
+}
+
+const findAllProfessoresForAdminQuery = `
+SELECT
+	id,
+	nome,
+	email,
+	idade,
+	descricao,
+	valor_hora,
+	duracao_aula,
+	foto_perfil,
+	suspended_at,
+	email_verified_at,
+	avaliacao_media,
+	avaliacoes_count,
+	created_at,
+	updated_at
+FROM
+	professores
+ORDER BY
+	created_at ASC
+`
+
+// FindAllProfessoresForAdmin returns every professor, including the ones that
+// are hidden from the public listing.
+func FindAllProfessoresForAdmin() ([]*model.Professor, error) {
+	rows, err := db.Query(findAllProfessoresForAdminQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	professores := []*model.Professor{}
+	for rows.Next() {
+		professor := &model.Professor{}
+		err := rows.Scan(
+			&professor.ID,
+			&professor.Nome,
+			&professor.Email,
+			&professor.Idade,
+			&professor.Descricao,
+			&professor.ValorHora,
+			&professor.DuracaoAula,
+			&professor.FotoPerfil,
+			&professor.SuspendedAt,
+			&professor.EmailVerifiedAt,
+			&professor.AvaliacaoMedia,
+			&professor.AvaliacoesCount,
+			&professor.CreatedAt,
+			&professor.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		professores = append(professores, professor)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return professores, nil
+}
+
+const suspendProfessorByIDQuery = `
+UPDATE
+	professores
+SET
+	suspended_at = CURRENT_TIMESTAMP
+WHERE
+	id = ?
+AND
+	suspended_at IS NULL
+`
+
+func SuspendProfessorByID(id int64) error {
+	_, err := db.Exec(suspendProfessorByIDQuery, id)
 	return err
 }
 
+const reactivateProfessorByIDQuery = `
+UPDATE
+	professores
+SET
+	suspended_at = NULL
+WHERE
+	id = ?
+`
+
+func ReactivateProfessorByID(id int64) error {
+	_, err := db.Exec(reactivateProfessorByIDQuery, id)
+	return err
+}
+
+const deleteAlunosByProfessorIDQuery = `
+DELETE FROM
+	alunos
+WHERE
+	professor_id = ?
+`
+
+const deleteProfessorByIDQuery = `
+DELETE FROM
+	professores
+WHERE
+	id = ?
+`
+
+// DeleteProfessorByID removes the professor and all of their bookings.
+func DeleteProfessorByID(id int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(deleteAlunosByProfessorIDQuery, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(deleteProfessorByIDQuery, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 const findAlunoByIDQuery = `
 SELECT
 	id,
 	nome,
 	email,
 	data_aula,
+	duracao,
+	status,
 	professor_id,
+	estudante_id,
 	created_at,
 	updated_at
 FROM
@@ -296,7 +679,10 @@ func FindAlunoByID(id int64) (*model.Aluno, error) {
 		&aluno.Nome,
 		&aluno.Email,
 		&aluno.DataAula,
+		&aluno.Duracao,
+		&aluno.Status,
 		&aluno.ProfessorID,
+		&aluno.EstudanteID,
 		&aluno.CreatedAt,
 		&aluno.UpdatedAt,
 	)
@@ -308,13 +694,93 @@ func FindAlunoByID(id int64) (*model.Aluno, error) {
 
 const createAlunoQuery = `
 INSERT INTO
-	alunos (nome, email, data_aula, professor_id)
+	alunos (nome, email, data_aula, duracao, status, professor_id, estudante_id)
 VALUES
-	(?, ?, ?, ?)
+	(?, ?, ?, ?, ?, ?, (SELECT id FROM estudantes WHERE email = ? AND email_verified_at IS NOT NULL))
 `
 
+const lockProfessorByIDQuery = `
+SELECT
+	id
+FROM
+	professores
+WHERE
+	id = ?
+FOR UPDATE
+`
+
+const countAlunosConflitantesQuery = `
+SELECT
+	COUNT(*)
+FROM
+	alunos
+WHERE
+	professor_id = ?
+AND
+	id != ?
+AND
+	status != 'cancelled'
+AND
+	data_aula < ?
+AND
+	DATE_ADD(data_aula, INTERVAL duracao MINUTE) > ?
+`
+
+// ErrAulaConflict is returned when a lesson overlaps another lesson already
+// booked with the same professor.
+var ErrAulaConflict = errors.New("aula conflicts with an existing booking")
+
+// lockAgendaAndCheckConflict locks the professor row for the rest of the
+// transaction, so concurrent bookings for the same professor are serialized,
+// and returns ErrAulaConflict if the lesson overlaps another active one.
+func lockAgendaAndCheckConflict(tx *sql.Tx, aluno *model.Aluno) error {
+	var professorID int64
+	err := tx.QueryRow(lockProfessorByIDQuery, aluno.ProfessorID).Scan(&professorID)
+	if err != nil {
+		return err
+	}
+
+	var conflitos int
+	err = tx.QueryRow(
+		countAlunosConflitantesQuery,
+		aluno.ProfessorID,
+		aluno.ID,
+		aluno.FimAula(),
+		aluno.DataAula,
+	).Scan(&conflitos)
+	if err != nil {
+		return err
+	}
+	if conflitos > 0 {
+		return ErrAulaConflict
+	}
+	return nil
+}
+
+// CreateAluno inserts the booking and its first history entry inside a
+// transaction, rejecting lessons that overlap another active booking of the
+// same professor with ErrAulaConflict.
 func CreateAluno(aluno *model.Aluno) (*model.Aluno, error) {
-	result, err := db.Exec(createAlunoQuery, aluno.Nome, aluno.Email, aluno.DataAula, aluno.ProfessorID)
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockAgendaAndCheckConflict(tx, aluno); err != nil {
+		return nil, err
+	}
+
+	result, err := tx.Exec(
+		createAlunoQuery,
+		aluno.Nome,
+		aluno.Email,
+		aluno.DataAula,
+		aluno.Duracao,
+		model.AulaStatusScheduled,
+		aluno.ProfessorID,
+		aluno.Email,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -322,30 +788,112 @@ func CreateAluno(aluno *model.Aluno) (*model.Aluno, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	err = createAlunoHistorico(tx, &model.AlunoHistorico{
+		AlunoID:          alunoID,
+		StatusNovo:       model.AulaStatusScheduled,
+		DataAulaAnterior: aluno.DataAula,
+		DataAulaNova:     aluno.DataAula,
+		Autor:            model.HistoricoAutorAluno,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 	return FindAlunoByID(alunoID)
 }
 
-const findAlunosByProfessorEmailQuery = `
+const updateAlunoAgendaQuery = `
+UPDATE
+	alunos
+SET
+	data_aula = ?,
+	status = ?
+WHERE
+	id = ?
+AND
+	status = ?
+AND
+	data_aula = ?
+`
+
+// ErrAulaChanged is returned when the lesson no longer has the status and
+// date it had when the transition was decided, because another request
+// changed it in the meantime.
+var ErrAulaChanged = errors.New("aula was changed by another request")
+
+// UpdateAlunoAgenda persists a status transition of the lesson together with
+// its history entry. The lesson is only updated while it still has the status
+// and date recorded as the previous ones in historico, otherwise
+// ErrAulaChanged is returned. When the lesson stays active the conflict check
+// is run again, so a rescheduled lesson cannot overlap another booking.
+func UpdateAlunoAgenda(aluno *model.Aluno, historico *model.AlunoHistorico) (*model.Aluno, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if aluno.IsAtiva() {
+		if err := lockAgendaAndCheckConflict(tx, aluno); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := tx.Exec(
+		updateAlunoAgendaQuery,
+		aluno.DataAula,
+		aluno.Status,
+		aluno.ID,
+		historico.StatusAnterior,
+		historico.DataAulaAnterior,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, ErrAulaChanged
+	}
+
+	historico.AlunoID = aluno.ID
+	if err := createAlunoHistorico(tx, historico); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return FindAlunoByID(aluno.ID)
+}
+
+const findAlunosByProfessorIDQuery = `
 SELECT
-	a.id,
-	a.nome,
-	a.email,
-	a.data_aula,
-	a.professor_id,
-	a.created_at,
-	a.updated_at
+	id,
+	nome,
+	email,
+	data_aula,
+	duracao,
+	status,
+	professor_id,
+	created_at,
+	updated_at
 FROM
-	alunos as a
-LEFT JOIN
-	professores as p
-ON
-	a.professor_id = p.id
+	alunos
 WHERE
-	p.email = ?
+	professor_id = ?
+AND
+	(? = '' OR status = ?)
 `
 
-func FindAlunosByProfessorEmail(email string) ([]*model.Aluno, error) {
-	rows, err := db.Query(findAlunosByProfessorEmailQuery, email)
+func FindAlunosByProfessorID(professorID int64, status string) ([]*model.Aluno, error) {
+	rows, err := db.Query(findAlunosByProfessorIDQuery, professorID, status, status)
 	if err != nil {
 		return nil, err
 	}
@@ -358,6 +906,8 @@ func FindAlunosByProfessorEmail(email string) ([]*model.Aluno, error) {
 			&aluno.Nome,
 			&aluno.Email,
 			&aluno.DataAula,
+			&aluno.Duracao,
+			&aluno.Status,
 			&aluno.ProfessorID,
 			&aluno.CreatedAt,
 			&aluno.UpdatedAt,
@@ -373,50 +923,120 @@ func FindAlunosByProfessorEmail(email string) ([]*model.Aluno, error) {
 	return alunos, nil
 }
 
-const deleteAlunosByProfessorEmailQuery = `
-DELETE FROM
-	alunos
-WHERE
-	professor_id = (
-		SELECT
-			id
-		FROM
-			professores
-		WHERE
-			email = ?
-	)
+const createAlunoHistoricoQuery = `
+INSERT INTO
+	aluno_historico (aluno_id, status_anterior, status_novo, data_aula_anterior, data_aula_nova, autor, motivo)
+VALUES
+	(?, ?, ?, ?, ?, ?, ?)
 `
 
-func DeleteAlunosByProfessorEmail(email string) error {
-	_, err := db.Exec(deleteAlunosByProfessorEmailQuery, email)
+func createAlunoHistorico(tx *sql.Tx, historico *model.AlunoHistorico) error {
+	_, err := tx.Exec(
+		createAlunoHistoricoQuery,
+		historico.AlunoID,
+		historico.StatusAnterior,
+		historico.StatusNovo,
+		historico.DataAulaAnterior,
+		historico.DataAulaNova,
+		historico.Autor,
+		historico.Motivo,
+	)
 	return err
 }
 
-const CreateInvalidatedTokenQuery = `
-INSERT INTO
-	invalidated_tokens (token)
+const findAlunoHistoricoByAlunoIDQuery = `
+SELECT
+	id,
+	aluno_id,
+	status_anterior,
+	status_novo,
+	data_aula_anterior,
+	data_aula_nova,
+	autor,
+	motivo,
+	created_at
+FROM
+	aluno_historico
+WHERE
+	aluno_id = ?
+ORDER BY
+	created_at ASC,
+	id ASC
+`
+
+func FindAlunoHistoricoByAlunoID(alunoID int64) ([]*model.AlunoHistorico, error) {
+	rows, err := db.Query(findAlunoHistoricoByAlunoIDQuery, alunoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	historicos := make([]*model.AlunoHistorico, 0)
+	for rows.Next() {
+		historico := &model.AlunoHistorico{}
+		err := rows.Scan(
+			&historico.ID,
+			&historico.AlunoID,
+			&historico.StatusAnterior,
+			&historico.StatusNovo,
+			&historico.DataAulaAnterior,
+			&historico.DataAulaNova,
+			&historico.Autor,
+			&historico.Motivo,
+			&historico.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		historicos = append(historicos, historico)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return historicos, nil
+}
+
+const createInvalidatedTokenQuery = `
+INSERT IGNORE INTO
+	invalidated_tokens (jti, expires_at)
 VALUES
-	(?)
+	(?, ?)
 `
 
-func CreateInvalidatedToken(token string) error {
-	_, err := db.Exec(CreateInvalidatedTokenQuery, token)
+func CreateInvalidatedToken(jti string, expiresAt time.Time) error {
+	_, err := db.Exec(createInvalidatedTokenQuery, jti, expiresAt)
 	return err
 }
 
-const existsInvalidatedTokenByTokenQuery = `
+const existsInvalidatedTokenByJTIQuery = `
 SELECT
-	token
+	jti
 FROM
 	invalidated_tokens
 WHERE
-	token = ?
+	jti = ?
 LIMIT 1
 `
 
-func ExistsInvalidatedTokenByToken(token string) bool {
-	row := db.QueryRow(existsInvalidatedTokenByTokenQuery, token)
-	var invalidatedToken string
-	err := row.Scan(&invalidatedToken)
-	return err == nil && invalidatedToken == token
+func ExistsInvalidatedTokenByJTI(jti string) bool {
+	row := db.QueryRow(existsInvalidatedTokenByJTIQuery, jti)
+	var invalidatedJTI string
+	err := row.Scan(&invalidatedJTI)
+	return err == nil && invalidatedJTI == jti
+}
+
+const deleteExpiredInvalidatedTokensQuery = `
+DELETE FROM
+	invalidated_tokens
+WHERE
+	expires_at <= ?
+`
+
+// DeleteExpiredInvalidatedTokens removes the tokens that expired before now,
+// they would be rejected anyway. It returns how many were removed.
+func DeleteExpiredInvalidatedTokens(now time.Time) (int64, error) {
+	result, err := db.Exec(deleteExpiredInvalidatedTokensQuery, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+const createRefreshTokenQuery = `
+INSERT INTO
+	refresh_tokens (jti, family_id, parent_jti, subject, role, expires_at)
+VALUES
+	(?, ?, ?, ?, ?, ?)
+`
+
+func CreateRefreshToken(refreshToken *model.RefreshToken) error {
+	_, err := db.Exec(
+		createRefreshTokenQuery,
+		refreshToken.JTI,
+		refreshToken.FamilyID,
+		refreshToken.ParentJTI,
+		refreshToken.Subject,
+		refreshToken.Role,
+		refreshToken.ExpiresAt,
+	)
+	return err
+}
+
+const findRefreshTokenByJTIQuery = `
+SELECT
+	jti,
+	family_id,
+	parent_jti,
+	subject,
+	role,
+	expires_at,
+	rotated_at,
+	revoked_at,
+	created_at
+FROM
+	refresh_tokens
+WHERE
+	jti = ?
+LIMIT 1
+`
+
+func FindRefreshTokenByJTI(jti string) (*model.RefreshToken, error) {
+	row := db.QueryRow(findRefreshTokenByJTIQuery, jti)
+	refreshToken := &model.RefreshToken{}
+	err := row.Scan(
+		&refreshToken.JTI,
+		&refreshToken.FamilyID,
+		&refreshToken.ParentJTI,
+		&refreshToken.Subject,
+		&refreshToken.Role,
+		&refreshToken.ExpiresAt,
+		&refreshToken.RotatedAt,
+		&refreshToken.RevokedAt,
+		&refreshToken.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return refreshToken, nil
+}
+
+const rotateRefreshTokenQuery = `
+UPDATE
+	refresh_tokens
+SET
+	rotated_at = ?
+WHERE
+	jti = ?
+AND
+	rotated_at IS NULL
+AND
+	revoked_at IS NULL
+`
+
+// RotateRefreshToken marks the refresh token as exchanged for a new one. It
+// reports false when the token was already rotated or revoked, which means it
+// is being reused.
+func RotateRefreshToken(jti string) (bool, error) {
+	result, err := db.Exec(rotateRefreshTokenQuery, time.Now(), jti)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
@@ -0,0 +1,255 @@
+package database
+
+import (
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+// ProfessorRepository, AlunoRepository and TokenRepository expose the queries
+// of the package as the repositories the service works with.
+type (
+	ProfessorRepository struct{}
+	AlunoRepository     struct{}
+	TokenRepository     struct{}
+)
+
+func (ProfessorRepository) FindAll(filter *model.ProfessorFilter) ([]*model.Professor, int64, error) {
+	return FindAllProfessores(filter)
+}
+
+func (ProfessorRepository) FindActiveByID(id int64) (*model.Professor, error) {
+	return FindActiveProfessorByID(id)
+}
+
+func (ProfessorRepository) FindByID(id int64) (*model.Professor, error) {
+	return FindProfessorByID(id)
+}
+
+func (ProfessorRepository) FindAllForAdmin() ([]*model.Professor, error) {
+	return FindAllProfessoresForAdmin()
+}
+
+func (ProfessorRepository) FindByEmail(email string) (*model.Professor, error) {
+	return FindProfessorByEmail(email)
+}
+
+func (ProfessorRepository) ExistsByEmail(email string) bool {
+	return ExistsProfessorByEmail(email)
+}
+
+func (ProfessorRepository) ExistsByEmailAndNotID(email string, id int64) bool {
+	return ExistsProfessorByEmailAndNotID(email, id)
+}
+
+func (ProfessorRepository) Create(professor *model.Professor) (*model.Professor, error) {
+	return CreateProfessor(professor)
+}
+
+func (ProfessorRepository) Update(professor *model.Professor) (*model.Professor, error) {
+	return UpdateProfessor(professor)
+}
+
+func (ProfessorRepository) UpdateFotoPerfil(id int64, fotoPerfil string) error {
+	return UpdateProfessorFotoPerfilByID(id, fotoPerfil)
+}
+
+func (ProfessorRepository) Suspend(id int64) error {
+	return SuspendProfessorByID(id)
+}
+
+func (ProfessorRepository) Reactivate(id int64) error {
+	return ReactivateProfessorByID(id)
+}
+
+func (ProfessorRepository) Delete(id int64) error {
+	return DeleteProfessorByID(id)
+}
+
+func (ProfessorRepository) FindDisciplinaIDs(professorIDs []int64) (map[int64][]int64, error) {
+	return FindDisciplinaIDsByProfessorIDs(professorIDs)
+}
+
+func (ProfessorRepository) FindByIdentity(provider, subject string) (*model.Professor, error) {
+	return FindProfessorByIdentity(provider, subject)
+}
+
+func (ProfessorRepository) LinkIdentity(identity *model.ProfessorIdentity, passwordHash string) error {
+	return LinkProfessorIdentity(identity, passwordHash)
+}
+
+func (ProfessorRepository) CreateWithIdentity(professor *model.Professor, identity *model.ProfessorIdentity) (*model.Professor, error) {
+	return CreateProfessorWithIdentity(professor, identity)
+}
+
+func (ProfessorRepository) UpdateTotpSecret(id int64, secret string) error {
+	return UpdateProfessorTotpSecret(id, secret)
+}
+
+func (ProfessorRepository) EnableTotp(id int64, codeHashes []string) error {
+	return EnableProfessorTotp(id, codeHashes)
+}
+
+func (ProfessorRepository) DisableTotp(id int64) error {
+	return DisableProfessorTotp(id)
+}
+
+func (ProfessorRepository) UseTotpStep(id int64, step int64) (bool, error) {
+	return UseProfessorTotpStep(id, step)
+}
+
+func (ProfessorRepository) UseRecoveryCode(id int64, codeHash string) (bool, error) {
+	return UseRecoveryCode(id, codeHash)
+}
+
+func (ProfessorRepository) FindDisponibilidadesByDiaSemana(professorID int64, diaSemana int32) ([]*model.Disponibilidade, error) {
+	return FindDisponibilidadesByProfessorIDAndDiaSemana(professorID, diaSemana)
+}
+
+func (ProfessorRepository) FindDisponibilidadeExcecoesByPeriodo(professorID int64, inicio, fim time.Time) ([]*model.DisponibilidadeExcecao, error) {
+	return FindDisponibilidadeExcecoesByProfessorIDAndPeriodo(professorID, inicio, fim)
+}
+
+func (AlunoRepository) Create(aluno *model.Aluno) (*model.Aluno, error) {
+	return CreateAluno(aluno)
+}
+
+func (AlunoRepository) FindByID(id int64) (*model.Aluno, error) {
+	return FindAlunoByID(id)
+}
+
+func (AlunoRepository) FindByProfessorID(professorID int64, status string) ([]*model.Aluno, error) {
+	return FindAlunosByProfessorID(professorID, status)
+}
+
+func (AlunoRepository) FindByEstudanteID(estudanteID int64, status string) ([]*model.Aluno, error) {
+	return FindAlunosByEstudanteID(estudanteID, status)
+}
+
+func (AlunoRepository) UpdateAgenda(aluno *model.Aluno, historico *model.AlunoHistorico) (*model.Aluno, error) {
+	return UpdateAlunoAgenda(aluno, historico)
+}
+
+func (AlunoRepository) FindHistorico(alunoID int64) ([]*model.AlunoHistorico, error) {
+	return FindAlunoHistoricoByAlunoID(alunoID)
+}
+
+func (AlunoRepository) CreateEstudante(estudante *model.Estudante) (*model.Estudante, error) {
+	return CreateEstudante(estudante)
+}
+
+func (AlunoRepository) FindEstudanteByEmail(email string) (*model.Estudante, error) {
+	return FindEstudanteByEmail(email)
+}
+
+func (TokenRepository) CreateSession(session *model.Session) error {
+	return CreateSession(session)
+}
+
+func (TokenRepository) FindSession(id string) (*model.Session, error) {
+	return FindSessionByID(id)
+}
+
+func (TokenRepository) ExistsActiveSession(id string) bool {
+	return ExistsActiveSessionByID(id)
+}
+
+func (TokenRepository) FindActiveSessions(subject, role string) ([]*model.Session, error) {
+	return FindActiveSessionsBySubject(subject, role)
+}
+
+func (TokenRepository) TouchSession(id string, client *model.SessionClient, expiresAt time.Time) error {
+	return TouchSession(id, client, expiresAt)
+}
+
+func (TokenRepository) RevokeSession(id string) error {
+	return RevokeSession(id)
+}
+
+func (TokenRepository) RevokeOtherSessions(subject, role, currentID string) error {
+	return RevokeOtherSessions(subject, role, currentID)
+}
+
+func (TokenRepository) CreateRefreshToken(refreshToken *model.RefreshToken) error {
+	return CreateRefreshToken(refreshToken)
+}
+
+func (TokenRepository) FindRefreshTokenByJTI(jti string) (*model.RefreshToken, error) {
+	return FindRefreshTokenByJTI(jti)
+}
+
+func (TokenRepository) RotateRefreshToken(jti string) (bool, error) {
+	return RotateRefreshToken(jti)
+}
+
+func (TokenRepository) CreateInvalidatedToken(jti string, expiresAt time.Time) error {
+	return CreateInvalidatedToken(jti, expiresAt)
+}
+
+func (TokenRepository) ExistsInvalidatedToken(jti string) bool {
+	return ExistsInvalidatedTokenByJTI(jti)
+}
+
+func (TokenRepository) CreateEmailVerification(verification *model.EmailVerification) error {
+	return CreateEmailVerification(verification)
+}
+
+func (TokenRepository) FindValidEmailVerification(tokenHash string) (*model.EmailVerification, error) {
+	return FindValidEmailVerificationByTokenHash(tokenHash)
+}
+
+func (TokenRepository) ExistsRecentEmailVerification(professorID int64, seconds int64) bool {
+	return ExistsRecentEmailVerification(professorID, seconds)
+}
+
+func (TokenRepository) VerifyProfessorEmail(verification *model.EmailVerification) error {
+	return VerifyProfessorEmail(verification)
+}
+
+func (TokenRepository) CreateEstudanteEmailVerification(verification *model.EstudanteEmailVerification) error {
+	return CreateEstudanteEmailVerification(verification)
+}
+
+func (TokenRepository) FindValidEstudanteEmailVerification(tokenHash string) (*model.EstudanteEmailVerification, error) {
+	return FindValidEstudanteEmailVerificationByTokenHash(tokenHash)
+}
+
+func (TokenRepository) ExistsRecentEstudanteEmailVerification(estudanteID int64, seconds int64) bool {
+	return ExistsRecentEstudanteEmailVerification(estudanteID, seconds)
+}
+
+func (TokenRepository) VerifyEstudanteEmail(verification *model.EstudanteEmailVerification) error {
+	return VerifyEstudanteEmail(verification)
+}
+
+func (TokenRepository) CreatePasswordReset(reset *model.PasswordReset) error {
+	return CreatePasswordReset(reset)
+}
+
+func (TokenRepository) ExistsRecentPasswordReset(professorID int64, seconds int64) bool {
+	return ExistsRecentPasswordReset(professorID, seconds)
+}
+
+func (TokenRepository) FindValidPasswordReset(tokenHash string) (*model.PasswordReset, error) {
+	return FindValidPasswordResetByTokenHash(tokenHash)
+}
+
+func (TokenRepository) ResetPassword(reset *model.PasswordReset, passwordHash string) error {
+	return ResetProfessorPassword(reset, passwordHash)
+}
+
+func (TokenRepository) CreateOidcState(state *model.OidcState) error {
+	return CreateOidcState(state)
+}
+
+func (TokenRepository) UseOidcState(stateHash string) (*model.OidcState, error) {
+	return UseOidcState(stateHash)
+}
+
+func (TokenRepository) CreateAdministrador(administrador *model.Administrador) (*model.Administrador, error) {
+	return CreateAdministrador(administrador)
+}
+
+func (TokenRepository) FindAdministradorByEmail(email string) (*model.Administrador, error) {
+	return FindAdministradorByEmail(email)
+}
@@ -0,0 +1,258 @@
+package database
+
+import (
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+const createSessionQuery = `
+INSERT INTO
+	sessions (id, subject, role, device, ip, user_agent, expires_at, last_used_at)
+VALUES
+	(?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+func CreateSession(session *model.Session) error {
+	_, err := db.Exec(
+		createSessionQuery,
+		session.ID,
+		session.Subject,
+		session.Role,
+		session.Device,
+		session.IP,
+		session.UserAgent,
+		session.ExpiresAt,
+		session.LastUsedAt,
+	)
+	return err
+}
+
+const findSessionByIDQuery = `
+SELECT
+	id,
+	subject,
+	role,
+	device,
+	ip,
+	user_agent,
+	expires_at,
+	last_used_at,
+	revoked_at,
+	created_at
+FROM
+	sessions
+WHERE
+	id = ?
+LIMIT 1
+`
+
+func FindSessionByID(id string) (*model.Session, error) {
+	row := db.QueryRow(findSessionByIDQuery, id)
+	session := &model.Session{}
+	err := row.Scan(
+		&session.ID,
+		&session.Subject,
+		&session.Role,
+		&session.Device,
+		&session.IP,
+		&session.UserAgent,
+		&session.ExpiresAt,
+		&session.LastUsedAt,
+		&session.RevokedAt,
+		&session.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+const existsActiveSessionByIDQuery = `
+SELECT
+	id
+FROM
+	sessions
+WHERE
+	id = ?
+AND
+	revoked_at IS NULL
+AND
+	expires_at > ?
+LIMIT 1
+`
+
+func ExistsActiveSessionByID(id string) bool {
+	row := db.QueryRow(existsActiveSessionByIDQuery, id, time.Now())
+	var sessionID string
+	err := row.Scan(&sessionID)
+	return err == nil && sessionID == id
+}
+
+const findActiveSessionsBySubjectQuery = `
+SELECT
+	id,
+	subject,
+	role,
+	device,
+	ip,
+	user_agent,
+	expires_at,
+	last_used_at,
+	revoked_at,
+	created_at
+FROM
+	sessions
+WHERE
+	subject = ?
+AND
+	role = ?
+AND
+	revoked_at IS NULL
+AND
+	expires_at > ?
+ORDER BY
+	last_used_at DESC
+`
+
+func FindActiveSessionsBySubject(subject, role string) ([]*model.Session, error) {
+	rows, err := db.Query(findActiveSessionsBySubjectQuery, subject, role, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	sessions := make([]*model.Session, 0)
+	for rows.Next() {
+		session := &model.Session{}
+		err := rows.Scan(
+			&session.ID,
+			&session.Subject,
+			&session.Role,
+			&session.Device,
+			&session.IP,
+			&session.UserAgent,
+			&session.ExpiresAt,
+			&session.LastUsedAt,
+			&session.RevokedAt,
+			&session.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+const touchSessionQuery = `
+UPDATE
+	sessions
+SET
+	ip = ?,
+	user_agent = ?,
+	expires_at = ?,
+	last_used_at = ?
+WHERE
+	id = ?
+`
+
+// TouchSession records that the session was refreshed from client and now
+// lasts until expiresAt.
+func TouchSession(id string, client *model.SessionClient, expiresAt time.Time) error {
+	_, err := db.Exec(touchSessionQuery, client.IP, client.UserAgent, expiresAt, time.Now(), id)
+	return err
+}
+
+const revokeSessionQuery = `
+UPDATE
+	sessions
+SET
+	revoked_at = ?
+WHERE
+	id = ?
+AND
+	revoked_at IS NULL
+`
+
+const revokeRefreshTokenFamilyQuery = `
+UPDATE
+	refresh_tokens
+SET
+	revoked_at = ?
+WHERE
+	family_id = ?
+AND
+	revoked_at IS NULL
+`
+
+// RevokeSession revokes the session and every refresh token of its family.
+func RevokeSession(id string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if _, err := tx.Exec(revokeSessionQuery, now, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(revokeRefreshTokenFamilyQuery, now, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const revokeOtherSessionsQuery = `
+UPDATE
+	sessions
+SET
+	revoked_at = ?
+WHERE
+	subject = ?
+AND
+	role = ?
+AND
+	id != ?
+AND
+	revoked_at IS NULL
+`
+
+const revokeOtherRefreshTokensQuery = `
+UPDATE
+	refresh_tokens
+SET
+	revoked_at = ?
+WHERE
+	subject = ?
+AND
+	role = ?
+AND
+	family_id != ?
+AND
+	revoked_at IS NULL
+`
+
+// RevokeOtherSessions revokes every session of the account except the one
+// identified by currentID, along with their refresh tokens.
+func RevokeOtherSessions(subject, role, currentID string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if _, err := tx.Exec(revokeOtherSessionsQuery, now, subject, role, currentID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(revokeOtherRefreshTokensQuery, now, subject, role, currentID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
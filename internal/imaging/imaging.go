@@ -0,0 +1,59 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+
+	"github.com/gen2brain/webp"
+)
+
+// Qualities the variants are encoded with. WebP reaches the same visual
+// quality as JPEG at a lower setting.
+const (
+	jpegQuality = 85
+	webpQuality = 80
+)
+
+// DecodeConfig returns the format and dimensions of the image held by data
+// without decoding it. The format is empty when data is not an image of a
+// supported format.
+func DecodeConfig(data []byte) (string, int, int) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", 0, 0
+	}
+	return format, config.Width, config.Height
+}
+
+// Decode decodes the image held by data, turned as its EXIF orientation asks.
+// Metadata is not kept.
+func Decode(data []byte) (*image.RGBA, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+
+	if format == "jpeg" {
+		return orient(rgba, orientation(data)), nil
+	}
+	return rgba, nil
+}
+
+// EncodeJPEG writes img to w as a JPEG.
+func EncodeJPEG(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+}
+
+// EncodeWebP writes img to w as a lossy WebP.
+func EncodeWebP(w io.Writer, img image.Image) error {
+	return webp.Encode(w, img, webp.Options{Quality: webpQuality, Method: webp.DefaultMethod})
+}
@@ -0,0 +1,117 @@
+package imaging
+
+import (
+	"image"
+	"math"
+)
+
+// contribution lists the weights of the consecutive source pixels, from
+// start, averaged into a destination pixel.
+type contribution struct {
+	start   int
+	weights []float64
+}
+
+// contributions spreads srcLen source pixels over dstLen destination pixels,
+// each destination pixel averaging the share of the source it covers.
+func contributions(srcLen, dstLen int) []contribution {
+	scale := float64(srcLen) / float64(dstLen)
+	out := make([]contribution, dstLen)
+	for i := range out {
+		from := float64(i) * scale
+		to := from + scale
+		start := int(from)
+		end := int(math.Ceil(to))
+		if end > srcLen {
+			end = srcLen
+		}
+
+		weights := make([]float64, end-start)
+		var total float64
+		for j := start; j < end; j++ {
+			weights[j-start] = math.Min(to, float64(j+1)) - math.Max(from, float64(j))
+			total += weights[j-start]
+		}
+		for j := range weights {
+			weights[j] /= total
+		}
+		out[i] = contribution{start: start, weights: weights}
+	}
+	return out
+}
+
+// Square crops the center of img to a square and scales it to size×size
+// pixels. Transparent areas are laid over white, as not every format the
+// result may be encoded in supports transparency.
+func Square(img *image.RGBA, size int) *image.RGBA {
+	bounds := img.Bounds()
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+	x0 := bounds.Min.X + (bounds.Dx()-side)/2
+	y0 := bounds.Min.Y + (bounds.Dy()-side)/2
+	return resize(img, image.Rect(x0, y0, x0+side, y0+side), size, size)
+}
+
+// resize scales the r area of src to width×height pixels, over white.
+func resize(src *image.RGBA, r image.Rectangle, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	cols := contributions(r.Dx(), width)
+	rows := contributions(r.Dy(), height)
+
+	// Rows are scaled horizontally one at a time, then summed into the
+	// destination row they contribute to, to keep memory bounded by the
+	// destination width.
+	row := make([]float64, width*4)
+	acc := make([]float64, width*4)
+	for y, cy := range rows {
+		for i := range acc {
+			acc[i] = 0
+		}
+		for k, wy := range cy.weights {
+			resizeRow(src, r.Min.X, r.Min.Y+cy.start+k, cols, row)
+			for i, v := range row {
+				acc[i] += v * wy
+			}
+		}
+
+		offset := dst.PixOffset(0, y)
+		for x := 0; x < width; x++ {
+			// Channels are premultiplied, what the alpha leaves uncovered
+			// shows the white background
+			white := 255 - acc[x*4+3]
+			for c := 0; c < 3; c++ {
+				dst.Pix[offset+x*4+c] = clamp(acc[x*4+c] + white)
+			}
+			dst.Pix[offset+x*4+3] = 0xFF
+		}
+	}
+	return dst
+}
+
+// resizeRow scales the row y of src, starting at x0, into row.
+func resizeRow(src *image.RGBA, x0, y int, cols []contribution, row []float64) {
+	offset := src.PixOffset(x0, y)
+	for x, cx := range cols {
+		var r, g, b, a float64
+		for k, w := range cx.weights {
+			p := src.Pix[offset+(cx.start+k)*4:]
+			r += float64(p[0]) * w
+			g += float64(p[1]) * w
+			b += float64(p[2]) * w
+			a += float64(p[3]) * w
+		}
+		row[x*4], row[x*4+1], row[x*4+2], row[x*4+3] = r, g, b, a
+	}
+}
+
+func clamp(v float64) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
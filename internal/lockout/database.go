@@ -0,0 +1,45 @@
+package lockout
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/database"
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+type databaseTracker struct{}
+
+// NewDatabaseTracker returns a Tracker backed by the login_attempts table, so
+// every instance of the API shares the same counts.
+func NewDatabaseTracker() Tracker {
+	return databaseTracker{}
+}
+
+func (databaseTracker) Find(key model.LoginAttemptKey) (*model.LoginAttempt, error) {
+	attempt, err := database.FindLoginAttempt(key)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return attempt, err
+}
+
+func (databaseTracker) AddFailure(key model.LoginAttemptKey, now, windowStart time.Time) (*model.LoginAttempt, error) {
+	return database.AddLoginFailure(key, now, windowStart)
+}
+
+func (databaseTracker) Lock(key model.LoginAttemptKey, until time.Time) error {
+	return database.LockLoginAttempt(key, until)
+}
+
+func (databaseTracker) Reset(key model.LoginAttemptKey) error {
+	return database.DeleteLoginAttempt(key)
+}
+
+func (databaseTracker) FindLocked(now time.Time) ([]*model.LoginAttempt, error) {
+	return database.FindLockedLoginAttempts(now)
+}
+
+func (databaseTracker) Purge(before time.Time) (int64, error) {
+	return database.DeleteStaleLoginAttempts(before)
+}
@@ -0,0 +1,56 @@
+package lockout
+
+import (
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+// Tracker stores the failed login attempts of accounts and IP addresses.
+type Tracker interface {
+	// Find returns the attempts recorded for key, or nil when there are none.
+	Find(key model.LoginAttemptKey) (*model.LoginAttempt, error)
+	// AddFailure records a failed login for key at now. The count starts over
+	// when the previous failure happened before windowStart.
+	AddFailure(key model.LoginAttemptKey, now, windowStart time.Time) (*model.LoginAttempt, error)
+	// Lock refuses the logins of key until the given time.
+	Lock(key model.LoginAttemptKey, until time.Time) error
+	// Reset forgets the attempts recorded for key.
+	Reset(key model.LoginAttemptKey) error
+	// FindLocked returns the attempts still locked at now.
+	FindLocked(now time.Time) ([]*model.LoginAttempt, error)
+	// Purge forgets the attempts that are neither locked nor have failed since
+	// before. It returns how many were removed.
+	Purge(before time.Time) (int64, error)
+}
+
+var tracker Tracker = NewMemoryTracker()
+
+// Init sets the tracker used by the package functions.
+func Init(t Tracker) {
+	tracker = t
+}
+
+func Find(key model.LoginAttemptKey) (*model.LoginAttempt, error) {
+	return tracker.Find(key)
+}
+
+func AddFailure(key model.LoginAttemptKey, now, windowStart time.Time) (*model.LoginAttempt, error) {
+	return tracker.AddFailure(key, now, windowStart)
+}
+
+func Lock(key model.LoginAttemptKey, until time.Time) error {
+	return tracker.Lock(key, until)
+}
+
+func Reset(key model.LoginAttemptKey) error {
+	return tracker.Reset(key)
+}
+
+func FindLocked(now time.Time) ([]*model.LoginAttempt, error) {
+	return tracker.FindLocked(now)
+}
+
+func Purge(before time.Time) (int64, error) {
+	return tracker.Purge(before)
+}
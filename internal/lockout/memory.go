@@ -0,0 +1,104 @@
+package lockout
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+type memoryTracker struct {
+	mu       sync.Mutex
+	attempts map[model.LoginAttemptKey]*model.LoginAttempt
+}
+
+// NewMemoryTracker returns a Tracker that keeps the attempts in the process
+// memory. They are lost on restart and are not shared between instances.
+func NewMemoryTracker() Tracker {
+	return &memoryTracker{
+		attempts: map[model.LoginAttemptKey]*model.LoginAttempt{},
+	}
+}
+
+func (t *memoryTracker) Find(key model.LoginAttemptKey) (*model.LoginAttempt, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	attempt, ok := t.attempts[key]
+	if !ok {
+		return nil, nil
+	}
+	copied := *attempt
+	return &copied, nil
+}
+
+func (t *memoryTracker) AddFailure(key model.LoginAttemptKey, now, windowStart time.Time) (*model.LoginAttempt, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	attempt, ok := t.attempts[key]
+	if !ok {
+		attempt = &model.LoginAttempt{LoginAttemptKey: key}
+		t.attempts[key] = attempt
+	}
+	if attempt.LastFailureAt.Before(windowStart) {
+		attempt.Failures = 0
+		attempt.LockedUntil = model.NullTime{}
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = now
+
+	copied := *attempt
+	return &copied, nil
+}
+
+func (t *memoryTracker) Lock(key model.LoginAttemptKey, until time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if attempt, ok := t.attempts[key]; ok {
+		attempt.LockedUntil.Time = until
+		attempt.LockedUntil.Valid = true
+	}
+	return nil
+}
+
+func (t *memoryTracker) Reset(key model.LoginAttemptKey) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.attempts, key)
+	return nil
+}
+
+func (t *memoryTracker) FindLocked(now time.Time) ([]*model.LoginAttempt, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	locked := make([]*model.LoginAttempt, 0)
+	for _, attempt := range t.attempts {
+		if attempt.IsLocked(now) {
+			copied := *attempt
+			locked = append(locked, &copied)
+		}
+	}
+	sort.Slice(locked, func(i, j int) bool {
+		return locked[i].LockedUntil.Time.After(locked[j].LockedUntil.Time)
+	})
+	return locked, nil
+}
+
+func (t *memoryTracker) Purge(before time.Time) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var removed int64
+	for key, attempt := range t.attempts {
+		if attempt.LastFailureAt.Before(before) && !attempt.IsLocked(before) {
+			delete(t.attempts, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type logMailer struct {
+	dir string
+}
+
+// NewLogMailer returns a Mailer meant for development. Only the recipient and
+// subject are logged, as bodies carry single-use tokens; when dir is not
+// empty the whole message is saved as an .eml file inside it.
+func NewLogMailer(dir string) Mailer {
+	return &logMailer{dir: dir}
+}
+
+func (m *logMailer) Send(msg *Message) error {
+	log.Info().
+		Str("to", msg.To).
+		Str("subject", msg.Subject).
+		Int("body_length", len(msg.Body)).
+		Msg("email not delivered, the log mailer is in use")
+
+	data, err := buildMessage("", msg)
+	if err != nil {
+		return err
+	}
+	if m.dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.dir, os.ModePerm); err != nil {
+		return err
+	}
+	// The recipient is left out of the name, it is user input
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), uuid.NewString())
+	return os.WriteFile(filepath.Join(m.dir, name), data, 0600)
+}
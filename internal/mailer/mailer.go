@@ -0,0 +1,24 @@
+package mailer
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages to their recipients.
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+var mailer Mailer = NewLogMailer("")
+
+// Init sets the mailer used by Send.
+func Init(m Mailer) {
+	mailer = m
+}
+
+func Send(msg *Message) error {
+	return mailer.Send(msg)
+}
@@ -0,0 +1,222 @@
+package memory
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/database"
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+var errDuplicateEstudanteEmail = errors.New("memory: duplicate estudante email")
+
+type AlunoRepository struct {
+	db *Database
+}
+
+// conflicts reports whether the lesson overlaps another active booking of
+// the same professor.
+func (r *AlunoRepository) conflicts(aluno *model.Aluno) bool {
+	for _, booked := range r.db.alunos {
+		if booked.ProfessorID == aluno.ProfessorID &&
+			booked.ID != aluno.ID &&
+			booked.Status != model.AulaStatusCancelled &&
+			booked.DataAula.Before(aluno.FimAula()) &&
+			booked.FimAula().After(aluno.DataAula) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *AlunoRepository) createHistorico(historico *model.AlunoHistorico) {
+	r.db.lastHistoricoID++
+	created := *historico
+	created.ID = r.db.lastHistoricoID
+	created.CreatedAt = time.Now()
+	r.db.historicos[created.ID] = &created
+}
+
+// Create books the lesson, rejecting lessons that overlap another active
+// booking of the same professor with database.ErrAulaConflict. Like the
+// database, it links the lesson to the student verified with its email.
+func (r *AlunoRepository) Create(aluno *model.Aluno) (*model.Aluno, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.professores[aluno.ProfessorID]; !ok {
+		return nil, sql.ErrNoRows
+	}
+	if r.conflicts(aluno) {
+		return nil, database.ErrAulaConflict
+	}
+
+	r.db.lastAlunoID++
+	now := time.Now()
+	created := &model.Aluno{
+		ID:          r.db.lastAlunoID,
+		ProfessorID: aluno.ProfessorID,
+		Nome:        aluno.Nome,
+		Email:       aluno.Email,
+		DataAula:    aluno.DataAula,
+		Duracao:     aluno.Duracao,
+		Status:      model.AulaStatusScheduled,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	for _, estudante := range r.db.estudantes {
+		if estudante.Email == aluno.Email && estudante.EmailVerifiedAt.Valid {
+			created.EstudanteID = sql.NullInt64{Int64: estudante.ID, Valid: true}
+		}
+	}
+	r.db.alunos[created.ID] = created
+	r.createHistorico(&model.AlunoHistorico{
+		AlunoID:          created.ID,
+		StatusNovo:       model.AulaStatusScheduled,
+		DataAulaAnterior: created.DataAula,
+		DataAulaNova:     created.DataAula,
+		Autor:            model.HistoricoAutorAluno,
+	})
+
+	result := *created
+	return &result, nil
+}
+
+func (r *AlunoRepository) FindByID(id int64) (*model.Aluno, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	aluno, ok := r.db.alunos[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	result := *aluno
+	return &result, nil
+}
+
+func (r *AlunoRepository) FindByProfessorID(professorID int64, status string) ([]*model.Aluno, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	alunos := make([]*model.Aluno, 0)
+	for _, aluno := range r.db.alunos {
+		if aluno.ProfessorID == professorID && (status == "" || aluno.Status == status) {
+			result := *aluno
+			alunos = append(alunos, &result)
+		}
+	}
+	sort.Slice(alunos, func(i, j int) bool {
+		return alunos[i].ID < alunos[j].ID
+	})
+	return alunos, nil
+}
+
+// FindByEstudanteID returns the lessons linked to the student, each along
+// with its professor, latest first.
+func (r *AlunoRepository) FindByEstudanteID(estudanteID int64, status string) ([]*model.Aluno, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	alunos := make([]*model.Aluno, 0)
+	for _, aluno := range r.db.alunos {
+		if aluno.EstudanteID.Valid && aluno.EstudanteID.Int64 == estudanteID && (status == "" || aluno.Status == status) {
+			result := *aluno
+			result.Professor = public(r.db.professores[aluno.ProfessorID])
+			alunos = append(alunos, &result)
+		}
+	}
+	sort.Slice(alunos, func(i, j int) bool {
+		return alunos[i].DataAula.After(alunos[j].DataAula)
+	})
+	return alunos, nil
+}
+
+// UpdateAgenda persists a status transition of the lesson together with
+// historico. It returns database.ErrAulaChanged when the lesson no longer has
+// the previous status and date of historico. Lessons that stay active are
+// rejected with database.ErrAulaConflict when they overlap another booking.
+func (r *AlunoRepository) UpdateAgenda(aluno *model.Aluno, historico *model.AlunoHistorico) (*model.Aluno, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	stored, ok := r.db.alunos[aluno.ID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	if stored.Status != historico.StatusAnterior || !stored.DataAula.Equal(historico.DataAulaAnterior) {
+		return nil, database.ErrAulaChanged
+	}
+	if aluno.IsAtiva() && r.conflicts(aluno) {
+		return nil, database.ErrAulaConflict
+	}
+
+	stored.DataAula = aluno.DataAula
+	stored.Status = aluno.Status
+	stored.UpdatedAt = time.Now()
+	created := *historico
+	created.AlunoID = stored.ID
+	r.createHistorico(&created)
+
+	result := *stored
+	return &result, nil
+}
+
+// FindHistorico returns the history of the lesson, oldest first.
+func (r *AlunoRepository) FindHistorico(alunoID int64) ([]*model.AlunoHistorico, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	historicos := make([]*model.AlunoHistorico, 0)
+	for _, historico := range r.db.historicos {
+		if historico.AlunoID == alunoID {
+			result := *historico
+			historicos = append(historicos, &result)
+		}
+	}
+	sort.Slice(historicos, func(i, j int) bool {
+		return historicos[i].ID < historicos[j].ID
+	})
+	return historicos, nil
+}
+
+func (r *AlunoRepository) CreateEstudante(estudante *model.Estudante) (*model.Estudante, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, registered := range r.db.estudantes {
+		if registered.Email == estudante.Email {
+			return nil, errDuplicateEstudanteEmail
+		}
+	}
+
+	r.db.lastEstudanteID++
+	now := time.Now()
+	created := &model.Estudante{
+		ID:        r.db.lastEstudanteID,
+		Nome:      estudante.Nome,
+		Email:     estudante.Email,
+		Password:  estudante.Password,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	r.db.estudantes[created.ID] = created
+
+	result := *created
+	result.Password = ""
+	return &result, nil
+}
+
+func (r *AlunoRepository) FindEstudanteByEmail(email string) (*model.Estudante, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, estudante := range r.db.estudantes {
+		if estudante.Email == email {
+			result := *estudante
+			return &result, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+// Database keeps in memory what the MySQL database keeps, for the tests of
+// the service. The repositories it hands out share its data like the tables
+// of a single database, so deleting a professor also deletes their bookings.
+type Database struct {
+	mu sync.Mutex
+
+	lastProfessorID       int64
+	lastAlunoID           int64
+	lastDisponibilidadeID int64
+	lastExcecaoID         int64
+	lastVerificationID    int64
+	lastIdentityID        int64
+	lastOidcStateID       int64
+	lastHistoricoID       int64
+	lastEstudanteID       int64
+	lastAdministradorID   int64
+	lastPasswordResetID   int64
+
+	lastEstudanteVerificationID int64
+
+	professores          map[int64]*model.Professor
+	professorDisciplinas map[int64][]int64
+	disponibilidades     map[int64]*model.Disponibilidade
+	excecoes             map[int64]*model.DisponibilidadeExcecao
+	alunos               map[int64]*model.Aluno
+	sessions             map[string]*model.Session
+	refreshTokens        map[string]*model.RefreshToken
+	invalidatedTokens    map[string]time.Time
+	emailVerifications   map[int64]*model.EmailVerification
+	identities           map[int64]*model.ProfessorIdentity
+	oidcStates           map[int64]*model.OidcState
+	historicos           map[int64]*model.AlunoHistorico
+	estudantes           map[int64]*model.Estudante
+	administradores      map[int64]*model.Administrador
+	passwordResets       map[int64]*model.PasswordReset
+	// recoveryCodes maps the hashes of the recovery codes of each professor
+	// to whether they were used.
+	recoveryCodes          map[int64]map[string]bool
+	estudanteVerifications map[int64]*model.EstudanteEmailVerification
+}
+
+func NewDatabase() *Database {
+	return &Database{
+		professores:          map[int64]*model.Professor{},
+		professorDisciplinas: map[int64][]int64{},
+		disponibilidades:     map[int64]*model.Disponibilidade{},
+		excecoes:             map[int64]*model.DisponibilidadeExcecao{},
+		alunos:               map[int64]*model.Aluno{},
+		sessions:             map[string]*model.Session{},
+		refreshTokens:        map[string]*model.RefreshToken{},
+		invalidatedTokens:    map[string]time.Time{},
+		emailVerifications:   map[int64]*model.EmailVerification{},
+		identities:           map[int64]*model.ProfessorIdentity{},
+		oidcStates:           map[int64]*model.OidcState{},
+		historicos:           map[int64]*model.AlunoHistorico{},
+		estudantes:           map[int64]*model.Estudante{},
+		administradores:      map[int64]*model.Administrador{},
+		passwordResets:       map[int64]*model.PasswordReset{},
+
+		recoveryCodes:          map[int64]map[string]bool{},
+		estudanteVerifications: map[int64]*model.EstudanteEmailVerification{},
+	}
+}
+
+func (db *Database) Professores() *ProfessorRepository {
+	return &ProfessorRepository{db: db}
+}
+
+func (db *Database) Alunos() *AlunoRepository {
+	return &AlunoRepository{db: db}
+}
+
+func (db *Database) Tokens() *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+// The methods below stand in for the parts of the API the repositories do
+// not cover, so tests can arrange the data they need.
+
+// VerifyProfessorEmail makes the professor visible to the public.
+func (db *Database) VerifyProfessorEmail(professorID int64) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if professor, ok := db.professores[professorID]; ok {
+		professor.EmailVerifiedAt = model.NullTime{NullTime: sqlTime(time.Now())}
+	}
+}
+
+// SuspendProfessor hides the professor from the public and keeps them from
+// logging in.
+func (db *Database) SuspendProfessor(professorID int64) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if professor, ok := db.professores[professorID]; ok {
+		professor.SuspendedAt = model.NullTime{NullTime: sqlTime(time.Now())}
+	}
+}
+
+// SetProfessorDisciplinas replaces the ids of the subjects the professor
+// teaches.
+func (db *Database) SetProfessorDisciplinas(professorID int64, disciplinaIDs []int64) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if len(disciplinaIDs) == 0 {
+		delete(db.professorDisciplinas, professorID)
+		return
+	}
+	db.professorDisciplinas[professorID] = append([]int64{}, disciplinaIDs...)
+}
+
+func (db *Database) CreateDisponibilidade(disponibilidade *model.Disponibilidade) *model.Disponibilidade {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.lastDisponibilidadeID++
+	created := *disponibilidade
+	created.ID = db.lastDisponibilidadeID
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	db.disponibilidades[created.ID] = &created
+
+	result := created
+	return &result
+}
+
+func (db *Database) CreateDisponibilidadeExcecao(excecao *model.DisponibilidadeExcecao) *model.DisponibilidadeExcecao {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.lastExcecaoID++
+	created := *excecao
+	created.ID = db.lastExcecaoID
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	db.excecoes[created.ID] = &created
+
+	result := created
+	return &result
+}
+
+// EmailVerifications returns the verification links sent to the professor,
+// oldest first.
+func (db *Database) EmailVerifications(professorID int64) []*model.EmailVerification {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	verifications := []*model.EmailVerification{}
+	for id := int64(1); id <= db.lastVerificationID; id++ {
+		verification, ok := db.emailVerifications[id]
+		if ok && verification.ProfessorID == professorID {
+			result := *verification
+			verifications = append(verifications, &result)
+		}
+	}
+	return verifications
+}
+
+func sqlTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: true}
+}
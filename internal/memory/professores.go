@@ -0,0 +1,559 @@
+package memory
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+var (
+	errDuplicateEmail    = errors.New("memory: duplicate professor email")
+	errDuplicateIdentity = errors.New("memory: duplicate professor identity")
+)
+
+type ProfessorRepository struct {
+	db *Database
+}
+
+// public returns a copy of professor without what the queries listing the
+// professores leave out, such as their credentials.
+func public(professor *model.Professor) *model.Professor {
+	result := *professor
+	result.Password = ""
+	result.TokenVersion = 0
+	result.TotpSecret = model.NullString{}
+	result.TotpEnabledAt = model.NullTime{}
+	result.TotpLastStep = 0
+	return &result
+}
+
+func isActive(professor *model.Professor) bool {
+	return !professor.SuspendedAt.Valid && professor.EmailVerifiedAt.Valid
+}
+
+func (r *ProfessorRepository) matches(professor *model.Professor, filter *model.ProfessorFilter) bool {
+	if !isActive(professor) {
+		return false
+	}
+	if filter.Q != "" && !containsID(filter.IDs, professor.ID) {
+		return false
+	}
+	if filter.Subject > 0 {
+		teaches := false
+		for _, id := range r.db.professorDisciplinas[professor.ID] {
+			teaches = teaches || containsID(filter.DisciplinaIDs, id)
+		}
+		if !teaches {
+			return false
+		}
+	}
+	if filter.Nome != "" && !strings.Contains(strings.ToLower(professor.Nome), strings.ToLower(filter.Nome)) {
+		return false
+	}
+	if filter.ValorHoraMin > 0 && professor.ValorHora < filter.ValorHoraMin {
+		return false
+	}
+	if filter.ValorHoraMax > 0 && professor.ValorHora > filter.ValorHoraMax {
+		return false
+	}
+	if filter.IdadeMin > 0 && professor.Idade < filter.IdadeMin {
+		return false
+	}
+	if filter.IdadeMax > 0 && professor.Idade > filter.IdadeMax {
+		return false
+	}
+	return true
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// professorLess orders the professores like the ORDER BY clauses of the
+// database package.
+func professorLess(sortBy string, ids []int64, a, b *model.Professor) bool {
+	switch sortBy {
+	case model.ProfessorSortRelevance:
+		return indexOf(ids, a.ID) < indexOf(ids, b.ID)
+	case model.ProfessorSortNewest:
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	case model.ProfessorSortPriceAsc:
+		if a.ValorHora != b.ValorHora {
+			return a.ValorHora < b.ValorHora
+		}
+		return a.ID < b.ID
+	case model.ProfessorSortPriceDesc:
+		if a.ValorHora != b.ValorHora {
+			return a.ValorHora > b.ValorHora
+		}
+		return a.ID > b.ID
+	case model.ProfessorSortRating:
+		if a.AvaliacaoMedia != b.AvaliacaoMedia {
+			return a.AvaliacaoMedia > b.AvaliacaoMedia
+		}
+		if a.AvaliacoesCount != b.AvaliacoesCount {
+			return a.AvaliacoesCount > b.AvaliacoesCount
+		}
+		return a.ID < b.ID
+	default:
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	}
+}
+
+func indexOf(ids []int64, id int64) int {
+	for i, candidate := range ids {
+		if candidate == id {
+			return i
+		}
+	}
+	return len(ids)
+}
+
+func (r *ProfessorRepository) FindAll(filter *model.ProfessorFilter) ([]*model.Professor, int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	matching := []*model.Professor{}
+	for _, professor := range r.db.professores {
+		if r.matches(professor, filter) {
+			matching = append(matching, professor)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		return professorLess(filter.Sort, filter.IDs, matching[i], matching[j])
+	})
+
+	professores := []*model.Professor{}
+	offset := int(filter.Offset())
+	for i := offset; i < len(matching) && i < offset+int(filter.Limit); i++ {
+		professores = append(professores, public(matching[i]))
+	}
+	return professores, int64(len(matching)), nil
+}
+
+func (r *ProfessorRepository) FindActiveByID(id int64) (*model.Professor, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	professor, ok := r.db.professores[id]
+	if !ok || !isActive(professor) {
+		return nil, sql.ErrNoRows
+	}
+	return public(professor), nil
+}
+
+// FindByID returns the professor regardless of the account state.
+func (r *ProfessorRepository) FindByID(id int64) (*model.Professor, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	professor, ok := r.db.professores[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return public(professor), nil
+}
+
+// FindAllForAdmin returns every professor, including the ones hidden from
+// the public, oldest first.
+func (r *ProfessorRepository) FindAllForAdmin() ([]*model.Professor, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	professores := []*model.Professor{}
+	for _, professor := range r.db.professores {
+		professores = append(professores, public(professor))
+	}
+	sort.Slice(professores, func(i, j int) bool {
+		return professorLess("", nil, professores[i], professores[j])
+	})
+	return professores, nil
+}
+
+func (r *ProfessorRepository) FindByEmail(email string) (*model.Professor, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, professor := range r.db.professores {
+		if professor.Email == email {
+			result := *professor
+			return &result, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *ProfessorRepository) ExistsByEmail(email string) bool {
+	return r.ExistsByEmailAndNotID(email, 0)
+}
+
+func (r *ProfessorRepository) ExistsByEmailAndNotID(email string, id int64) bool {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, professor := range r.db.professores {
+		if professor.Email == email && professor.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ProfessorRepository) emailTaken(email string, id int64) bool {
+	for _, professor := range r.db.professores {
+		if professor.Email == email && professor.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ProfessorRepository) Create(professor *model.Professor) (*model.Professor, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if r.emailTaken(professor.Email, 0) {
+		return nil, errDuplicateEmail
+	}
+
+	r.db.lastProfessorID++
+	now := time.Now()
+	created := &model.Professor{
+		ID:          r.db.lastProfessorID,
+		Nome:        professor.Nome,
+		Email:       professor.Email,
+		Idade:       professor.Idade,
+		Descricao:   professor.Descricao,
+		ValorHora:   professor.ValorHora,
+		DuracaoAula: professor.DuracaoAula,
+		Password:    professor.Password,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	r.db.professores[created.ID] = created
+	return public(created), nil
+}
+
+func (r *ProfessorRepository) Update(professor *model.Professor) (*model.Professor, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	stored, ok := r.db.professores[professor.ID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	if r.emailTaken(professor.Email, professor.ID) {
+		return nil, errDuplicateEmail
+	}
+
+	stored.Nome = professor.Nome
+	stored.Email = professor.Email
+	stored.Idade = professor.Idade
+	stored.Descricao = professor.Descricao
+	stored.ValorHora = professor.ValorHora
+	stored.DuracaoAula = professor.DuracaoAula
+	stored.Password = professor.Password
+	stored.UpdatedAt = time.Now()
+	return public(stored), nil
+}
+
+func (r *ProfessorRepository) UpdateFotoPerfil(id int64, fotoPerfil string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if professor, ok := r.db.professores[id]; ok {
+		professor.FotoPerfil = model.NullString{NullString: sql.NullString{String: fotoPerfil, Valid: true}}
+	}
+	return nil
+}
+
+func (r *ProfessorRepository) Suspend(id int64) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if professor, ok := r.db.professores[id]; ok && !professor.SuspendedAt.Valid {
+		professor.SuspendedAt = model.NullTime{NullTime: sqlTime(time.Now())}
+	}
+	return nil
+}
+
+func (r *ProfessorRepository) Reactivate(id int64) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if professor, ok := r.db.professores[id]; ok {
+		professor.SuspendedAt = model.NullTime{}
+	}
+	return nil
+}
+
+func (r *ProfessorRepository) Delete(id int64) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for alunoID, aluno := range r.db.alunos {
+		if aluno.ProfessorID == id {
+			delete(r.db.alunos, alunoID)
+		}
+	}
+	for historicoID, historico := range r.db.historicos {
+		if _, ok := r.db.alunos[historico.AlunoID]; !ok {
+			delete(r.db.historicos, historicoID)
+		}
+	}
+	for disponibilidadeID, disponibilidade := range r.db.disponibilidades {
+		if disponibilidade.ProfessorID == id {
+			delete(r.db.disponibilidades, disponibilidadeID)
+		}
+	}
+	for excecaoID, excecao := range r.db.excecoes {
+		if excecao.ProfessorID == id {
+			delete(r.db.excecoes, excecaoID)
+		}
+	}
+	for identityID, identity := range r.db.identities {
+		if identity.ProfessorID == id {
+			delete(r.db.identities, identityID)
+		}
+	}
+	delete(r.db.professorDisciplinas, id)
+	delete(r.db.recoveryCodes, id)
+	delete(r.db.professores, id)
+	return nil
+}
+
+func (r *ProfessorRepository) FindDisciplinaIDs(professorIDs []int64) (map[int64][]int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	disciplinaIDs := map[int64][]int64{}
+	for _, professorID := range professorIDs {
+		if ids, ok := r.db.professorDisciplinas[professorID]; ok {
+			disciplinaIDs[professorID] = append([]int64{}, ids...)
+		}
+	}
+	return disciplinaIDs, nil
+}
+
+func (r *ProfessorRepository) FindDisponibilidadesByDiaSemana(professorID int64, diaSemana int32) ([]*model.Disponibilidade, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	disponibilidades := []*model.Disponibilidade{}
+	for _, disponibilidade := range r.db.disponibilidades {
+		if disponibilidade.ProfessorID == professorID && disponibilidade.DiaSemana == diaSemana {
+			result := *disponibilidade
+			disponibilidades = append(disponibilidades, &result)
+		}
+	}
+	sort.Slice(disponibilidades, func(i, j int) bool {
+		return disponibilidades[i].HoraInicio < disponibilidades[j].HoraInicio
+	})
+	return disponibilidades, nil
+}
+
+func (r *ProfessorRepository) FindDisponibilidadeExcecoesByPeriodo(professorID int64, inicio, fim time.Time) ([]*model.DisponibilidadeExcecao, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	excecoes := []*model.DisponibilidadeExcecao{}
+	for _, excecao := range r.db.excecoes {
+		if excecao.ProfessorID == professorID && excecao.Inicio.Before(fim) && excecao.Fim.After(inicio) {
+			result := *excecao
+			excecoes = append(excecoes, &result)
+		}
+	}
+	sort.Slice(excecoes, func(i, j int) bool {
+		return excecoes[i].Inicio.Before(excecoes[j].Inicio)
+	})
+	return excecoes, nil
+}
+
+func (r *ProfessorRepository) FindByIdentity(provider, subject string) (*model.Professor, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	identity := r.findIdentity(provider, subject)
+	if identity == nil {
+		return nil, sql.ErrNoRows
+	}
+	professor, ok := r.db.professores[identity.ProfessorID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	result := *professor
+	return &result, nil
+}
+
+func (r *ProfessorRepository) findIdentity(provider, subject string) *model.ProfessorIdentity {
+	for _, identity := range r.db.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity
+		}
+	}
+	return nil
+}
+
+func (r *ProfessorRepository) createIdentity(identity *model.ProfessorIdentity) error {
+	if r.findIdentity(identity.Provider, identity.Subject) != nil {
+		return errDuplicateIdentity
+	}
+
+	r.db.lastIdentityID++
+	created := *identity
+	created.ID = r.db.lastIdentityID
+	created.CreatedAt = time.Now()
+	r.db.identities[created.ID] = &created
+	return nil
+}
+
+// LinkIdentity links identity to an existing professor. When the professor
+// never verified their email, it is marked as verified, their password is
+// replaced by passwordHash and their tokens are revoked.
+func (r *ProfessorRepository) LinkIdentity(identity *model.ProfessorIdentity, passwordHash string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	professor, ok := r.db.professores[identity.ProfessorID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if err := r.createIdentity(identity); err != nil {
+		return err
+	}
+
+	if !professor.EmailVerifiedAt.Valid {
+		professor.EmailVerifiedAt = model.NullTime{NullTime: sqlTime(time.Now())}
+		professor.Password = passwordHash
+		professor.TokenVersion++
+	}
+	return nil
+}
+
+// CreateWithIdentity creates a professor whose email was verified by the
+// provider of identity and links them together.
+func (r *ProfessorRepository) CreateWithIdentity(professor *model.Professor, identity *model.ProfessorIdentity) (*model.Professor, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if r.emailTaken(professor.Email, 0) {
+		return nil, errDuplicateEmail
+	}
+	if r.findIdentity(identity.Provider, identity.Subject) != nil {
+		return nil, errDuplicateIdentity
+	}
+
+	r.db.lastProfessorID++
+	now := time.Now()
+	created := &model.Professor{
+		ID:              r.db.lastProfessorID,
+		Nome:            professor.Nome,
+		Email:           professor.Email,
+		Idade:           professor.Idade,
+		Descricao:       professor.Descricao,
+		ValorHora:       professor.ValorHora,
+		DuracaoAula:     professor.DuracaoAula,
+		Password:        professor.Password,
+		EmailVerifiedAt: model.NullTime{NullTime: sqlTime(now)},
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	r.db.professores[created.ID] = created
+
+	linked := *identity
+	linked.ProfessorID = created.ID
+	if err := r.createIdentity(&linked); err != nil {
+		return nil, err
+	}
+	return public(created), nil
+}
+
+// UpdateTotpSecret stores a secret that is pending confirmation. It does
+// nothing when the professor already has TOTP enabled.
+func (r *ProfessorRepository) UpdateTotpSecret(id int64, secret string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if professor, ok := r.db.professores[id]; ok && !professor.TotpEnabledAt.Valid {
+		professor.TotpSecret = model.NullString{NullString: sql.NullString{String: secret, Valid: true}}
+		professor.TotpLastStep = 0
+	}
+	return nil
+}
+
+// EnableTotp turns on TOTP for the professor and replaces their recovery
+// codes by the ones in codeHashes.
+func (r *ProfessorRepository) EnableTotp(id int64, codeHashes []string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	professor, ok := r.db.professores[id]
+	if !ok {
+		return nil
+	}
+	professor.TotpEnabledAt = model.NullTime{NullTime: sqlTime(time.Now())}
+	codes := map[string]bool{}
+	for _, codeHash := range codeHashes {
+		codes[codeHash] = false
+	}
+	r.db.recoveryCodes[id] = codes
+	return nil
+}
+
+// DisableTotp turns off TOTP for the professor and drops their recovery
+// codes.
+func (r *ProfessorRepository) DisableTotp(id int64) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if professor, ok := r.db.professores[id]; ok {
+		professor.TotpSecret = model.NullString{}
+		professor.TotpEnabledAt = model.NullTime{}
+		professor.TotpLastStep = 0
+	}
+	delete(r.db.recoveryCodes, id)
+	return nil
+}
+
+// UseTotpStep records step as the last time step a code was accepted for. It
+// reports false when a code for step, or a later one, was already used.
+func (r *ProfessorRepository) UseTotpStep(id int64, step int64) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	professor, ok := r.db.professores[id]
+	if !ok || professor.TotpLastStep >= step {
+		return false, nil
+	}
+	professor.TotpLastStep = step
+	return true, nil
+}
+
+// UseRecoveryCode consumes the recovery code identified by codeHash. It
+// reports false when no unused code matches.
+func (r *ProfessorRepository) UseRecoveryCode(id int64, codeHash string) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	used, ok := r.db.recoveryCodes[id][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.db.recoveryCodes[id][codeHash] = true
+	return true, nil
+}
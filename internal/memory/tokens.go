@@ -0,0 +1,449 @@
+package memory
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+var errDuplicateAdministradorEmail = errors.New("memory: duplicate administrador email")
+
+type TokenRepository struct {
+	db *Database
+}
+
+func (r *TokenRepository) CreateSession(session *model.Session) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	created := *session
+	created.Current = false
+	created.RevokedAt = model.NullTime{}
+	created.CreatedAt = time.Now()
+	r.db.sessions[created.ID] = &created
+	return nil
+}
+
+func (r *TokenRepository) FindSession(id string) (*model.Session, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	session, ok := r.db.sessions[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	result := *session
+	return &result, nil
+}
+
+func (r *TokenRepository) ExistsActiveSession(id string) bool {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	session, ok := r.db.sessions[id]
+	return ok && !session.RevokedAt.Valid && session.ExpiresAt.After(time.Now())
+}
+
+// FindActiveSessions returns the sessions of the account that were neither
+// revoked nor expired, most recently used first.
+func (r *TokenRepository) FindActiveSessions(subject, role string) ([]*model.Session, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	sessions := make([]*model.Session, 0)
+	for _, session := range r.db.sessions {
+		if session.Subject == subject && session.Role == role && !session.RevokedAt.Valid && session.ExpiresAt.After(now) {
+			result := *session
+			sessions = append(sessions, &result)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+	return sessions, nil
+}
+
+func (r *TokenRepository) TouchSession(id string, client *model.SessionClient, expiresAt time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if session, ok := r.db.sessions[id]; ok {
+		session.IP = client.IP
+		session.UserAgent = client.UserAgent
+		session.ExpiresAt = expiresAt
+		session.LastUsedAt = time.Now()
+	}
+	return nil
+}
+
+// RevokeSession revokes the session and every refresh token of its family.
+func (r *TokenRepository) RevokeSession(id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := model.NullTime{NullTime: sqlTime(time.Now())}
+	if session, ok := r.db.sessions[id]; ok && !session.RevokedAt.Valid {
+		session.RevokedAt = now
+	}
+	for _, refreshToken := range r.db.refreshTokens {
+		if refreshToken.FamilyID == id && !refreshToken.RevokedAt.Valid {
+			refreshToken.RevokedAt = now
+		}
+	}
+	return nil
+}
+
+// RevokeOtherSessions revokes every session of the account except the one
+// identified by currentID, along with their refresh tokens.
+func (r *TokenRepository) RevokeOtherSessions(subject, role, currentID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := model.NullTime{NullTime: sqlTime(time.Now())}
+	for _, session := range r.db.sessions {
+		if session.Subject == subject && session.Role == role && session.ID != currentID && !session.RevokedAt.Valid {
+			session.RevokedAt = now
+		}
+	}
+	for _, refreshToken := range r.db.refreshTokens {
+		if refreshToken.Subject == subject && refreshToken.Role == role && refreshToken.FamilyID != currentID && !refreshToken.RevokedAt.Valid {
+			refreshToken.RevokedAt = now
+		}
+	}
+	return nil
+}
+
+func (r *TokenRepository) CreateRefreshToken(refreshToken *model.RefreshToken) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	created := *refreshToken
+	created.RotatedAt = model.NullTime{}
+	created.RevokedAt = model.NullTime{}
+	created.CreatedAt = time.Now()
+	r.db.refreshTokens[created.JTI] = &created
+	return nil
+}
+
+func (r *TokenRepository) FindRefreshTokenByJTI(jti string) (*model.RefreshToken, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	refreshToken, ok := r.db.refreshTokens[jti]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	result := *refreshToken
+	return &result, nil
+}
+
+// RotateRefreshToken marks the refresh token as exchanged for a new one. It
+// reports false when the token was already rotated or revoked.
+func (r *TokenRepository) RotateRefreshToken(jti string) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	refreshToken, ok := r.db.refreshTokens[jti]
+	if !ok || refreshToken.RotatedAt.Valid || refreshToken.RevokedAt.Valid {
+		return false, nil
+	}
+	refreshToken.RotatedAt = model.NullTime{NullTime: sqlTime(time.Now())}
+	return true, nil
+}
+
+func (r *TokenRepository) CreateInvalidatedToken(jti string, expiresAt time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.invalidatedTokens[jti]; !ok {
+		r.db.invalidatedTokens[jti] = expiresAt
+	}
+	return nil
+}
+
+func (r *TokenRepository) ExistsInvalidatedToken(jti string) bool {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	_, ok := r.db.invalidatedTokens[jti]
+	return ok
+}
+
+func (r *TokenRepository) CreateEmailVerification(verification *model.EmailVerification) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.lastVerificationID++
+	created := *verification
+	created.ID = r.db.lastVerificationID
+	created.UsedAt = model.NullTime{}
+	created.CreatedAt = time.Now()
+	r.db.emailVerifications[created.ID] = &created
+	return nil
+}
+
+func (r *TokenRepository) FindValidEmailVerification(tokenHash string) (*model.EmailVerification, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	for _, verification := range r.db.emailVerifications {
+		if verification.TokenHash == tokenHash && !verification.UsedAt.Valid && verification.ExpiresAt.After(now) {
+			result := *verification
+			return &result, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// ExistsRecentEmailVerification reports whether a verification was sent to
+// the professor in the last seconds.
+func (r *TokenRepository) ExistsRecentEmailVerification(professorID int64, seconds int64) bool {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	since := time.Now().Add(-time.Duration(seconds) * time.Second)
+	for _, verification := range r.db.emailVerifications {
+		if verification.ProfessorID == professorID && verification.CreatedAt.After(since) {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyProfessorEmail consumes verification and marks the email of its
+// professor as verified, invalidating any other pending verification. It
+// returns sql.ErrNoRows when verification was already used.
+func (r *TokenRepository) VerifyProfessorEmail(verification *model.EmailVerification) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	stored, ok := r.db.emailVerifications[verification.ID]
+	if !ok || stored.UsedAt.Valid {
+		return sql.ErrNoRows
+	}
+
+	now := model.NullTime{NullTime: sqlTime(time.Now())}
+	if professor, ok := r.db.professores[stored.ProfessorID]; ok && !professor.EmailVerifiedAt.Valid {
+		professor.EmailVerifiedAt = now
+	}
+	for _, pending := range r.db.emailVerifications {
+		if pending.ProfessorID == stored.ProfessorID && !pending.UsedAt.Valid {
+			pending.UsedAt = now
+		}
+	}
+	return nil
+}
+
+func (r *TokenRepository) CreateEstudanteEmailVerification(verification *model.EstudanteEmailVerification) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.lastEstudanteVerificationID++
+	created := *verification
+	created.ID = r.db.lastEstudanteVerificationID
+	created.UsedAt = model.NullTime{}
+	created.CreatedAt = time.Now()
+	r.db.estudanteVerifications[created.ID] = &created
+	return nil
+}
+
+func (r *TokenRepository) FindValidEstudanteEmailVerification(tokenHash string) (*model.EstudanteEmailVerification, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	for _, verification := range r.db.estudanteVerifications {
+		if verification.TokenHash == tokenHash && !verification.UsedAt.Valid && verification.ExpiresAt.After(now) {
+			result := *verification
+			return &result, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *TokenRepository) ExistsRecentEstudanteEmailVerification(estudanteID int64, seconds int64) bool {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	since := time.Now().Add(-time.Duration(seconds) * time.Second)
+	for _, verification := range r.db.estudanteVerifications {
+		if verification.EstudanteID == estudanteID && verification.CreatedAt.After(since) {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyEstudanteEmail works like VerifyProfessorEmail and also links every
+// booking made with the email to the student.
+func (r *TokenRepository) VerifyEstudanteEmail(verification *model.EstudanteEmailVerification) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	stored, ok := r.db.estudanteVerifications[verification.ID]
+	if !ok || stored.UsedAt.Valid {
+		return sql.ErrNoRows
+	}
+
+	now := model.NullTime{NullTime: sqlTime(time.Now())}
+	for _, pending := range r.db.estudanteVerifications {
+		if pending.EstudanteID == stored.EstudanteID && !pending.UsedAt.Valid {
+			pending.UsedAt = now
+		}
+	}
+	estudante, ok := r.db.estudantes[stored.EstudanteID]
+	if !ok {
+		return nil
+	}
+	if !estudante.EmailVerifiedAt.Valid {
+		estudante.EmailVerifiedAt = now
+	}
+	for _, aluno := range r.db.alunos {
+		if aluno.Email == estudante.Email && !aluno.EstudanteID.Valid {
+			aluno.EstudanteID = sql.NullInt64{Int64: estudante.ID, Valid: true}
+		}
+	}
+	return nil
+}
+
+func (r *TokenRepository) CreatePasswordReset(reset *model.PasswordReset) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.lastPasswordResetID++
+	created := *reset
+	created.ID = r.db.lastPasswordResetID
+	created.UsedAt = model.NullTime{}
+	created.CreatedAt = time.Now()
+	r.db.passwordResets[created.ID] = &created
+	return nil
+}
+
+// ExistsRecentPasswordReset reports whether a reset was sent to the professor
+// in the last seconds.
+func (r *TokenRepository) ExistsRecentPasswordReset(professorID int64, seconds int64) bool {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	since := time.Now().Add(-time.Duration(seconds) * time.Second)
+	for _, reset := range r.db.passwordResets {
+		if reset.ProfessorID == professorID && reset.CreatedAt.After(since) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *TokenRepository) FindValidPasswordReset(tokenHash string) (*model.PasswordReset, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	for _, reset := range r.db.passwordResets {
+		if reset.TokenHash == tokenHash && !reset.UsedAt.Valid && reset.ExpiresAt.After(now) {
+			result := *reset
+			return &result, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// ResetPassword consumes reset and every other pending reset of the
+// professor, stores passwordHash and revokes the tokens issued to them. It
+// returns sql.ErrNoRows when reset was already used.
+func (r *TokenRepository) ResetPassword(reset *model.PasswordReset, passwordHash string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	stored, ok := r.db.passwordResets[reset.ID]
+	if !ok || stored.UsedAt.Valid {
+		return sql.ErrNoRows
+	}
+
+	now := model.NullTime{NullTime: sqlTime(time.Now())}
+	for _, pending := range r.db.passwordResets {
+		if pending.ProfessorID == stored.ProfessorID && !pending.UsedAt.Valid {
+			pending.UsedAt = now
+		}
+	}
+	if professor, ok := r.db.professores[stored.ProfessorID]; ok {
+		professor.Password = passwordHash
+		professor.TokenVersion++
+	}
+	return nil
+}
+
+func (r *TokenRepository) CreateOidcState(state *model.OidcState) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.lastOidcStateID++
+	created := *state
+	created.ID = r.db.lastOidcStateID
+	created.UsedAt = model.NullTime{}
+	created.CreatedAt = time.Now()
+	r.db.oidcStates[created.ID] = &created
+	return nil
+}
+
+// UseOidcState marks the state as used. It returns sql.ErrNoRows when the
+// state is unknown, used or expired.
+func (r *TokenRepository) UseOidcState(stateHash string) (*model.OidcState, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	for _, state := range r.db.oidcStates {
+		if state.StateHash == stateHash && !state.UsedAt.Valid && state.ExpiresAt.After(now) {
+			state.UsedAt = model.NullTime{NullTime: sqlTime(now)}
+			result := *state
+			return &result, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *TokenRepository) CreateAdministrador(administrador *model.Administrador) (*model.Administrador, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, registered := range r.db.administradores {
+		if registered.Email == administrador.Email {
+			return nil, errDuplicateAdministradorEmail
+		}
+	}
+
+	r.db.lastAdministradorID++
+	now := time.Now()
+	created := &model.Administrador{
+		ID:        r.db.lastAdministradorID,
+		Nome:      administrador.Nome,
+		Email:     administrador.Email,
+		Password:  administrador.Password,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	r.db.administradores[created.ID] = created
+
+	result := *created
+	result.Password = ""
+	return &result, nil
+}
+
+func (r *TokenRepository) FindAdministradorByEmail(email string) (*model.Administrador, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, administrador := range r.db.administradores {
+		if administrador.Email == email {
+			result := *administrador
+			return &result, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type ApplicationError struct {
 	Message string
 }
@@ -55,6 +57,63 @@ func (e *AlunoNotFoundError) Error() string {
 	return e.Message
 }
 
+type DisponibilidadeNotFoundError struct {
+	Message string
+}
+
+func (e *DisponibilidadeNotFoundError) Error() string {
+	if e.Message == "" {
+		return "Disponibilidade not found"
+	}
+	return e.Message
+}
+
+type SessionNotFoundError struct {
+	Message string
+}
+
+func (e *SessionNotFoundError) Error() string {
+	if e.Message == "" {
+		return "Session not found"
+	}
+	return e.Message
+}
+
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	if e.Message == "" {
+		return "Conflict"
+	}
+	return e.Message
+}
+
+type TooManyRequestsError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *TooManyRequestsError) Error() string {
+	if e.Message == "" {
+		return "Too many requests"
+	}
+	return e.Message
+}
+
+type AccountLockedError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	if e.Message == "" {
+		return "Account is temporarily locked"
+	}
+	return e.Message
+}
+
 type ValidationError struct {
 	Message string
 	Errors  map[string][]string
@@ -95,6 +154,17 @@ func (e *BadCredentialsError) Error() string {
 	return e.Message
 }
 
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	if e.Message == "" {
+		return "Forbidden"
+	}
+	return e.Message
+}
+
 type JwtTokenError struct {
 	Message string
 }
@@ -105,3 +175,58 @@ func (e *JwtTokenError) Error() string {
 	}
 	return e.Message
 }
+
+type ApiKeyNotFoundError struct {
+	Message string
+}
+
+func (e *ApiKeyNotFoundError) Error() string {
+	if e.Message == "" {
+		return "Api key not found"
+	}
+	return e.Message
+}
+
+type DisciplinaNotFoundError struct {
+	Message string
+}
+
+func (e *DisciplinaNotFoundError) Error() string {
+	if e.Message == "" {
+		return "Disciplina not found"
+	}
+	return e.Message
+}
+
+type AvaliacaoNotFoundError struct {
+	Message string
+}
+
+func (e *AvaliacaoNotFoundError) Error() string {
+	if e.Message == "" {
+		return "Avaliacao not found"
+	}
+	return e.Message
+}
+
+type ArquivoNotFoundError struct {
+	Message string
+}
+
+func (e *ArquivoNotFoundError) Error() string {
+	if e.Message == "" {
+		return "Arquivo not found"
+	}
+	return e.Message
+}
+
+type OidcProviderNotFoundError struct {
+	Message string
+}
+
+func (e *OidcProviderNotFoundError) Error() string {
+	if e.Message == "" {
+		return "Oidc provider not found"
+	}
+	return e.Message
+}
@@ -6,25 +6,431 @@ import (
 	"time"
 )
 
+const (
+	RoleProfessor = "professor"
+	RoleStudent   = "student"
+	RoleAdmin     = "admin"
+	RoleApiKey    = "api_key"
+)
+
+// DefaultDuracaoAula is the lesson duration, in minutes, used when a professor
+// does not set one.
+const DefaultDuracaoAula int32 = 60
+
 type Professor struct {
-	ID         int64      `json:"id"`
-	Nome       string     `json:"nome"`
-	Email      string     `json:"email"`
-	Idade      int32      `json:"idade"`
-	Descricao  string     `json:"descricao"`
-	ValorHora  float64    `json:"valor_hora"`
-	FotoPerfil NullString `json:"foto_perfil"`
-	Password   string     `json:"-"`
-	CreatedAt  time.Time  `json:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at"`
+	ID              int64   `json:"id"`
+	Nome            string  `json:"nome"`
+	Email           string  `json:"email"`
+	Idade           int32   `json:"idade"`
+	Descricao       string  `json:"descricao"`
+	ValorHora       float64 `json:"valor_hora"`
+	DuracaoAula     int32   `json:"duracao_aula"`
+	AvaliacaoMedia  float64 `json:"avaliacao_media"`
+	AvaliacoesCount int32   `json:"avaliacoes_count"`
+	// FotoPerfil is where the photo is kept in the storage, see Fotos for
+	// its URLs.
+	FotoPerfil      NullString `json:"-"`
+	Password        string     `json:"-"`
+	TokenVersion    int32      `json:"-"`
+	TotpSecret      NullString `json:"-"`
+	TotpEnabledAt   NullTime   `json:"-"`
+	TotpLastStep    int64      `json:"-"`
+	SuspendedAt     NullTime   `json:"suspended_at"`
+	EmailVerifiedAt NullTime   `json:"email_verified_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	// Disciplinas lists the subjects taught by the professor, each along with
+	// its path in the catalogue.
+	Disciplinas []*Disciplina `json:"disciplinas"`
+	// Fotos lists the variants of the profile photo, empty when the
+	// professor has none.
+	Fotos []*FotoVariante `json:"fotos"`
+	// Highlights holds, for each field matching the search query, the
+	// matching fragment with the matched words wrapped in <mark> tags.
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+const (
+	ProfessorSortOldest    = "oldest"
+	ProfessorSortNewest    = "newest"
+	ProfessorSortPriceAsc  = "price_asc"
+	ProfessorSortPriceDesc = "price_desc"
+	ProfessorSortRelevance = "relevance"
+	ProfessorSortRating    = "rating"
+)
+
+// ProfessorFilter narrows down the professor search. Zero values leave the
+// matching filter out. When Q is set, only the professores in IDs, found by
+// the searcher for Q, match. When Subject is set, only the professores
+// teaching one of DisciplinaIDs, the subject and its descendants, match.
+type ProfessorFilter struct {
+	Q             string
+	IDs           []int64
+	Subject       int64
+	DisciplinaIDs []int64
+	Nome          string
+	ValorHoraMin  float64
+	ValorHoraMax  float64
+	IdadeMin      int32
+	IdadeMax      int32
+	Sort          string
+	Page          int32
+	Limit         int32
 }
 
+// Offset returns how many rows come before the requested page.
+func (f *ProfessorFilter) Offset() int32 {
+	return (f.Page - 1) * f.Limit
+}
+
+type Pagination struct {
+	Page       int32 `json:"page"`
+	Limit      int32 `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int32 `json:"total_pages"`
+}
+
+func NewPagination(page, limit int32, total int64) Pagination {
+	return Pagination{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: int32((total + int64(limit) - 1) / int64(limit)),
+	}
+}
+
+// Page is a page of a listing along with where it stands in the whole
+// result.
+type Page[T any] struct {
+	Data       []T        `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// FotoTamanhos are the sizes, in pixels, of the square variants generated for
+// each profile photo.
+var FotoTamanhos = []int{64, 256, 1024}
+
+// FotoMaxPixels bounds the dimensions of the uploaded photos, which are fully
+// decoded in memory.
+const FotoMaxPixels = 25_000_000
+
+// FotoVariante is a profile photo in one of the sizes and formats it is
+// served in. Photos uploaded before the variants were introduced only have
+// their original file, with no size.
+type FotoVariante struct {
+	Tamanho int    `json:"tamanho"`
+	Formato string `json:"formato"`
+	URL     string `json:"url"`
+}
+
+// Disciplina is a subject of the catalogue. Subjects form a tree, e.g.
+// Exatas > Matemática > Cálculo.
+type Disciplina struct {
+	ID       int64     `json:"id"`
+	ParentID NullInt64 `json:"parent_id"`
+	Nome     string    `json:"nome"`
+	// Caminho holds the names from the root of the catalogue down to the
+	// subject, it is only filled for the subjects of a professor.
+	Caminho []string `json:"caminho,omitempty"`
+	// Subdisciplinas is only filled when the whole catalogue is listed.
+	Subdisciplinas []*Disciplina `json:"subdisciplinas,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+const (
+	AulaStatusScheduled   = "scheduled"
+	AulaStatusCancelled   = "cancelled"
+	AulaStatusRescheduled = "rescheduled"
+	AulaStatusCompleted   = "completed"
+)
+
 type Aluno struct {
+	ID          int64         `json:"id"`
+	ProfessorID int64         `json:"-"`
+	EstudanteID sql.NullInt64 `json:"-"`
+	Nome        string        `json:"nome"`
+	Email       string        `json:"email"`
+	DataAula    time.Time     `json:"data_aula"`
+	Duracao     int32         `json:"duracao"`
+	Status      string        `json:"status"`
+	Assinatura  string        `json:"assinatura,omitempty"`
+	Professor   *Professor    `json:"professor,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// FimAula returns the instant the lesson ends, based on its duration in
+// minutes.
+func (a *Aluno) FimAula() time.Time {
+	return a.DataAula.Add(time.Duration(a.Duracao) * time.Minute)
+}
+
+// IsAtiva reports whether the lesson still takes a slot in the professor's
+// calendar and can be cancelled or rescheduled.
+func (a *Aluno) IsAtiva() bool {
+	return a.Status == AulaStatusScheduled || a.Status == AulaStatusRescheduled
+}
+
+// Bounds of the grade of an Avaliacao.
+const (
+	AvaliacaoNotaMin = 1
+	AvaliacaoNotaMax = 5
+)
+
+// Avaliacao is the review a student leaves about a completed lesson. The
+// professor can publicly answer it once.
+type Avaliacao struct {
+	ID           int64      `json:"id"`
+	AlunoID      int64      `json:"aluno_id"`
+	ProfessorID  int64      `json:"-"`
+	Autor        string     `json:"autor"`
+	Nota         int32      `json:"nota"`
+	Comentario   string     `json:"comentario"`
+	Resposta     NullString `json:"resposta"`
+	RespondidaEm NullTime   `json:"respondida_em"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+type Estudante struct {
+	ID              int64     `json:"id"`
+	Nome            string    `json:"nome"`
+	Email           string    `json:"email"`
+	Password        string    `json:"-"`
+	EmailVerifiedAt NullTime  `json:"email_verified_at"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type Administrador struct {
+	ID        int64     `json:"id"`
+	Nome      string    `json:"nome"`
+	Email     string    `json:"email"`
+	Password  string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Principal is the authenticated caller of a request. Only the account that
+// matches Role is loaded.
+type Principal struct {
+	Subject        string
+	Role           string
+	TokenID        string
+	TokenExpiresAt time.Time
+	SessionID      string
+	Professor      *Professor
+	Estudante      *Estudante
+	Administrador  *Administrador
+	ApiKey         *ApiKey
+}
+
+type PasswordReset struct {
+	ID          int64
+	ProfessorID int64
+	TokenHash   string
+	ExpiresAt   time.Time
+	UsedAt      NullTime
+	CreatedAt   time.Time
+}
+
+type EmailVerification struct {
+	ID          int64
+	ProfessorID int64
+	TokenHash   string
+	ExpiresAt   time.Time
+	UsedAt      NullTime
+	CreatedAt   time.Time
+}
+
+type EstudanteEmailVerification struct {
+	ID          int64
+	EstudanteID int64
+	TokenHash   string
+	ExpiresAt   time.Time
+	UsedAt      NullTime
+	CreatedAt   time.Time
+}
+
+const (
+	OidcProviderGoogle    = "google"
+	OidcProviderMicrosoft = "microsoft"
+)
+
+// OidcState remembers an OIDC login started by the API until the provider
+// redirects the user back with the authorization code.
+type OidcState struct {
+	ID           int64
+	Provider     string
+	StateHash    string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+	UsedAt       NullTime
+	CreatedAt    time.Time
+}
+
+// ProfessorIdentity links a professor to the account Subject of an OIDC
+// provider.
+type ProfessorIdentity struct {
+	ID          int64
+	ProfessorID int64
+	Provider    string
+	Subject     string
+	Email       string
+	CreatedAt   time.Time
+}
+
+// Session is a login of an account on a device. It lasts as long as the
+// refresh token family started by the login, whose id it shares.
+type Session struct {
+	ID         string    `json:"id"`
+	Subject    string    `json:"-"`
+	Role       string    `json:"-"`
+	Device     string    `json:"device"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	Current    bool      `json:"current"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	RevokedAt  NullTime  `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// SessionClient describes the client a session is being used from.
+type SessionClient struct {
+	Device    string
+	IP        string
+	UserAgent string
+}
+
+// Scopes an API key can be granted.
+const (
+	ApiKeyScopeProfessoresRead = "professores:read"
+	ApiKeyScopeAulasWrite      = "aulas:write"
+)
+
+var ApiKeyScopes = []string{ApiKeyScopeProfessoresRead, ApiKeyScopeAulasWrite}
+
+// ApiKey lets a partner backend call the API on its own behalf. Only the hash
+// of the key is stored; Prefix is kept so it can be recognized.
+type ApiKey struct {
+	ID              int64         `json:"id"`
+	AdministradorID sql.NullInt64 `json:"-"`
+	Nome            string        `json:"nome"`
+	Prefix          string        `json:"prefix"`
+	KeyHash         string        `json:"-"`
+	Scopes          []string      `json:"scopes"`
+	LastUsedAt      NullTime      `json:"last_used_at"`
+	LastUsedIP      NullString    `json:"last_used_ip"`
+	RevokedAt       NullTime      `json:"revoked_at"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+}
+
+// HasScope reports whether the key was granted scope.
+func (k *ApiKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	LoginScopeAccount  = "account"
+	LoginScopeIP       = "ip"
+	LoginScopeMfaToken = "mfa_token"
+)
+
+// LoginAttemptKey identifies what failed logins are counted against: an
+// account of Role, identified by its email, or an IP address.
+type LoginAttemptKey struct {
+	Scope      string `json:"scope"`
+	Role       string `json:"role,omitempty"`
+	Identifier string `json:"identifier"`
+}
+
+// LoginAttempt counts the consecutive failed logins of an account or IP
+// address.
+type LoginAttempt struct {
+	LoginAttemptKey
+	Failures      int32     `json:"failures"`
+	LockedUntil   NullTime  `json:"locked_until"`
+	LastFailureAt time.Time `json:"last_failure_at"`
+}
+
+// IsLocked reports whether logins are refused at now.
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return a.LockedUntil.Valid && a.LockedUntil.Time.After(now)
+}
+
+// JWK is a public key in the JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// RefreshToken tracks an issued refresh token. Every token obtained by
+// rotating another one belongs to the same family as its parent.
+type RefreshToken struct {
+	JTI       string
+	FamilyID  string
+	ParentJTI NullString
+	Subject   string
+	Role      string
+	ExpiresAt time.Time
+	RotatedAt NullTime
+	RevokedAt NullTime
+	CreatedAt time.Time
+}
+
+const (
+	HistoricoAutorProfessor = "professor"
+	HistoricoAutorAluno     = "aluno"
+)
+
+type AlunoHistorico struct {
+	ID               int64     `json:"id"`
+	AlunoID          int64     `json:"-"`
+	StatusAnterior   string    `json:"status_anterior"`
+	StatusNovo       string    `json:"status_novo"`
+	DataAulaAnterior time.Time `json:"data_aula_anterior"`
+	DataAulaNova     time.Time `json:"data_aula_nova"`
+	Autor            string    `json:"autor"`
+	Motivo           string    `json:"motivo"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type Disponibilidade struct {
+	ID          int64     `json:"id"`
+	ProfessorID int64     `json:"-"`
+	DiaSemana   int32     `json:"dia_semana"`
+	HoraInicio  string    `json:"hora_inicio"`
+	HoraFim     string    `json:"hora_fim"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type DisponibilidadeExcecao struct {
 	ID          int64     `json:"id"`
 	ProfessorID int64     `json:"-"`
-	Nome        string    `json:"nome"`
-	Email       string    `json:"email"`
-	DataAula    time.Time `json:"data_aula"`
+	Inicio      time.Time `json:"inicio"`
+	Fim         time.Time `json:"fim"`
+	Disponivel  bool      `json:"disponivel"`
+	Motivo      string    `json:"motivo"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -39,3 +445,25 @@ func (ns *NullString) MarshalJSON() ([]byte, error) {
 	}
 	return json.Marshal(nil)
 }
+
+type NullInt64 struct {
+	sql.NullInt64
+}
+
+func (ni *NullInt64) MarshalJSON() ([]byte, error) {
+	if ni.Valid {
+		return json.Marshal(ni.Int64)
+	}
+	return json.Marshal(nil)
+}
+
+type NullTime struct {
+	sql.NullTime
+}
+
+func (nt *NullTime) MarshalJSON() ([]byte, error) {
+	if nt.Valid {
+		return json.Marshal(nt.Time)
+	}
+	return json.Marshal(nil)
+}
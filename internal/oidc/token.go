@@ -0,0 +1,99 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// tenantPlaceholder stands for the tenant of the user in the issuer of
+// multi-tenant providers, such as the common endpoint of Microsoft.
+const tenantPlaceholder = "{tenantid}"
+
+// IDToken holds the claims of a verified ID token the API relies on.
+type IDToken struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// VerifyIDToken checks the signature of raw against the keys of the provider,
+// that it was issued by the provider for this client and that it carries
+// nonce.
+func (p *Provider) VerifyIDToken(raw, nonce string) (*IDToken, error) {
+	m, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		default:
+			return nil, errors.New("unexpected signing method")
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.keys.get(kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := m.Issuer
+	if tenant, _ := claims["tid"].(string); tenant != "" {
+		issuer = strings.Replace(issuer, tenantPlaceholder, tenant, 1)
+	}
+	if !claims.VerifyIssuer(issuer, true) {
+		return nil, errors.New("token was issued by another provider")
+	}
+	if !claims.VerifyAudience(p.ClientID, true) {
+		return nil, errors.New("token was issued to another client")
+	}
+	tokenNonce, _ := claims["nonce"].(string)
+	if subtle.ConstantTimeCompare([]byte(tokenNonce), []byte(nonce)) != 1 {
+		return nil, errors.New("token nonce does not match")
+	}
+
+	idToken := &IDToken{}
+	idToken.Subject, _ = claims["sub"].(string)
+	idToken.Email, _ = claims["email"].(string)
+	idToken.Name, _ = claims["name"].(string)
+	idToken.EmailVerified = isTrue(claims[p.EmailVerifiedClaim])
+	if idToken.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+	return idToken, nil
+}
+
+// isTrue accepts the booleans some providers send as strings.
+func isTrue(v interface{}) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case string:
+		return b == "true"
+	}
+	return false
+}
+
+// NewCodeVerifier returns a random PKCE code verifier.
+func NewCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge derives the S256 PKCE challenge of verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy is a token bucket: it allows bursts of up to Limit requests and
+// refills completely over Period.
+type Policy struct {
+	Name   string
+	Limit  int64
+	Period time.Duration
+}
+
+// ParsePolicy reads a policy written as "<limit>/<seconds>", e.g. "10/60" for
+// ten requests per minute. fallback is returned when s is empty.
+func ParsePolicy(name, s string, fallback Policy) (Policy, error) {
+	fallback.Name = name
+	if s == "" {
+		return fallback, nil
+	}
+
+	limit, seconds, ok := strings.Cut(s, "/")
+	l, err := strconv.ParseInt(limit, 10, 64)
+	if !ok || err != nil || l <= 0 {
+		return fallback, fmt.Errorf("invalid rate limit %q for %s", s, name)
+	}
+	p, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil || p <= 0 {
+		return fallback, fmt.Errorf("invalid rate limit %q for %s", s, name)
+	}
+
+	return Policy{Name: name, Limit: l, Period: time.Duration(p) * time.Second}, nil
+}
+
+// rate returns how many tokens are added back per millisecond.
+func (p Policy) rate() float64 {
+	return float64(p.Limit) / float64(p.Period.Milliseconds())
+}
+
+// Result is the state of a bucket after a request was counted against it.
+type Result struct {
+	Allowed   bool
+	Limit     int64
+	Remaining int64
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next request is allowed, only set
+	// when this one was not.
+	RetryAfter time.Duration
+}
+
+func newResult(policy Policy, tokens float64, allowed bool) *Result {
+	rate := policy.rate()
+	result := &Result{
+		Allowed:   allowed,
+		Limit:     policy.Limit,
+		Remaining: int64(math.Floor(tokens)),
+		Reset:     time.Duration((float64(policy.Limit)-tokens)/rate) * time.Millisecond,
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration(math.Ceil((1-tokens)/rate)) * time.Millisecond
+	}
+	return result
+}
+
+// Store keeps the buckets of every client.
+type Store interface {
+	// Take counts a request against the bucket of key under policy.
+	Take(key string, policy Policy, now time.Time) (*Result, error)
+}
+
+var store Store = NewMemoryStore()
+
+// Init sets the store used by Take.
+func Init(s Store) {
+	store = s
+}
+
+func Take(key string, policy Policy) (*Result, error) {
+	return store.Take(policy.Name+":"+key, policy, time.Now())
+}
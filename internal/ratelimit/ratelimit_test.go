@@ -0,0 +1,391 @@
+package ratelimit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testPolicy allows bursts of 2 requests and adds a token back every second.
+var testPolicy = Policy{Name: "test", Limit: 2, Period: 2 * time.Second}
+
+func TestParsePolicy(t *testing.T) {
+	fallback := Policy{Limit: 5, Period: time.Minute}
+
+	policy, err := ParsePolicy("login", "10/60", fallback)
+	if err != nil {
+		t.Fatalf("ParsePolicy() error = %v", err)
+	}
+	if policy != (Policy{Name: "login", Limit: 10, Period: time.Minute}) {
+		t.Errorf("ParsePolicy() = %+v", policy)
+	}
+
+	policy, err = ParsePolicy("login", "", fallback)
+	if err != nil || policy != (Policy{Name: "login", Limit: 5, Period: time.Minute}) {
+		t.Errorf("ParsePolicy(\"\") = %+v, %v", policy, err)
+	}
+
+	for _, s := range []string{"10", "0/60", "10/0", "-1/60", "a/60", "10/b"} {
+		if _, err := ParsePolicy("login", s, fallback); err == nil {
+			t.Errorf("ParsePolicy(%q) error = nil", s)
+		}
+	}
+}
+
+func TestNewResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		tokens  float64
+		allowed bool
+		want    Result
+	}{
+		{
+			name:    "full",
+			tokens:  2,
+			allowed: true,
+			want:    Result{Allowed: true, Limit: 2, Remaining: 2},
+		},
+		{
+			name:    "allowed",
+			tokens:  1.5,
+			allowed: true,
+			want:    Result{Allowed: true, Limit: 2, Remaining: 1, Reset: 500 * time.Millisecond},
+		},
+		{
+			name:    "empty",
+			tokens:  0,
+			allowed: false,
+			want:    Result{Limit: 2, Reset: 2 * time.Second, RetryAfter: time.Second},
+		},
+		{
+			name:    "partly refilled",
+			tokens:  0.25,
+			allowed: false,
+			want:    Result{Limit: 2, Reset: 1750 * time.Millisecond, RetryAfter: 750 * time.Millisecond},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := newResult(testPolicy, tt.tokens, tt.allowed)
+			if *result != tt.want {
+				t.Errorf("newResult() = %+v, want %+v", *result, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	s := NewMemoryStore().(*memoryStore)
+	start := time.Unix(1700000000, 0)
+
+	s.Take("a", testPolicy, start)
+	s.Take("b", testPolicy, start.Add(sweepInterval-time.Second))
+	s.Take("b", testPolicy, start.Add(sweepInterval-time.Second))
+
+	// a is full again by the next sweep, b only a second after it
+	s.Take("c", testPolicy, start.Add(sweepInterval))
+	if _, ok := s.buckets["a"]; ok {
+		t.Error("full bucket was not swept")
+	}
+	if _, ok := s.buckets["b"]; !ok {
+		t.Error("bucket still refilling was swept")
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	testStore(t, NewRedisStore(server.addr, "secret", 3))
+
+	commands := server.commands()
+	if len(commands) < 3 || commands[0] != "AUTH" || commands[1] != "SELECT 3" {
+		t.Fatalf("commands = %q", commands)
+	}
+	for _, command := range commands[2:] {
+		if command != "EVAL" {
+			t.Fatalf("commands = %q", commands)
+		}
+	}
+	if server.dials() != 1 {
+		t.Errorf("dials = %d, want the pooled connection to be reused", server.dials())
+	}
+	if ttl := server.ttl("ratelimit:test:alice"); ttl <= 0 || ttl > testPolicy.Period+time.Millisecond {
+		t.Errorf("ttl = %v", ttl)
+	}
+}
+
+func TestRedisStoreErrors(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	t.Run("wrong password", func(t *testing.T) {
+		server := newFakeRedis(t, "secret")
+		_, err := NewRedisStore(server.addr, "wrong", 0).Take("alice", testPolicy, now)
+		if err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+			t.Errorf("Take() error = %v", err)
+		}
+	})
+
+	t.Run("error reply", func(t *testing.T) {
+		server := newFakeRedis(t, "")
+		server.fail("ERR script failed")
+		s := NewRedisStore(server.addr, "", 0)
+		if _, err := s.Take("alice", testPolicy, now); err == nil || err.Error() != "ERR script failed" {
+			t.Errorf("Take() error = %v", err)
+		}
+
+		// The failed connection is not pooled
+		server.fail("")
+		if _, err := s.Take("alice", testPolicy, now); err != nil {
+			t.Fatalf("Take() error = %v", err)
+		}
+		if server.dials() != 2 {
+			t.Errorf("dials = %d, want 2", server.dials())
+		}
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		server := newFakeRedis(t, "")
+		server.listener.Close()
+		if _, err := NewRedisStore(server.addr, "", 0).Take("alice", testPolicy, now); err == nil {
+			t.Error("Take() error = nil")
+		}
+	})
+}
+
+// testStore checks the token bucket of s under testPolicy.
+func testStore(t *testing.T, s Store) {
+	t.Helper()
+
+	start := time.Unix(1700000000, 0)
+	take := func(key string, elapsed time.Duration) *Result {
+		t.Helper()
+		result, err := s.Take("test:"+key, testPolicy, start.Add(elapsed))
+		if err != nil {
+			t.Fatalf("Take() error = %v", err)
+		}
+		return result
+	}
+
+	steps := []struct {
+		elapsed time.Duration
+		want    Result
+	}{
+		{0, Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}},
+		{0, Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}},
+		{0, Result{Limit: 2, Reset: 2 * time.Second, RetryAfter: time.Second}},
+		{250 * time.Millisecond, Result{Limit: 2, Reset: 1750 * time.Millisecond, RetryAfter: 750 * time.Millisecond}},
+		{time.Second, Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}},
+		{3500 * time.Millisecond, Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}},
+	}
+	for i, step := range steps {
+		if result := take("alice", step.elapsed); *result != step.want {
+			t.Errorf("step %d: Take() = %+v, want %+v", i, *result, step.want)
+		}
+	}
+
+	// Every key has a bucket of its own
+	if result := take("bob", time.Second); !result.Allowed || result.Remaining != 1 {
+		t.Errorf("Take(bob) = %+v", *result)
+	}
+
+	// A clock going backwards adds no tokens
+	take("carol", time.Second)
+	take("carol", time.Second)
+	if result := take("carol", 0); result.Allowed {
+		t.Errorf("Take(carol) = %+v", *result)
+	}
+}
+
+// fakeRedis is an in-process server speaking RESP that runs takeScript
+// natively, as the store sends no other script.
+type fakeRedis struct {
+	addr     string
+	password string
+	listener net.Listener
+
+	mu       sync.Mutex
+	log      []string
+	dialed   int
+	failWith string
+	hashes   map[string]map[string]string
+	ttls     map[string]time.Duration
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	f := &fakeRedis{
+		addr:     listener.Addr().String(),
+		password: password,
+		listener: listener,
+		hashes:   map[string]map[string]string{},
+		ttls:     map[string]time.Duration{},
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			f.mu.Lock()
+			f.dialed++
+			f.mu.Unlock()
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	authenticated := f.password == ""
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		f.mu.Lock()
+		reply := f.handle(args, &authenticated)
+		f.mu.Unlock()
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeRedis) handle(args []string, authenticated *bool) string {
+	command := strings.ToUpper(args[0])
+	switch command {
+	case "AUTH", "EVAL":
+		f.log = append(f.log, command)
+	default:
+		f.log = append(f.log, strings.Join(args, " "))
+	}
+
+	if f.failWith != "" {
+		return "-" + f.failWith + "\r\n"
+	}
+
+	switch {
+	case command == "AUTH":
+		if len(args) != 2 || args[1] != f.password {
+			return "-WRONGPASS invalid username-password pair\r\n"
+		}
+		*authenticated = true
+		return "+OK\r\n"
+	case !*authenticated:
+		return "-NOAUTH Authentication required.\r\n"
+	case command == "SELECT":
+		return "+OK\r\n"
+	case command == "EVAL" && len(args) == 7 && args[1] == takeScript && args[2] == "1":
+		allowed, tokens := f.take(args[3], args[4], args[5], args[6])
+		return fmt.Sprintf("*2\r\n:%d\r\n$%d\r\n%s\r\n", allowed, len(tokens), tokens)
+	}
+	return "-ERR unknown command '" + args[0] + "'\r\n"
+}
+
+// take mirrors takeScript.
+func (f *fakeRedis) take(key, limitArg, rateArg, nowArg string) (int, string) {
+	limit, _ := strconv.ParseFloat(limitArg, 64)
+	rate, _ := strconv.ParseFloat(rateArg, 64)
+	now, _ := strconv.ParseFloat(nowArg, 64)
+
+	tokens, ts := limit, now
+	if hash, ok := f.hashes[key]; ok {
+		tokens, _ = strconv.ParseFloat(hash["tokens"], 64)
+		ts, _ = strconv.ParseFloat(hash["ts"], 64)
+	}
+	tokens = math.Min(limit, tokens+math.Max(0, now-ts)*rate)
+	allowed := 0
+	if tokens >= 1 {
+		tokens--
+		allowed = 1
+	}
+
+	remaining := strconv.FormatFloat(tokens, 'g', -1, 64)
+	f.hashes[key] = map[string]string{"tokens": remaining, "ts": nowArg}
+	f.ttls[key] = time.Duration(math.Ceil((limit-tokens)/rate)+1) * time.Millisecond
+	return allowed, remaining
+}
+
+// fail makes every following command fail with message, or succeed again
+// when it is empty.
+func (f *fakeRedis) fail(message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failWith = message
+}
+
+func (f *fakeRedis) commands() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.log...)
+}
+
+func (f *fakeRedis) dials() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.dialed
+}
+
+func (f *fakeRedis) ttl(key string) time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.ttls[key]
+}
+
+// readCommand reads a command sent as an array of bulk strings.
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("malformed command %q", line)
+	}
+	count, err := strconv.Atoi(line[1 : len(line)-2])
+	if err != nil || count < 1 {
+		return nil, fmt.Errorf("malformed command %q", line)
+	}
+
+	args := make([]string, count)
+	for i := range args {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") || !strings.HasSuffix(line, "\r\n") {
+			return nil, fmt.Errorf("malformed argument %q", line)
+		}
+		size, err := strconv.Atoi(line[1 : len(line)-2])
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("malformed argument %q", line)
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
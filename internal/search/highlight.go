@@ -0,0 +1,107 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// fragmentSize is roughly how many characters of context a highlight keeps.
+const fragmentSize = 160
+
+const (
+	markOpen  = "<mark>"
+	markClose = "</mark>"
+)
+
+// Highlight returns the fragment of text around the first word matching
+// query, with every matching word wrapped in <mark> tags. Words match when
+// they start with a term of the query, ignoring case and accents. The text is
+// HTML escaped. It returns "" when nothing matches.
+func Highlight(text, query string) string {
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return ""
+	}
+
+	type span struct{ start, end int }
+	var matches []span
+	start := -1
+	for i, r := range text + " " {
+		if isSeparator(r) {
+			if start >= 0 {
+				if matchesAny(Normalize(text[start:i]), terms) {
+					matches = append(matches, span{start, i})
+				}
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+
+	from, to := fragmentBounds(text, matches[0].start)
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	last := from
+	for _, m := range matches {
+		if m.start < from || m.end > to {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[last:m.start]))
+		b.WriteString(markOpen)
+		b.WriteString(html.EscapeString(text[m.start:m.end]))
+		b.WriteString(markClose)
+		last = m.end
+	}
+	b.WriteString(html.EscapeString(text[last:to]))
+	if to < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+func matchesAny(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// fragmentBounds picks the byte range of text shown around the match at
+// offset, cutting on spaces so words are kept whole.
+func fragmentBounds(text string, offset int) (int, int) {
+	if utf8.RuneCountInString(text) <= fragmentSize {
+		return 0, len(text)
+	}
+
+	from := offset - fragmentSize/4
+	if from <= 0 {
+		from = 0
+	} else if space := strings.IndexByte(text[from:offset], ' '); space >= 0 {
+		from += space + 1
+	} else {
+		from = offset
+	}
+
+	to := from + fragmentSize
+	if to >= len(text) {
+		return from, len(text)
+	}
+	if space := strings.LastIndexByte(text[offset:to], ' '); space > 0 {
+		to = offset + space
+	}
+	for !utf8.RuneStart(text[to]) {
+		to--
+	}
+	return from, to
+}
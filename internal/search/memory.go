@@ -0,0 +1,165 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Weights of a term depending on the field it was found in.
+const (
+	nomeWeight        = 2.0
+	disciplinasWeight = 2.0
+	descricaoWeight   = 1.0
+)
+
+// prefixWeight scales the score of a term only matched by its prefix, so
+// "mat" finds "matematica" but ranks it after an exact match.
+const prefixWeight = 0.5
+
+// minPrefixLen is the shortest query term also matched as a prefix.
+const minPrefixLen = 3
+
+type memorySearcher struct {
+	mu sync.RWMutex
+	// postings maps a term to the weighted frequency of the term in each
+	// document containing it.
+	postings map[string]map[int64]float64
+	// terms lists the terms of each document, to unindex it.
+	terms map[int64][]string
+}
+
+// NewMemorySearcher returns a Searcher backed by an inverted index kept in
+// memory. It must be rebuilt from the database whenever the process starts
+// and only sees the changes made by its own process, so it does not suit
+// more than one instance of the API.
+func NewMemorySearcher() Searcher {
+	return &memorySearcher{
+		postings: map[string]map[int64]float64{},
+		terms:    map[int64][]string{},
+	}
+}
+
+func (s *memorySearcher) Index(doc *Document) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(doc.ID)
+	s.index(doc)
+	return nil
+}
+
+func (s *memorySearcher) Remove(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(id)
+	return nil
+}
+
+func (s *memorySearcher) Rebuild(docs []*Document) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.postings = map[string]map[int64]float64{}
+	s.terms = map[int64][]string{}
+	for _, doc := range docs {
+		s.index(doc)
+	}
+	return nil
+}
+
+func (s *memorySearcher) index(doc *Document) {
+	frequencies := map[string]float64{}
+	for _, term := range Tokenize(doc.Nome) {
+		frequencies[term] += nomeWeight
+	}
+	for _, term := range Tokenize(doc.Descricao) {
+		frequencies[term] += descricaoWeight
+	}
+	for _, disciplina := range doc.Disciplinas {
+		for _, term := range Tokenize(disciplina) {
+			frequencies[term] += disciplinasWeight
+		}
+	}
+
+	terms := make([]string, 0, len(frequencies))
+	for term, frequency := range frequencies {
+		if s.postings[term] == nil {
+			s.postings[term] = map[int64]float64{}
+		}
+		s.postings[term][doc.ID] = frequency
+		terms = append(terms, term)
+	}
+	s.terms[doc.ID] = terms
+}
+
+func (s *memorySearcher) remove(id int64) {
+	for _, term := range s.terms[id] {
+		delete(s.postings[term], id)
+		if len(s.postings[term]) == 0 {
+			delete(s.postings, term)
+		}
+	}
+	delete(s.terms, id)
+}
+
+// Search only returns the documents matching every term of query. Each term
+// adds to the score its frequency in the document, dampened logarithmically,
+// times its inverse document frequency, so rare terms weigh more.
+func (s *memorySearcher) Search(query string, limit int) ([]Hit, error) {
+	queryTerms := Tokenize(query)
+	if len(queryTerms) == 0 {
+		return nil, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total := float64(len(s.terms))
+	var scores map[int64]float64
+	for _, queryTerm := range queryTerms {
+		termScores := map[int64]float64{}
+		for term, postings := range s.postings {
+			weight := 1.0
+			if term != queryTerm {
+				if len(queryTerm) < minPrefixLen || !strings.HasPrefix(term, queryTerm) {
+					continue
+				}
+				weight = prefixWeight
+			}
+			idf := math.Log(1 + total/float64(len(postings)))
+			for id, frequency := range postings {
+				score := weight * (1 + math.Log(frequency)) * idf
+				if score > termScores[id] {
+					termScores[id] = score
+				}
+			}
+		}
+
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id, score := range scores {
+			if termScore, ok := termScores[id]; ok {
+				scores[id] = score + termScore
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
@@ -0,0 +1,61 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/cleysonph/hyperprof/internal/database"
+)
+
+type mysqlSearcher struct{}
+
+// NewMySQLSearcher returns a Searcher relying on the FULLTEXT index of the
+// professores table, which also covers the names of their subjects saved
+// along with them. MySQL keeps the index up to date itself, so Index, Remove
+// and Rebuild do nothing. Accents and case are ignored through the
+// utf8mb4_0900_ai_ci collation the searched columns are set to.
+func NewMySQLSearcher() Searcher {
+	return mysqlSearcher{}
+}
+
+func (mysqlSearcher) Index(doc *Document) error {
+	return nil
+}
+
+func (mysqlSearcher) Remove(id int64) error {
+	return nil
+}
+
+func (mysqlSearcher) Rebuild(docs []*Document) error {
+	return nil
+}
+
+func (mysqlSearcher) Search(query string, limit int) ([]Hit, error) {
+	against := booleanQuery(query)
+	if against == "" {
+		return nil, nil
+	}
+
+	ids, scores, err := database.SearchProfessores(against, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]Hit, len(ids))
+	for i := range ids {
+		hits[i] = Hit{ID: ids[i], Score: scores[i]}
+	}
+	return hits, nil
+}
+
+// booleanQuery requires every term of query, matching longer words starting
+// with it too. Tokenize already dropped the operators of the boolean mode.
+func booleanQuery(query string) string {
+	terms := Tokenize(query)
+	for i, term := range terms {
+		terms[i] = "+" + term
+		if len(term) >= minPrefixLen {
+			terms[i] += "*"
+		}
+	}
+	return strings.Join(terms, " ")
+}
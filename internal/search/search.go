@@ -0,0 +1,52 @@
+package search
+
+// Document is what the searcher knows about a professor.
+type Document struct {
+	ID        int64
+	Nome      string
+	Descricao string
+	// Disciplinas holds the names of the subjects taught by the professor
+	// and of their ancestors in the catalogue.
+	Disciplinas []string
+}
+
+// Hit is a document matching a query, with a higher Score for a better match.
+type Hit struct {
+	ID    int64
+	Score float64
+}
+
+// Searcher finds the professores matching a free text query.
+type Searcher interface {
+	// Index adds doc to the searcher, replacing any previous version.
+	Index(doc *Document) error
+	// Remove forgets the document with id.
+	Remove(id int64) error
+	// Rebuild replaces every document known by the searcher with docs.
+	Rebuild(docs []*Document) error
+	// Search returns up to limit hits for query, best match first.
+	Search(query string, limit int) ([]Hit, error)
+}
+
+var searcher Searcher = NewMemorySearcher()
+
+// Init sets the searcher used by the package functions.
+func Init(s Searcher) {
+	searcher = s
+}
+
+func Index(doc *Document) error {
+	return searcher.Index(doc)
+}
+
+func Remove(id int64) error {
+	return searcher.Remove(id)
+}
+
+func Rebuild(docs []*Document) error {
+	return searcher.Rebuild(docs)
+}
+
+func Search(query string, limit int) ([]Hit, error) {
+	return searcher.Search(query, limit)
+}
@@ -0,0 +1,52 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// foldings maps the accented letters used in Portuguese, and a few others, to
+// their plain form so "matemática" and "matematica" match.
+var foldings = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ç': 'c', 'ñ': 'n', 'ý': 'y', 'ÿ': 'y',
+}
+
+// stopwords are too common in Portuguese to tell profiles apart.
+var stopwords = map[string]bool{
+	"a": true, "o": true, "as": true, "os": true, "e": true, "de": true,
+	"da": true, "do": true, "das": true, "dos": true, "em": true, "no": true,
+	"na": true, "nos": true, "nas": true, "um": true, "uma": true, "com": true,
+	"para": true, "por": true, "que": true, "se": true, "ao": true, "aos": true,
+}
+
+// Normalize lower cases s and removes its diacritics.
+func Normalize(s string) string {
+	return strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		if folded, ok := foldings[r]; ok {
+			return folded
+		}
+		return r
+	}, s)
+}
+
+// Tokenize splits s into normalized terms, leaving stopwords out.
+func Tokenize(s string) []string {
+	words := strings.FieldsFunc(Normalize(s), isSeparator)
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if !stopwords[word] {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/validator"
+)
+
+func (s *Service) CreateAdministrador(administrador *model.Administrador, passwordConfirmation string) (*model.Administrador, error) {
+	if err := validator.ValidateAdministrador(administrador, passwordConfirmation); err != nil {
+		return nil, err
+	}
+
+	hash, err := hashPassword(administrador.Password)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	administrador.Password = hash
+	administrador, err = s.tokens.CreateAdministrador(administrador)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return administrador, nil
+}
+
+func (s *Service) LoginAdministrador(email, password string, client *model.SessionClient) ([]string, error) {
+	if err := validator.ValidateLogin(email, password); err != nil {
+		return nil, err
+	}
+
+	if err := checkLoginAllowed(model.RoleAdmin, email, client); err != nil {
+		return nil, err
+	}
+
+	administrador, err := s.tokens.FindAdministradorByEmail(email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, badCredentials(model.RoleAdmin, email, client)
+		}
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	match := checkPasswordHash(password, administrador.Password)
+	if !match {
+		return nil, badCredentials(model.RoleAdmin, email, client)
+	}
+	loginSucceeded(model.RoleAdmin, email)
+
+	tokens, err := s.generateTokens(administrador.Email, model.RoleAdmin, 0, client)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return tokens, nil
+}
+
+func (s *Service) FindAllProfessoresByAdminPrincipal(principal *model.Principal) ([]*model.Professor, error) {
+	if err := checkAdminPrincipal(principal); err != nil {
+		return nil, err
+	}
+
+	professores, err := s.professores.FindAllForAdmin()
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	if err := s.loadProfessorDetails(professores...); err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return professores, nil
+}
+
+func (s *Service) SuspendProfessorByAdminPrincipal(principal *model.Principal, professorID int64) (*model.Professor, error) {
+	if err := checkAdminPrincipal(principal); err != nil {
+		return nil, err
+	}
+	if _, err := s.findAnyProfessorByID(professorID); err != nil {
+		return nil, err
+	}
+
+	if err := s.professores.Suspend(professorID); err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	professor, err := s.findAnyProfessorByID(professorID)
+	if err != nil {
+		return nil, err
+	}
+	indexProfessor(professor)
+	return professor, nil
+}
+
+func (s *Service) ReactivateProfessorByAdminPrincipal(principal *model.Principal, professorID int64) (*model.Professor, error) {
+	if err := checkAdminPrincipal(principal); err != nil {
+		return nil, err
+	}
+	if _, err := s.findAnyProfessorByID(professorID); err != nil {
+		return nil, err
+	}
+
+	if err := s.professores.Reactivate(professorID); err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	professor, err := s.findAnyProfessorByID(professorID)
+	if err != nil {
+		return nil, err
+	}
+	indexProfessor(professor)
+	return professor, nil
+}
+
+func (s *Service) DeleteProfessorByAdminPrincipal(principal *model.Principal, professorID int64) error {
+	if err := checkAdminPrincipal(principal); err != nil {
+		return err
+	}
+	professor, err := s.findAnyProfessorByID(professorID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.professores.Delete(professorID); err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	unindexProfessor(professorID)
+	deleteFoto(professor.FotoPerfil.String)
+
+	return nil
+}
+
+// findAnyProfessorByID looks up a professor regardless of the account state.
+func (s *Service) findAnyProfessorByID(professorID int64) (*model.Professor, error) {
+	professor, err := s.professores.FindByID(professorID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &model.ProfessorNotFoundError{
+				Message: fmt.Sprintf("Professor with ID %d not found", professorID),
+			}
+		}
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	if err := s.loadProfessorDetails(professor); err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	return professor, nil
+}
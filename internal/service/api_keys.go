@@ -0,0 +1,115 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/database"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/validator"
+	"github.com/rs/zerolog/log"
+)
+
+// apiKeyPrefix marks the keys issued by the API, so leaked keys are easy to
+// recognize.
+const apiKeyPrefix = "hp_"
+
+// apiKeyTouchInterval is how often the last use of a key is written.
+const apiKeyTouchInterval = time.Minute
+
+// CreateApiKeyByAdminPrincipal issues a new key. The key itself is only
+// returned here, just its hash is stored.
+func CreateApiKeyByAdminPrincipal(principal *model.Principal, apiKey *model.ApiKey) (*model.ApiKey, string, error) {
+	if err := checkAdminPrincipal(principal); err != nil {
+		return nil, "", err
+	}
+
+	if err := validator.ValidateApiKey(apiKey); err != nil {
+		return nil, "", err
+	}
+
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return nil, "", &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	key := apiKeyPrefix + token
+
+	apiKey.AdministradorID.Int64 = principal.Administrador.ID
+	apiKey.AdministradorID.Valid = true
+	apiKey.Prefix = key[:len(apiKeyPrefix)+8]
+	apiKey.KeyHash = hashOpaqueToken(key)
+	apiKey, err = database.CreateApiKey(apiKey)
+	if err != nil {
+		return nil, "", &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return apiKey, key, nil
+}
+
+func FindAllApiKeysByAdminPrincipal(principal *model.Principal) ([]*model.ApiKey, error) {
+	if err := checkAdminPrincipal(principal); err != nil {
+		return nil, err
+	}
+
+	apiKeys, err := database.FindAllApiKeys()
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return apiKeys, nil
+}
+
+func RevokeApiKeyByAdminPrincipal(principal *model.Principal, apiKeyID int64) error {
+	if err := checkAdminPrincipal(principal); err != nil {
+		return err
+	}
+
+	revoked, err := database.RevokeApiKey(apiKeyID)
+	if err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	if !revoked {
+		return &model.ApiKeyNotFoundError{
+			Message: fmt.Sprintf("Api key with ID %d not found", apiKeyID),
+		}
+	}
+
+	return nil
+}
+
+// AuthenticateApiKey loads the key sent by a partner integration and records
+// that it was used from ip.
+func AuthenticateApiKey(key, ip string) (*model.Principal, error) {
+	apiKey, err := database.FindActiveApiKeyByKeyHash(hashOpaqueToken(key))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &model.BadCredentialsError{
+				Message: "Invalid api key",
+			}
+		}
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	now := time.Now()
+	if err := database.TouchApiKey(apiKey.ID, ip, now, now.Add(-apiKeyTouchInterval)); err != nil {
+		log.Error().Err(err).Int64("api_key_id", apiKey.ID).Msg("failed to record api key use")
+	}
+
+	return &model.Principal{
+		Subject: strconv.FormatInt(apiKey.ID, 10),
+		Role:    model.RoleApiKey,
+		ApiKey:  apiKey,
+	}, nil
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/cleysonph/hyperprof/config"
+	"github.com/cleysonph/hyperprof/internal/database"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/validator"
+)
+
+func (s *Service) CancelAlunoByPrincipal(principal *model.Principal, alunoID int64, motivo string) (*model.Aluno, error) {
+	aluno, err := s.findAlunoByPrincipal(principal, alunoID)
+	if err != nil {
+		return nil, err
+	}
+	return s.cancelAluno(aluno, model.HistoricoAutorProfessor, motivo)
+}
+
+func (s *Service) RescheduleAlunoByPrincipal(principal *model.Principal, alunoID int64, dataAula time.Time, motivo string) (*model.Aluno, error) {
+	aluno, err := s.findAlunoByPrincipal(principal, alunoID)
+	if err != nil {
+		return nil, err
+	}
+	return s.rescheduleAluno(aluno, model.HistoricoAutorProfessor, dataAula, motivo)
+}
+
+func (s *Service) CompleteAlunoByPrincipal(principal *model.Principal, alunoID int64) (*model.Aluno, error) {
+	aluno, err := s.findAlunoByPrincipal(principal, alunoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !aluno.IsAtiva() {
+		return nil, &model.ConflictError{
+			Message: fmt.Sprintf("Aula with status %s cannot be completed", aluno.Status),
+		}
+	}
+	if aluno.FimAula().After(time.Now()) {
+		return nil, &model.ConflictError{
+			Message: "Aula cannot be completed before it ends",
+		}
+	}
+
+	return s.updateAlunoStatus(aluno, model.AulaStatusCompleted, aluno.DataAula, model.HistoricoAutorProfessor, "")
+}
+
+func (s *Service) GetAlunoHistoricoByPrincipal(principal *model.Principal, alunoID int64) ([]*model.AlunoHistorico, error) {
+	aluno, err := s.findAlunoByPrincipal(principal, alunoID)
+	if err != nil {
+		return nil, err
+	}
+	return s.findAlunoHistorico(aluno.ID)
+}
+
+func (s *Service) GetAlunoBySignature(alunoID int64, signature string) (*model.Aluno, error) {
+	return s.findAlunoBySignature(alunoID, signature)
+}
+
+func (s *Service) CancelAlunoBySignature(alunoID int64, signature string, motivo string) (*model.Aluno, error) {
+	aluno, err := s.findAlunoBySignature(alunoID, signature)
+	if err != nil {
+		return nil, err
+	}
+	return s.cancelAluno(aluno, model.HistoricoAutorAluno, motivo)
+}
+
+func (s *Service) RescheduleAlunoBySignature(alunoID int64, signature string, dataAula time.Time, motivo string) (*model.Aluno, error) {
+	aluno, err := s.findAlunoBySignature(alunoID, signature)
+	if err != nil {
+		return nil, err
+	}
+	return s.rescheduleAluno(aluno, model.HistoricoAutorAluno, dataAula, motivo)
+}
+
+func (s *Service) GetAlunoHistoricoBySignature(alunoID int64, signature string) ([]*model.AlunoHistorico, error) {
+	aluno, err := s.findAlunoBySignature(alunoID, signature)
+	if err != nil {
+		return nil, err
+	}
+	return s.findAlunoHistorico(aluno.ID)
+}
+
+func (s *Service) findAlunoByPrincipal(principal *model.Principal, alunoID int64) (*model.Aluno, error) {
+	professor, err := professorFromPrincipal(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	aluno, err := s.findAlunoByID(alunoID)
+	if err != nil {
+		return nil, err
+	}
+	if aluno.ProfessorID != professor.ID {
+		return nil, &model.AlunoNotFoundError{
+			Message: fmt.Sprintf("Aluno with ID %d not found", alunoID),
+		}
+	}
+
+	return aluno, nil
+}
+
+func (s *Service) findAlunoBySignature(alunoID int64, signature string) (*model.Aluno, error) {
+	if !checkAlunoSignature(alunoID, signature) {
+		return nil, &model.AlunoNotFoundError{
+			Message: fmt.Sprintf("Aluno with ID %d not found", alunoID),
+		}
+	}
+	return s.findAlunoByID(alunoID)
+}
+
+func (s *Service) findAlunoByID(alunoID int64) (*model.Aluno, error) {
+	aluno, err := s.alunos.FindByID(alunoID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &model.AlunoNotFoundError{
+				Message: fmt.Sprintf("Aluno with ID %d not found", alunoID),
+			}
+		}
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	return aluno, nil
+}
+
+func (s *Service) findAlunoHistorico(alunoID int64) ([]*model.AlunoHistorico, error) {
+	historico, err := s.alunos.FindHistorico(alunoID)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	return historico, nil
+}
+
+func (s *Service) cancelAluno(aluno *model.Aluno, autor, motivo string) (*model.Aluno, error) {
+	if err := validator.ValidateMotivo(motivo); err != nil {
+		return nil, err
+	}
+	if err := checkAlunoAlteravel(aluno); err != nil {
+		return nil, err
+	}
+
+	return s.updateAlunoStatus(aluno, model.AulaStatusCancelled, aluno.DataAula, autor, motivo)
+}
+
+func (s *Service) rescheduleAluno(aluno *model.Aluno, autor string, dataAula time.Time, motivo string) (*model.Aluno, error) {
+	if err := validator.ValidateMotivo(motivo); err != nil {
+		return nil, err
+	}
+	if err := checkAlunoAlteravel(aluno); err != nil {
+		return nil, err
+	}
+
+	reagendado := *aluno
+	reagendado.DataAula = dataAula
+	if err := validator.ValidateAluno(&reagendado); err != nil {
+		return nil, err
+	}
+	if err := validator.ValidateAlunoDisponibilidade(&reagendado, s.professores); err != nil {
+		return nil, err
+	}
+
+	return s.updateAlunoStatus(aluno, model.AulaStatusRescheduled, dataAula, autor, motivo)
+}
+
+// checkAlunoAlteravel ensures the lesson is still active and that the
+// cancellation window has not been reached yet.
+func checkAlunoAlteravel(aluno *model.Aluno) error {
+	if !aluno.IsAtiva() {
+		return &model.ConflictError{
+			Message: fmt.Sprintf("Aula with status %s cannot be changed", aluno.Status),
+		}
+	}
+
+	limite := aluno.DataAula.Add(-time.Duration(config.CancellationWindow) * time.Hour)
+	if time.Now().After(limite) {
+		return &model.ConflictError{
+			Message: fmt.Sprintf("Aula can only be changed up to %d hours before it starts", config.CancellationWindow),
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) updateAlunoStatus(aluno *model.Aluno, status string, dataAula time.Time, autor, motivo string) (*model.Aluno, error) {
+	historico := &model.AlunoHistorico{
+		StatusAnterior:   aluno.Status,
+		StatusNovo:       status,
+		DataAulaAnterior: aluno.DataAula,
+		DataAulaNova:     dataAula,
+		Autor:            autor,
+		Motivo:           motivo,
+	}
+
+	aluno.Status = status
+	aluno.DataAula = dataAula
+	aluno, err := s.alunos.UpdateAgenda(aluno, historico)
+	if err != nil {
+		if err == database.ErrAulaConflict {
+			return nil, &model.ConflictError{
+				Message: "The professor already has a lesson booked at this time",
+			}
+		}
+		if err == database.ErrAulaChanged {
+			// The status and the cancellation window were checked against
+			// the lesson as it was before another request changed it.
+			return nil, &model.ConflictError{
+				Message: "Aula was changed by another request, try again",
+			}
+		}
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return aluno, nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"database/sql"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+// Authenticate validates the access token and loads the account it was
+// issued to.
+func (s *Service) Authenticate(token string) (*model.Principal, error) {
+	claims, err := s.getClaimsFromAccessToken(token)
+	if err != nil {
+		return nil, &model.JwtTokenError{
+			Message: err.Error(),
+		}
+	}
+
+	if claims.Session == "" || !s.tokens.ExistsActiveSession(claims.Session) {
+		return nil, &model.JwtTokenError{
+			Message: "Session has been revoked",
+		}
+	}
+
+	principal, err := s.loadPrincipal(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	principal.SessionID = claims.Session
+	principal.TokenID = claims.ID
+	principal.TokenExpiresAt = claims.ExpiresAt.Time
+	return principal, nil
+}
+
+// loadPrincipal loads the account the token claims were issued to, ensuring it
+// still exists and is allowed to use the API.
+func (s *Service) loadPrincipal(claims *tokenClaims) (*model.Principal, error) {
+	principal := &model.Principal{
+		Subject: claims.Subject,
+		Role:    claims.role(),
+	}
+
+	var err error
+	switch principal.Role {
+	case model.RoleProfessor:
+		principal.Professor, err = s.professores.FindByEmail(principal.Subject)
+		if err != nil {
+			break
+		}
+		if principal.Professor.TokenVersion != claims.Version {
+			return nil, &model.JwtTokenError{
+				Message: "Token has been revoked",
+			}
+		}
+		if principal.Professor.SuspendedAt.Valid {
+			return nil, &model.ForbiddenError{
+				Message: "Professor account is suspended",
+			}
+		}
+	case model.RoleStudent:
+		principal.Estudante, err = s.alunos.FindEstudanteByEmail(principal.Subject)
+	case model.RoleAdmin:
+		principal.Administrador, err = s.tokens.FindAdministradorByEmail(principal.Subject)
+	default:
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &model.JwtTokenError{
+				Message: "Invalid token subject",
+			}
+		}
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return principal, nil
+}
+
+func professorFromPrincipal(principal *model.Principal) (*model.Professor, error) {
+	if principal == nil || principal.Professor == nil {
+		return nil, &model.ForbiddenError{
+			Message: "Only professors are allowed to perform this action",
+		}
+	}
+	return principal.Professor, nil
+}
+
+func estudanteFromPrincipal(principal *model.Principal) (*model.Estudante, error) {
+	if principal == nil || principal.Estudante == nil {
+		return nil, &model.ForbiddenError{
+			Message: "Only students are allowed to perform this action",
+		}
+	}
+	return principal.Estudante, nil
+}
+
+func checkAdminPrincipal(principal *model.Principal) error {
+	if principal == nil || principal.Administrador == nil {
+		return &model.ForbiddenError{
+			Message: "Only administrators are allowed to perform this action",
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/cleysonph/hyperprof/internal/database"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/validator"
+)
+
+// FindAvaliacoesByProfessorID returns a page of the reviews of the professor,
+// newest first.
+func FindAvaliacoesByProfessorID(professorID int64, page, limit int32) (*model.Page[*model.Avaliacao], error) {
+	if page == 0 {
+		page = 1
+	}
+	if limit == 0 {
+		limit = defaultPageLimit
+	}
+	if err := validator.ValidatePagination(page, limit); err != nil {
+		return nil, err
+	}
+
+	if !database.ExistsProfessorByID(professorID) {
+		return nil, &model.ProfessorNotFoundError{
+			Message: fmt.Sprintf("Professor with ID %d not found", professorID),
+		}
+	}
+
+	avaliacoes, total, err := database.FindAvaliacoesByProfessorID(professorID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	for _, avaliacao := range avaliacoes {
+		avaliacao.Autor = autorPublico(avaliacao.Autor)
+	}
+
+	return &model.Page[*model.Avaliacao]{
+		Data:       avaliacoes,
+		Pagination: model.NewPagination(page, limit, total),
+	}, nil
+}
+
+// CreateAvaliacaoBySignature reviews the lesson through the link sent to the
+// student when it was booked.
+func (s *Service) CreateAvaliacaoBySignature(alunoID int64, signature string, avaliacao *model.Avaliacao) (*model.Avaliacao, error) {
+	aluno, err := s.findAlunoBySignature(alunoID, signature)
+	if err != nil {
+		return nil, err
+	}
+	return createAvaliacao(aluno, avaliacao)
+}
+
+// CreateAvaliacaoByEstudantePrincipal reviews a lesson booked by the
+// authenticated student.
+func (s *Service) CreateAvaliacaoByEstudantePrincipal(principal *model.Principal, alunoID int64, avaliacao *model.Avaliacao) (*model.Avaliacao, error) {
+	estudante, err := estudanteFromPrincipal(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	aluno, err := s.findAlunoByID(alunoID)
+	if err != nil {
+		return nil, err
+	}
+	if !aluno.EstudanteID.Valid || aluno.EstudanteID.Int64 != estudante.ID {
+		return nil, &model.AlunoNotFoundError{
+			Message: fmt.Sprintf("Aluno with ID %d not found", alunoID),
+		}
+	}
+
+	return createAvaliacao(aluno, avaliacao)
+}
+
+// createAvaliacao reviews the lesson, which must have been completed and not
+// reviewed yet.
+func createAvaliacao(aluno *model.Aluno, avaliacao *model.Avaliacao) (*model.Avaliacao, error) {
+	avaliacao.Comentario = strings.TrimSpace(avaliacao.Comentario)
+	if err := validator.ValidateAvaliacao(avaliacao); err != nil {
+		return nil, err
+	}
+
+	if aluno.Status != model.AulaStatusCompleted {
+		return nil, &model.ConflictError{
+			Message: "Only completed aulas can be reviewed",
+		}
+	}
+	if database.ExistsAvaliacaoByAlunoID(aluno.ID) {
+		return nil, &model.ConflictError{
+			Message: "Aula has already been reviewed",
+		}
+	}
+
+	avaliacao.AlunoID = aluno.ID
+	avaliacao.ProfessorID = aluno.ProfessorID
+	avaliacao, err := database.CreateAvaliacao(avaliacao)
+	if database.IsDuplicateEntry(err) {
+		// Another request reviewed the aula since it was checked above.
+		return nil, &model.ConflictError{
+			Message: "Aula has already been reviewed",
+		}
+	}
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	avaliacao.Autor = autorPublico(avaliacao.Autor)
+	return avaliacao, nil
+}
+
+// AnswerAvaliacaoByPrincipal publishes the reply of the professor to one of
+// their reviews. A review can only be answered once.
+func AnswerAvaliacaoByPrincipal(principal *model.Principal, avaliacaoID int64, resposta string) (*model.Avaliacao, error) {
+	professor, err := professorFromPrincipal(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	resposta = strings.TrimSpace(resposta)
+	if err := validator.ValidateResposta(resposta); err != nil {
+		return nil, err
+	}
+
+	avaliacao, err := database.FindAvaliacaoByID(avaliacaoID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	if err == sql.ErrNoRows || avaliacao.ProfessorID != professor.ID {
+		return nil, &model.AvaliacaoNotFoundError{
+			Message: fmt.Sprintf("Avaliacao with ID %d not found", avaliacaoID),
+		}
+	}
+
+	answered, err := database.AnswerAvaliacao(avaliacaoID, resposta)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	if !answered {
+		return nil, &model.ConflictError{
+			Message: "Avaliacao has already been answered",
+		}
+	}
+
+	avaliacao, err = database.FindAvaliacaoByID(avaliacaoID)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	avaliacao.Autor = autorPublico(avaliacao.Autor)
+	return avaliacao, nil
+}
+
+// autorPublico only keeps the first name of the student, as reviews are
+// public.
+func autorPublico(nome string) string {
+	if fields := strings.Fields(nome); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cleysonph/hyperprof/internal/database"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/validator"
+)
+
+// caminhoSeparator joins the names of the path of a subject when it is shown
+// as text.
+const caminhoSeparator = " > "
+
+// catalogo holds the whole subjects catalogue. It is small enough to be
+// loaded every time the tree has to be walked.
+type catalogo struct {
+	disciplinas []*model.Disciplina
+	byID        map[int64]*model.Disciplina
+}
+
+func loadCatalogo() (*catalogo, error) {
+	disciplinas, err := database.FindAllDisciplinas()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &catalogo{
+		disciplinas: disciplinas,
+		byID:        make(map[int64]*model.Disciplina, len(disciplinas)),
+	}
+	for _, disciplina := range disciplinas {
+		c.byID[disciplina.ID] = disciplina
+	}
+	return c, nil
+}
+
+// tree returns the root subjects, each holding its descendants, sorted by
+// name.
+func (c *catalogo) tree() []*model.Disciplina {
+	roots := []*model.Disciplina{}
+	for _, disciplina := range c.disciplinas {
+		if parent, ok := c.byID[disciplina.ParentID.Int64]; disciplina.ParentID.Valid && ok {
+			parent.Subdisciplinas = append(parent.Subdisciplinas, disciplina)
+			continue
+		}
+		roots = append(roots, disciplina)
+	}
+	return roots
+}
+
+// caminho returns the names from the root of the catalogue down to the
+// subject.
+func (c *catalogo) caminho(id int64) []string {
+	var names []string
+	disciplina, ok := c.byID[id]
+	// A subject can only be attached to an existing one, so the tree has no
+	// cycles, the bound only guards against a corrupted table.
+	for ok && len(names) < len(c.byID) {
+		names = append([]string{disciplina.Nome}, names...)
+		if !disciplina.ParentID.Valid {
+			break
+		}
+		disciplina, ok = c.byID[disciplina.ParentID.Int64]
+	}
+	return names
+}
+
+// descendants returns the id of the subject followed by the ids of all the
+// subjects below it.
+func (c *catalogo) descendants(id int64) []int64 {
+	ids := []int64{id}
+	for i := 0; i < len(ids) && len(ids) <= len(c.byID); i++ {
+		for _, disciplina := range c.disciplinas {
+			if disciplina.ParentID.Valid && disciplina.ParentID.Int64 == ids[i] {
+				ids = append(ids, disciplina.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// professorDisciplinas returns copies of the subjects with ids carrying their
+// path, sorted by it.
+func (c *catalogo) professorDisciplinas(ids []int64) []*model.Disciplina {
+	disciplinas := make([]*model.Disciplina, 0, len(ids))
+	for _, id := range ids {
+		disciplina, ok := c.byID[id]
+		if !ok {
+			continue
+		}
+		disciplinas = append(disciplinas, &model.Disciplina{
+			ID:        disciplina.ID,
+			ParentID:  disciplina.ParentID,
+			Nome:      disciplina.Nome,
+			Caminho:   c.caminho(id),
+			CreatedAt: disciplina.CreatedAt,
+			UpdatedAt: disciplina.UpdatedAt,
+		})
+	}
+	sort.Slice(disciplinas, func(i, j int) bool {
+		return strings.Join(disciplinas[i].Caminho, caminhoSeparator) < strings.Join(disciplinas[j].Caminho, caminhoSeparator)
+	})
+	return disciplinas
+}
+
+// loadDisciplinas fills the subjects taught by each of the professores.
+func (s *Service) loadDisciplinas(professores ...*model.Professor) error {
+	professorIDs := make([]int64, len(professores))
+	for i, professor := range professores {
+		professorIDs[i] = professor.ID
+	}
+
+	disciplinaIDs, err := s.professores.FindDisciplinaIDs(professorIDs)
+	if err != nil {
+		return err
+	}
+	return attachDisciplinas(professores, disciplinaIDs)
+}
+
+func attachDisciplinas(professores []*model.Professor, disciplinaIDs map[int64][]int64) error {
+	// The catalogue is only needed when one of them teaches something
+	if len(disciplinaIDs) == 0 {
+		for _, professor := range professores {
+			professor.Disciplinas = []*model.Disciplina{}
+		}
+		return nil
+	}
+
+	c, err := loadCatalogo()
+	if err != nil {
+		return err
+	}
+
+	for _, professor := range professores {
+		professor.Disciplinas = c.professorDisciplinas(disciplinaIDs[professor.ID])
+	}
+	return nil
+}
+
+// disciplinaNames returns the names of the subjects and of their ancestors,
+// without repetitions, so a search for a broad subject also finds the
+// professores teaching a narrower one.
+func disciplinaNames(disciplinas []*model.Disciplina) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, disciplina := range disciplinas {
+		for _, name := range disciplina.Caminho {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// subjectFilterIDs returns the ids of the subject and of its descendants.
+func subjectFilterIDs(subject int64) ([]int64, error) {
+	c, err := loadCatalogo()
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	if _, ok := c.byID[subject]; !ok {
+		validationErr := &model.ValidationError{}
+		validationErr.AddError("subject", "does not exist")
+		return nil, validationErr
+	}
+	return c.descendants(subject), nil
+}
+
+func FindAllDisciplinas() ([]*model.Disciplina, error) {
+	c, err := loadCatalogo()
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	return c.tree(), nil
+}
+
+func CreateDisciplinaByAdminPrincipal(principal *model.Principal, disciplina *model.Disciplina) (*model.Disciplina, error) {
+	if err := checkAdminPrincipal(principal); err != nil {
+		return nil, err
+	}
+
+	disciplina.Nome = strings.TrimSpace(disciplina.Nome)
+	if err := validator.ValidateDisciplina(disciplina); err != nil {
+		return nil, err
+	}
+
+	c, err := loadCatalogo()
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	if _, ok := c.byID[disciplina.ParentID.Int64]; disciplina.ParentID.Valid && !ok {
+		validationErr := &model.ValidationError{}
+		validationErr.AddError("parent_id", "does not exist")
+		return nil, validationErr
+	}
+	for _, sibling := range c.disciplinas {
+		if sibling.ParentID == disciplina.ParentID && strings.EqualFold(sibling.Nome, disciplina.Nome) {
+			return nil, &model.ConflictError{
+				Message: fmt.Sprintf("Disciplina %s already exists", strings.Join(c.caminho(sibling.ID), caminhoSeparator)),
+			}
+		}
+	}
+
+	disciplina, err = database.CreateDisciplina(disciplina)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return disciplina, nil
+}
+
+// DeleteDisciplinaByAdminPrincipal removes a subject from the catalogue. Only
+// subjects without descendants that nobody teaches can be removed.
+func DeleteDisciplinaByAdminPrincipal(principal *model.Principal, disciplinaID int64) error {
+	if err := checkAdminPrincipal(principal); err != nil {
+		return err
+	}
+
+	c, err := loadCatalogo()
+	if err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	if _, ok := c.byID[disciplinaID]; !ok {
+		return &model.DisciplinaNotFoundError{
+			Message: fmt.Sprintf("Disciplina with ID %d not found", disciplinaID),
+		}
+	}
+	if len(c.descendants(disciplinaID)) > 1 {
+		return &model.ConflictError{
+			Message: "Disciplina has subdisciplinas",
+		}
+	}
+	if database.ExistsProfessorDisciplinaByDisciplinaID(disciplinaID) {
+		return &model.ConflictError{
+			Message: "Disciplina is taught by professores",
+		}
+	}
+
+	if err := database.DeleteDisciplinaByID(disciplinaID); err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// SetDisciplinasByPrincipal replaces the subjects taught by the professor.
+func SetDisciplinasByPrincipal(principal *model.Principal, disciplinaIDs []int64) ([]*model.Disciplina, error) {
+	professor, err := professorFromPrincipal(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validator.ValidateProfessorDisciplinas(disciplinaIDs); err != nil {
+		return nil, err
+	}
+
+	c, err := loadCatalogo()
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	for _, id := range disciplinaIDs {
+		if _, ok := c.byID[id]; !ok {
+			validationErr := &model.ValidationError{}
+			validationErr.AddError("disciplina_ids", fmt.Sprintf("disciplina %d does not exist", id))
+			return nil, validationErr
+		}
+	}
+
+	return saveProfessorDisciplinas(c, professor, disciplinaIDs)
+}
+
+// DeleteDisciplinaByPrincipal stops the professor from teaching a subject.
+func DeleteDisciplinaByPrincipal(principal *model.Principal, disciplinaID int64) error {
+	professor, err := professorFromPrincipal(principal)
+	if err != nil {
+		return err
+	}
+
+	c, err := loadCatalogo()
+	if err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	current, err := database.FindDisciplinaIDsByProfessorIDs([]int64{professor.ID})
+	if err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	disciplinaIDs := []int64{}
+	for _, id := range current[professor.ID] {
+		if id != disciplinaID {
+			disciplinaIDs = append(disciplinaIDs, id)
+		}
+	}
+	if len(disciplinaIDs) == len(current[professor.ID]) {
+		return &model.DisciplinaNotFoundError{
+			Message: fmt.Sprintf("Disciplina with ID %d not found", disciplinaID),
+		}
+	}
+
+	_, err = saveProfessorDisciplinas(c, professor, disciplinaIDs)
+	return err
+}
+
+func saveProfessorDisciplinas(c *catalogo, professor *model.Professor, disciplinaIDs []int64) ([]*model.Disciplina, error) {
+	disciplinas := c.professorDisciplinas(disciplinaIDs)
+	busca := strings.Join(disciplinaNames(disciplinas), " ")
+	if err := database.SetProfessorDisciplinas(professor.ID, disciplinaIDs, busca); err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	professor.Disciplinas = disciplinas
+	indexProfessor(professor)
+	return disciplinas, nil
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/cleysonph/hyperprof/internal/database"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/validator"
+)
+
+func FindDisponibilidadesByProfessorID(professorID int64) ([]*model.Disponibilidade, []*model.DisponibilidadeExcecao, error) {
+	if !database.ExistsProfessorByID(professorID) {
+		return nil, nil, &model.ProfessorNotFoundError{
+			Message: fmt.Sprintf("Professor with ID %d not found", professorID),
+		}
+	}
+
+	return findDisponibilidades(professorID)
+}
+
+func GetDisponibilidadesByPrincipal(principal *model.Principal) ([]*model.Disponibilidade, []*model.DisponibilidadeExcecao, error) {
+	professor, err := professorFromPrincipal(principal)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return findDisponibilidades(professor.ID)
+}
+
+func findDisponibilidades(professorID int64) ([]*model.Disponibilidade, []*model.DisponibilidadeExcecao, error) {
+	disponibilidades, err := database.FindDisponibilidadesByProfessorID(professorID)
+	if err != nil {
+		return nil, nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	excecoes, err := database.FindDisponibilidadeExcecoesByProfessorID(professorID)
+	if err != nil {
+		return nil, nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return disponibilidades, excecoes, nil
+}
+
+func CreateDisponibilidadeByPrincipal(principal *model.Principal, disponibilidade *model.Disponibilidade) (*model.Disponibilidade, error) {
+	professor, err := professorFromPrincipal(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	disponibilidade.ProfessorID = professor.ID
+	if err := validator.ValidateDisponibilidade(disponibilidade); err != nil {
+		return nil, err
+	}
+
+	disponibilidade, err = database.CreateDisponibilidade(disponibilidade)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return disponibilidade, nil
+}
+
+func DeleteDisponibilidadeByPrincipal(principal *model.Principal, disponibilidadeID int64) error {
+	professor, err := professorFromPrincipal(principal)
+	if err != nil {
+		return err
+	}
+
+	disponibilidade, err := database.FindDisponibilidadeByID(disponibilidadeID)
+	if err != nil && err != sql.ErrNoRows {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	if err == sql.ErrNoRows || disponibilidade.ProfessorID != professor.ID {
+		return &model.DisponibilidadeNotFoundError{
+			Message: fmt.Sprintf("Disponibilidade with ID %d not found", disponibilidadeID),
+		}
+	}
+
+	err = database.DeleteDisponibilidadeByID(disponibilidadeID)
+	if err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+func CreateDisponibilidadeExcecaoByPrincipal(principal *model.Principal, excecao *model.DisponibilidadeExcecao) (*model.DisponibilidadeExcecao, error) {
+	professor, err := professorFromPrincipal(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	excecao.ProfessorID = professor.ID
+	if err := validator.ValidateDisponibilidadeExcecao(excecao); err != nil {
+		return nil, err
+	}
+
+	excecao, err = database.CreateDisponibilidadeExcecao(excecao)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return excecao, nil
+}
+
+func DeleteDisponibilidadeExcecaoByPrincipal(principal *model.Principal, excecaoID int64) error {
+	professor, err := professorFromPrincipal(principal)
+	if err != nil {
+		return err
+	}
+
+	excecao, err := database.FindDisponibilidadeExcecaoByID(excecaoID)
+	if err != nil && err != sql.ErrNoRows {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	if err == sql.ErrNoRows || excecao.ProfessorID != professor.ID {
+		return &model.DisponibilidadeNotFoundError{
+			Message: fmt.Sprintf("Disponibilidade excecao with ID %d not found", excecaoID),
+		}
+	}
+
+	err = database.DeleteDisponibilidadeExcecaoByID(excecaoID)
+	if err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"database/sql"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/validator"
+	"github.com/rs/zerolog/log"
+)
+
+func (s *Service) CreateEstudante(estudante *model.Estudante, passwordConfirmation string) (*model.Estudante, error) {
+	if err := validator.ValidateEstudante(estudante, passwordConfirmation); err != nil {
+		return nil, err
+	}
+
+	hash, err := hashPassword(estudante.Password)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	estudante.Password = hash
+	estudante, err = s.alunos.CreateEstudante(estudante)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	// Bookings made with the email are only linked to the account once the
+	// student proves they own it. A failure here can be recovered through
+	// ResendEstudanteEmailVerification.
+	if err := s.sendEstudanteEmailVerification(estudante); err != nil {
+		log.Error().Err(err).Int64("estudante_id", estudante.ID).Msg("failed to send email verification")
+	}
+
+	return estudante, nil
+}
+
+func (s *Service) GetEstudanteByPrincipal(principal *model.Principal) (*model.Estudante, error) {
+	return estudanteFromPrincipal(principal)
+}
+
+func (s *Service) GetAulasByPrincipal(principal *model.Principal, status string) ([]*model.Aluno, error) {
+	if err := validator.ValidateAlunoStatus(status); err != nil {
+		return nil, err
+	}
+
+	estudante, err := estudanteFromPrincipal(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	alunos, err := s.alunos.FindByEstudanteID(estudante.ID, status)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	for _, aluno := range alunos {
+		resolveFotos(aluno.Professor)
+	}
+
+	return alunos, nil
+}
+
+func (s *Service) LoginEstudante(email, password string, client *model.SessionClient) ([]string, error) {
+	if err := validator.ValidateLogin(email, password); err != nil {
+		return nil, err
+	}
+
+	if err := checkLoginAllowed(model.RoleStudent, email, client); err != nil {
+		return nil, err
+	}
+
+	estudante, err := s.alunos.FindEstudanteByEmail(email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, badCredentials(model.RoleStudent, email, client)
+		}
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	match := checkPasswordHash(password, estudante.Password)
+	if !match {
+		return nil, badCredentials(model.RoleStudent, email, client)
+	}
+	loginSucceeded(model.RoleStudent, email)
+
+	tokens, err := s.generateTokens(estudante.Email, model.RoleStudent, 0, client)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"bytes"
+	"image"
+	"io"
+	"mime/multipart"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/database"
+	"github.com/cleysonph/hyperprof/internal/imaging"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/storage"
+	"github.com/cleysonph/hyperprof/internal/validator"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// fotoKeyPrefix groups the profile photos in the storage. Each photo is a
+// directory holding its variants, e.g. fotos/8c4f0a1e/256.jpg, and the
+// directory, with its trailing slash, is what professores.foto_perfil holds.
+const fotoKeyPrefix = "fotos/"
+
+// fotoFormato is a format the variants of the photos are encoded in.
+type fotoFormato struct {
+	nome        string
+	ext         string
+	contentType string
+	encode      func(w io.Writer, img image.Image) error
+}
+
+// fotoFormatos are the formats each variant is generated in, the preferred
+// one first. JPEG is kept for the clients that cannot display WebP.
+var fotoFormatos = []fotoFormato{
+	{nome: "webp", ext: ".webp", contentType: "image/webp", encode: imaging.EncodeWebP},
+	{nome: "jpeg", ext: ".jpg", contentType: "image/jpeg", encode: imaging.EncodeJPEG},
+}
+
+func fotoVarianteKey(dir string, tamanho int, formato fotoFormato) string {
+	return dir + strconv.Itoa(tamanho) + formato.ext
+}
+
+// processFoto checks the uploaded file really is an image, whatever the
+// client claimed, and stores its variants. It returns the directory holding
+// them.
+func processFoto(file multipart.File) (string, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	format, width, height := imaging.DecodeConfig(data)
+	if err := validator.ValidateProfessorFotoImage(format, width, height); err != nil {
+		return "", err
+	}
+
+	// Decoding drops the metadata, EXIF included, which the variants are
+	// generated from
+	img, err := imaging.Decode(data)
+	if err != nil {
+		validationErr := &model.ValidationError{}
+		validationErr.AddError("foto", "must be a valid image")
+		return "", validationErr
+	}
+
+	dir := fotoKeyPrefix + uuid.NewString() + "/"
+	for _, tamanho := range model.FotoTamanhos {
+		variante := imaging.Square(img, tamanho)
+		for _, formato := range fotoFormatos {
+			var buf bytes.Buffer
+			err := formato.encode(&buf, variante)
+			if err == nil {
+				key := fotoVarianteKey(dir, tamanho, formato)
+				err = storage.Put(key, &buf, int64(buf.Len()), formato.contentType)
+			}
+			if err != nil {
+				deleteFoto(dir)
+				return "", &model.ApplicationError{
+					Message: err.Error(),
+				}
+			}
+		}
+	}
+	return dir, nil
+}
+
+// resolveFotos fills the URLs of the variants of the photo of each
+// professor.
+func resolveFotos(professores ...*model.Professor) {
+	for _, professor := range professores {
+		if professor == nil {
+			continue
+		}
+		professor.Fotos = []*model.FotoVariante{}
+		if !professor.FotoPerfil.Valid {
+			continue
+		}
+
+		fotos, err := fotoVariantes(professor.FotoPerfil.String)
+		if err != nil {
+			log.Warn().Err(err).Int64("professor_id", professor.ID).Msg("failed to resolve photo URLs")
+			continue
+		}
+		professor.Fotos = fotos
+	}
+}
+
+func fotoVariantes(fotoPerfil string) ([]*model.FotoVariante, error) {
+	// Photos uploaded before the variants were introduced are a single file
+	if !strings.HasSuffix(fotoPerfil, "/") {
+		url, err := storage.URL(fotoPerfil)
+		if err != nil {
+			return nil, err
+		}
+		return []*model.FotoVariante{{Formato: "original", URL: url}}, nil
+	}
+
+	fotos := make([]*model.FotoVariante, 0, len(model.FotoTamanhos)*len(fotoFormatos))
+	for _, tamanho := range model.FotoTamanhos {
+		for _, formato := range fotoFormatos {
+			url, err := storage.URL(fotoVarianteKey(fotoPerfil, tamanho, formato))
+			if err != nil {
+				return nil, err
+			}
+			fotos = append(fotos, &model.FotoVariante{
+				Tamanho: tamanho,
+				Formato: formato.nome,
+				URL:     url,
+			})
+		}
+	}
+	return fotos, nil
+}
+
+// fotoKeys returns the keys of the files making up a photo.
+func fotoKeys(fotoPerfil string) ([]string, error) {
+	if !strings.HasSuffix(fotoPerfil, "/") {
+		return []string{fotoPerfil}, nil
+	}
+
+	objects, err := storage.List(fotoPerfil)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(objects))
+	for i, object := range objects {
+		keys[i] = object.Key
+	}
+	return keys, nil
+}
+
+// deleteFoto removes the files of a photo no professor references anymore,
+// if any. Failures are only logged, ReconcileFotos removes what is left
+// behind.
+func deleteFoto(fotoPerfil string) {
+	if fotoPerfil == "" {
+		return
+	}
+
+	keys, err := fotoKeys(fotoPerfil)
+	if err != nil {
+		log.Error().Err(err).Str("foto_perfil", fotoPerfil).Msg("failed to list photo files")
+		return
+	}
+	for _, key := range keys {
+		if err := storage.Delete(key); err != nil {
+			log.Error().Err(err).Str("key", key).Msg("failed to delete photo file")
+		}
+	}
+}
+
+// legacyFotoKey matches the photos uploaded before they were kept under
+// fotoKeyPrefix: a UUID followed by the name of the uploaded file, at the root
+// of the storage.
+var legacyFotoKey = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}-[^/]+$`)
+
+// fotoObjects returns the stored files that are profile photos. The storage
+// may be shared, so anything else is left out.
+func fotoObjects() ([]*storage.ObjectInfo, error) {
+	objects, err := storage.List(fotoKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := storage.List("")
+	if err != nil {
+		return nil, err
+	}
+	for _, object := range all {
+		if legacyFotoKey.MatchString(object.Key) {
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
+// ReconcileFotos looks for the stored files no professor references and,
+// unless dryRun, deletes them. Files modified in the last grace are left
+// alone, as their upload may still be in progress. It returns the keys of the
+// unreferenced files.
+func ReconcileFotos(dryRun bool, grace time.Duration) ([]string, error) {
+	fotoPerfis, err := database.FindAllProfessorFotoPerfis()
+	if err != nil {
+		return nil, err
+	}
+	referenced := make(map[string]bool, len(fotoPerfis))
+	for _, fotoPerfil := range fotoPerfis {
+		referenced[fotoPerfil] = true
+	}
+
+	objects, err := fotoObjects()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-grace)
+	orphans := []string{}
+	for _, object := range objects {
+		if object.ModifiedAt.After(cutoff) {
+			continue
+		}
+		// Variants are referenced through the directory holding them
+		dir := object.Key[:strings.LastIndex(object.Key, "/")+1]
+		if referenced[object.Key] || dir != "" && referenced[dir] {
+			continue
+		}
+
+		if !dryRun {
+			if err := storage.Delete(object.Key); err != nil {
+				return orphans, err
+			}
+		}
+		orphans = append(orphans, object.Key)
+	}
+	return orphans, nil
+}
@@ -6,76 +6,197 @@ import (
 
 	"github.com/cleysonph/hyperprof/config"
 	"github.com/cleysonph/hyperprof/internal/database"
+	"github.com/cleysonph/hyperprof/internal/model"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
-func generateAccessToken(sub string) (string, error) {
-	return generateToken(sub, config.TokenSecret, time.Duration(config.TokenDuration)*time.Second)
+// tokenTypeMfa marks the tokens handed out by Login while the second factor
+// has not been verified yet. They are signed with the access token secret,
+// which also signs access tokens when no signing keys are configured, so
+// access tokens must never carry a type.
+const tokenTypeMfa = "mfa"
+
+// defaultMfaDuration is used when MFA_DURATION is not configured.
+const defaultMfaDuration = 5 * time.Minute
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Role    string `json:"role,omitempty"`
+	Version int32  `json:"ver,omitempty"`
+	Type    string `json:"typ,omitempty"`
+	Session string `json:"sid,omitempty"`
 }
 
-func generateRefreshToken(sub string) (string, error) {
-	return generateToken(sub, config.RefreshSecret, time.Duration(config.RefreshDuration)*time.Second)
+// role returns the role carried by the token. Tokens issued before roles were
+// introduced have no role claim and always belong to a professor.
+func (c *tokenClaims) role() string {
+	if c.Role == "" {
+		return model.RoleProfessor
+	}
+	return c.Role
 }
 
-func generateToken(sub, secret string, exp time.Duration) (string, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
-	claims := token.Claims.(jwt.MapClaims)
-	claims["sub"] = sub
-	claims["iat"] = time.Now().Unix()
-	claims["exp"] = time.Now().Add(exp).Unix()
+func newClaims(sub, role string, version int32, typ string, exp time.Duration) *tokenClaims {
+	now := time.Now()
+	return &tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   sub,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(exp)),
+		},
+		Role:    role,
+		Version: version,
+		Type:    typ,
+	}
+}
+
+func signToken(claims *tokenClaims, secret string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(secret))
 }
 
-func getSubFromAccessToken(token string) (string, error) {
-	return getSubFromToken(token, config.TokenSecret)
+func generateAccessToken(sub, role string, version int32, sessionID string) (string, error) {
+	claims := newClaims(sub, role, version, "", time.Duration(config.TokenDuration)*time.Second)
+	claims.Session = sessionID
+	return signAccessToken(claims)
 }
 
-func getSubFromRefreshToken(token string) (string, error) {
-	return getSubFromToken(token, config.RefreshSecret)
+// generateRefreshToken signs a refresh token and records it as a member of
+// familyID, so it can later be rotated or revoked along with its family.
+func (s *Service) generateRefreshToken(sub, role string, version int32, familyID, parentJTI string) (string, error) {
+	claims := newClaims(sub, role, version, "", time.Duration(config.RefreshDuration)*time.Second)
+	refreshToken := &model.RefreshToken{
+		JTI:       claims.ID,
+		FamilyID:  familyID,
+		Subject:   sub,
+		Role:      role,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	refreshToken.ParentJTI.String = parentJTI
+	refreshToken.ParentJTI.Valid = parentJTI != ""
+	if err := s.tokens.CreateRefreshToken(refreshToken); err != nil {
+		return "", err
+	}
+	return signToken(claims, config.RefreshSecret)
 }
 
-func getSubFromToken(token, secret string) (string, error) {
-	claims := jwt.MapClaims{}
-	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+func generateMfaToken(sub, role string, version int32) (string, error) {
+	exp := time.Duration(config.MfaDuration) * time.Second
+	if exp <= 0 {
+		exp = defaultMfaDuration
+	}
+	return signToken(newClaims(sub, role, version, tokenTypeMfa, exp), config.TokenSecret)
+}
+
+// generateTokens starts a new session for client, issuing an access token and
+// a refresh token that starts a new family. The session shares its id with
+// the family.
+func (s *Service) generateTokens(sub, role string, version int32, client *model.SessionClient) ([]string, error) {
+	now := time.Now()
+	session := &model.Session{
+		ID:         uuid.NewString(),
+		Subject:    sub,
+		Role:       role,
+		Device:     client.Device,
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
+		ExpiresAt:  now.Add(time.Duration(config.RefreshDuration) * time.Second),
+		LastUsedAt: now,
+	}
+	if err := s.tokens.CreateSession(session); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(sub, role, version, session.ID, "")
+}
+
+// rotateTokens issues the tokens that replace refreshToken, keeping them in
+// the same family and session.
+func (s *Service) rotateTokens(claims *tokenClaims, refreshToken *model.RefreshToken, client *model.SessionClient) ([]string, error) {
+	expiresAt := time.Now().Add(time.Duration(config.RefreshDuration) * time.Second)
+	if err := s.tokens.TouchSession(refreshToken.FamilyID, client, expiresAt); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(claims.Subject, claims.role(), claims.Version, refreshToken.FamilyID, refreshToken.JTI)
+}
+
+func (s *Service) issueTokens(sub, role string, version int32, familyID, parentJTI string) ([]string, error) {
+	accessToken, err := generateAccessToken(sub, role, version, familyID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	refreshToken, err := s.generateRefreshToken(sub, role, version, familyID, parentJTI)
+	if err != nil {
+		return nil, err
+	}
+	return []string{accessToken, refreshToken}, nil
+}
 
-	err = validateToken(token, secret)
+func (s *Service) getClaimsFromAccessToken(token string) (*tokenClaims, error) {
+	claims, err := s.getClaimsFromToken(token, accessTokenKey)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if claims.Type != "" {
+		return nil, errors.New("token is not an access token")
 	}
+	return claims, nil
+}
 
-	return claims["sub"].(string), nil
+func (s *Service) getClaimsFromMfaToken(token string) (*tokenClaims, error) {
+	claims, err := s.getClaimsFromToken(token, hmacKey(config.TokenSecret))
+	if err != nil {
+		return nil, err
+	}
+	if claims.Type != tokenTypeMfa {
+		return nil, errors.New("token is not a MFA token")
+	}
+	return claims, nil
 }
 
-func validateToken(token, secret string) error {
-	claims := jwt.MapClaims{}
+func (s *Service) getClaimsFromRefreshToken(token string) (*tokenClaims, error) {
+	return s.getClaimsFromToken(token, hmacKey(config.RefreshSecret))
+}
 
-	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+// hmacKey returns a keyfunc that only accepts tokens signed with secret.
+func hmacKey(secret string) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
 		return []byte(secret), nil
-	})
+	}
+}
+
+func (s *Service) getClaimsFromToken(token string, keyFunc jwt.Keyfunc) (*tokenClaims, error) {
+	claims := &tokenClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, keyFunc)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if exp, ok := claims["exp"].(float64); ok {
-		if time.Now().Unix() > int64(exp) {
-			return err
-		}
+	// Tokens issued before token ids were introduced cannot be revoked, so
+	// they are no longer accepted.
+	if claims.ID == "" {
+		return nil, errors.New("token has no id")
 	}
 
-	if database.ExistsInvalidatedTokenByToken(token) {
-		return errors.New("token has been invalidated")
+	if s.tokens.ExistsInvalidatedToken(claims.ID) {
+		return nil, errors.New("token has been invalidated")
 	}
 
-	return nil
+	return claims, nil
 }
 
-func invalidateTokens(tokens ...string) {
-	for _, token := range tokens {
-		database.CreateInvalidatedToken(token)
-	}
+// invalidateToken revokes the access or MFA token identified by jti until it
+// expires.
+func (s *Service) invalidateToken(jti string, expiresAt time.Time) {
+	s.tokens.CreateInvalidatedToken(jti, expiresAt)
+}
+
+// PurgeExpiredInvalidatedTokens forgets the invalidated tokens that have
+// expired since, as their signature check already rejects them.
+func PurgeExpiredInvalidatedTokens() (int64, error) {
+	return database.DeleteExpiredInvalidatedTokens(time.Now())
 }
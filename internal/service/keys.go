@@ -0,0 +1,184 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cleysonph/hyperprof/config"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// signingKey is a key used to sign or verify access tokens. Retired keys only
+// have the public half, they are kept so tokens signed before a rotation are
+// still accepted until they expire.
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+var (
+	// activeKey signs new access tokens. When it is nil the access tokens are
+	// signed with HS256 and config.TokenSecret.
+	activeKey        *signingKey
+	verificationKeys = map[string]*signingKey{}
+)
+
+// LoadSigningKeys reads the access token keys from config.JwtKeysDir. Every
+// <kid>.pem file holds a PKCS#8 RSA or Ed25519 private key, or a PKIX public
+// key for retired keys. The key named by config.JwtActiveKid signs new
+// tokens. Nothing is loaded when no directory is configured.
+func LoadSigningKeys() error {
+	if config.JwtKeysDir == "" {
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(config.JwtKeysDir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	keys := map[string]*signingKey{}
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+		key, err := readSigningKey(file, kid)
+		if err != nil {
+			return fmt.Errorf("loading key %s: %w", kid, err)
+		}
+		keys[kid] = key
+	}
+
+	active, ok := keys[config.JwtActiveKid]
+	if !ok {
+		return fmt.Errorf("active key %q not found in %s", config.JwtActiveKid, config.JwtKeysDir)
+	}
+	if active.private == nil {
+		return fmt.Errorf("active key %q has no private key", config.JwtActiveKid)
+	}
+
+	activeKey = active
+	verificationKeys = keys
+	return nil
+}
+
+func readSigningKey(file, kid string) (*signingKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	key := &signingKey{kid: kid}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported private key")
+		}
+		key.private = signer
+		key.public = signer.Public()
+	case "RSA PRIVATE KEY":
+		parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key.private = parsed
+		key.public = parsed.Public()
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key.public = parsed
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %s", block.Type)
+	}
+
+	switch key.public.(type) {
+	case *rsa.PublicKey:
+		key.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, errors.New("only RSA and Ed25519 keys are supported")
+	}
+
+	return key, nil
+}
+
+// signAccessToken signs claims with the active key, falling back to HS256
+// when no keys are configured.
+func signAccessToken(claims *tokenClaims) (string, error) {
+	if activeKey == nil {
+		return signToken(claims, config.TokenSecret)
+	}
+	token := jwt.NewWithClaims(activeKey.method, claims)
+	token.Header["kid"] = activeKey.kid
+	return token.SignedString(activeKey.private)
+}
+
+// accessTokenKey picks the key that verifies an access token from its kid
+// header.
+func accessTokenKey(token *jwt.Token) (interface{}, error) {
+	if activeKey == nil {
+		return hmacKey(config.TokenSecret)(token)
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := verificationKeys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.public, nil
+}
+
+// GetJWKS returns the public keys that verify access tokens, including the
+// retired ones.
+func GetJWKS() *model.JWKS {
+	jwks := &model.JWKS{Keys: make([]*model.JWK, 0, len(verificationKeys))}
+	for _, key := range verificationKeys {
+		jwk := &model.JWK{
+			Kid: key.kid,
+			Use: "sig",
+			Alg: key.method.Alg(),
+		}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+	return jwks
+}
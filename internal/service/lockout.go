@@ -0,0 +1,188 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cleysonph/hyperprof/config"
+	"github.com/cleysonph/hyperprof/internal/lockout"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/rs/zerolog/log"
+)
+
+// Defaults used when the LOGIN_* variables are not configured.
+const (
+	defaultLoginMaxAttempts   = 5
+	defaultLoginMaxIPAttempts = 50
+	defaultLoginLockout       = time.Minute
+	defaultLoginMaxLockout    = time.Hour
+)
+
+// loginAttemptWindow is how long a failed login keeps counting towards a
+// lockout.
+const loginAttemptWindow = 24 * time.Hour
+
+func accountLoginKey(role, email string) model.LoginAttemptKey {
+	return model.LoginAttemptKey{
+		Scope:      model.LoginScopeAccount,
+		Role:       role,
+		Identifier: strings.ToLower(strings.TrimSpace(email)),
+	}
+}
+
+func mfaTokenLoginKey(jti string) model.LoginAttemptKey {
+	return model.LoginAttemptKey{
+		Scope:      model.LoginScopeMfaToken,
+		Identifier: jti,
+	}
+}
+
+func ipLoginKey(ip string) model.LoginAttemptKey {
+	return model.LoginAttemptKey{
+		Scope:      model.LoginScopeIP,
+		Identifier: ip,
+	}
+}
+
+// checkLoginAllowed refuses the login while the account or the address it
+// comes from is locked, before the password is even checked.
+func checkLoginAllowed(role, email string, client *model.SessionClient) error {
+	now := time.Now()
+
+	if client.IP != "" {
+		attempt, err := lockout.Find(ipLoginKey(client.IP))
+		if err != nil {
+			return &model.ApplicationError{
+				Message: err.Error(),
+			}
+		}
+		if attempt != nil && attempt.IsLocked(now) {
+			return &model.TooManyRequestsError{
+				Message:    "Too many failed logins from this address, try again later",
+				RetryAfter: attempt.LockedUntil.Time.Sub(now),
+			}
+		}
+	}
+
+	attempt, err := lockout.Find(accountLoginKey(role, email))
+	if err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	if attempt != nil && attempt.IsLocked(now) {
+		return &model.AccountLockedError{
+			Message:    "Too many failed logins for this account, try again later",
+			RetryAfter: attempt.LockedUntil.Time.Sub(now),
+		}
+	}
+
+	return nil
+}
+
+// badCredentials counts a failed login against the account and the address
+// it comes from and returns the error reported for it. Unknown emails are
+// counted too, so lockouts do not reveal which accounts exist.
+func badCredentials(role, email string, client *model.SessionClient) error {
+	now := time.Now()
+	recordLoginFailure(accountLoginKey(role, email), loginMaxAttempts(), now)
+	if client.IP != "" {
+		recordLoginFailure(ipLoginKey(client.IP), loginMaxIPAttempts(), now)
+	}
+
+	return &model.BadCredentialsError{
+		Message: "Invalid credentials",
+	}
+}
+
+// recordLoginFailure locks key once it reaches maxAttempts failures. Every
+// further failure doubles the lockout, up to the configured maximum. Errors
+// are only logged, the login is refused either way.
+func recordLoginFailure(key model.LoginAttemptKey, maxAttempts int32, now time.Time) {
+	attempt, err := lockout.AddFailure(key, now, now.Add(-loginAttemptWindow))
+	if err != nil {
+		log.Error().Err(err).Str("scope", key.Scope).Msg("failed to record login failure")
+		return
+	}
+	if attempt.Failures < maxAttempts {
+		return
+	}
+
+	until := now.Add(loginLockoutDuration(attempt.Failures - maxAttempts))
+	if err := lockout.Lock(key, until); err != nil {
+		log.Error().Err(err).Str("scope", key.Scope).Msg("failed to lock login")
+		return
+	}
+
+	log.Warn().
+		Str("event", "login_lockout").
+		Str("scope", key.Scope).
+		Str("role", key.Role).
+		Str("identifier", key.Identifier).
+		Int32("failures", attempt.Failures).
+		Time("locked_until", until).
+		Msg("login locked after repeated failures")
+}
+
+// loginSucceeded clears the failures of the account. The failures of the
+// address are kept, otherwise logging into an account of their own would let
+// an attacker keep guessing others.
+func loginSucceeded(role, email string) {
+	if err := lockout.Reset(accountLoginKey(role, email)); err != nil {
+		log.Error().Err(err).Msg("failed to reset login failures")
+	}
+}
+
+func loginLockoutDuration(excess int32) time.Duration {
+	lockoutDuration := time.Duration(config.LoginLockout) * time.Second
+	if lockoutDuration <= 0 {
+		lockoutDuration = defaultLoginLockout
+	}
+	maxLockout := time.Duration(config.LoginMaxLockout) * time.Second
+	if maxLockout <= 0 {
+		maxLockout = defaultLoginMaxLockout
+	}
+
+	for i := int32(0); i < excess && lockoutDuration < maxLockout; i++ {
+		lockoutDuration *= 2
+	}
+	if lockoutDuration > maxLockout {
+		return maxLockout
+	}
+	return lockoutDuration
+}
+
+func loginMaxAttempts() int32 {
+	if config.LoginMaxAttempts <= 0 {
+		return defaultLoginMaxAttempts
+	}
+	return int32(config.LoginMaxAttempts)
+}
+
+func loginMaxIPAttempts() int32 {
+	if config.LoginMaxIPAttempts <= 0 {
+		return defaultLoginMaxIPAttempts
+	}
+	return int32(config.LoginMaxIPAttempts)
+}
+
+// PurgeStaleLoginAttempts forgets the failed logins that no longer count
+// towards a lockout.
+func PurgeStaleLoginAttempts() (int64, error) {
+	return lockout.Purge(time.Now().Add(-loginAttemptWindow))
+}
+
+func GetLockedLoginsByAdminPrincipal(principal *model.Principal) ([]*model.LoginAttempt, error) {
+	if err := checkAdminPrincipal(principal); err != nil {
+		return nil, err
+	}
+
+	attempts, err := lockout.FindLocked(time.Now())
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return attempts, nil
+}
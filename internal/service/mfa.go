@@ -0,0 +1,307 @@
+package service
+
+import (
+	"crypto/rand"
+	"strings"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/lockout"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/validator"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghijklmnopqrstuvwxyz234567"
+)
+
+// mfaMaxAttempts is how many wrong codes a MFA token accepts before it is
+// invalidated and the password has to be entered again.
+const mfaMaxAttempts = 3
+
+// EnrollTotpByPrincipal starts the TOTP enrollment of the professor, returning
+// the new secret and the otpauth:// URI to be shown as a QR code. TOTP is only
+// enabled once ConfirmTotpByPrincipal receives a valid code.
+func (s *Service) EnrollTotpByPrincipal(principal *model.Principal) (string, string, error) {
+	professor, err := professorFromPrincipal(principal)
+	if err != nil {
+		return "", "", err
+	}
+	if professor.TotpEnabledAt.Valid {
+		return "", "", &model.ConflictError{
+			Message: "TOTP is already enabled",
+		}
+	}
+
+	secret, err := generateTotpSecret()
+	if err != nil {
+		return "", "", &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	if err := s.professores.UpdateTotpSecret(professor.ID, secret); err != nil {
+		return "", "", &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return secret, totpURI(secret, professor.Email), nil
+}
+
+// ConfirmTotpByPrincipal enables TOTP once the professor proves their
+// authenticator app is set up, returning the recovery codes. The codes are
+// only stored hashed, so this is the only time they can be shown.
+func (s *Service) ConfirmTotpByPrincipal(principal *model.Principal, code string) ([]string, error) {
+	if err := validator.ValidateTotpCode(code); err != nil {
+		return nil, err
+	}
+
+	professor, err := professorFromPrincipal(principal)
+	if err != nil {
+		return nil, err
+	}
+	if professor.TotpEnabledAt.Valid {
+		return nil, &model.ConflictError{
+			Message: "TOTP is already enabled",
+		}
+	}
+	if !professor.TotpSecret.Valid {
+		return nil, &model.ConflictError{
+			Message: "TOTP enrollment was not started",
+		}
+	}
+
+	ok, err := s.useTotpCode(professor, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, invalidCodeError()
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	if err := s.professores.EnableTotp(professor.ID, hashes); err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return codes, nil
+}
+
+// DisableTotpByPrincipal turns TOTP off. It requires a valid TOTP or recovery
+// code so a stolen access token is not enough to remove the second factor.
+func (s *Service) DisableTotpByPrincipal(principal *model.Principal, code string) error {
+	if err := validator.ValidateTotpCode(code); err != nil {
+		return err
+	}
+
+	professor, err := professorFromPrincipal(principal)
+	if err != nil {
+		return err
+	}
+	if !professor.TotpEnabledAt.Valid {
+		return &model.ConflictError{
+			Message: "TOTP is not enabled",
+		}
+	}
+
+	ok, err := s.useSecondFactor(professor, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return invalidCodeError()
+	}
+
+	if err := s.professores.DisableTotp(professor.ID); err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// LoginMfa exchanges the MFA token returned by Login and a TOTP or recovery
+// code for the access and refresh tokens.
+func (s *Service) LoginMfa(mfaToken, code string, client *model.SessionClient) ([]string, error) {
+	if err := validator.ValidateMfaLogin(mfaToken, code); err != nil {
+		return nil, err
+	}
+
+	claims, err := s.getClaimsFromMfaToken(mfaToken)
+	if err != nil {
+		return nil, &model.JwtTokenError{
+			Message: err.Error(),
+		}
+	}
+
+	principal, err := s.loadPrincipal(claims)
+	if err != nil {
+		return nil, err
+	}
+	professor, err := professorFromPrincipal(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkLoginAllowed(model.RoleProfessor, professor.Email, client); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.useSecondFactor(professor, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, s.badSecondFactor(claims, professor.Email, client)
+	}
+
+	s.invalidateToken(claims.ID, claims.ExpiresAt.Time)
+	loginSucceeded(model.RoleProfessor, professor.Email)
+
+	tokens, err := s.generateTokens(professor.Email, model.RoleProfessor, professor.TokenVersion, client)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return tokens, nil
+}
+
+// badSecondFactor counts a wrong code like a wrong password, so guessing codes
+// locks the account too, and invalidates the MFA token once it has received
+// mfaMaxAttempts wrong codes.
+func (s *Service) badSecondFactor(claims *tokenClaims, email string, client *model.SessionClient) error {
+	now := time.Now()
+	recordLoginFailure(accountLoginKey(model.RoleProfessor, email), loginMaxAttempts(), now)
+	if client.IP != "" {
+		recordLoginFailure(ipLoginKey(client.IP), loginMaxIPAttempts(), now)
+	}
+
+	attempt, err := lockout.AddFailure(mfaTokenLoginKey(claims.ID), now, now.Add(-loginAttemptWindow))
+	if err != nil {
+		log.Error().Err(err).Msg("failed to record MFA failure")
+	}
+	if err != nil || attempt.Failures >= mfaMaxAttempts {
+		// When the failure cannot be counted the token is dropped as well,
+		// rather than allowing unlimited guesses
+		s.invalidateToken(claims.ID, claims.ExpiresAt.Time)
+	}
+
+	return &model.BadCredentialsError{
+		Message: "Invalid code",
+	}
+}
+
+// useSecondFactor accepts either a TOTP code or one of the recovery codes of
+// the professor.
+func (s *Service) useSecondFactor(professor *model.Professor, code string) (bool, error) {
+	if isTotpCode(code) {
+		return s.useTotpCode(professor, code)
+	}
+
+	ok, err := s.professores.UseRecoveryCode(professor.ID, hashOpaqueToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return false, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	return ok, nil
+}
+
+func (s *Service) useTotpCode(professor *model.Professor, code string) (bool, error) {
+	if !professor.TotpSecret.Valid {
+		return false, nil
+	}
+
+	step, ok := matchTotpCode(professor.TotpSecret.String, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	ok, err := s.professores.UseTotpStep(professor.ID, step)
+	if err != nil {
+		return false, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	return ok, nil
+}
+
+func isTotpCode(code string) bool {
+	if len(code) != totpDigits {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// generateRecoveryCodes returns the recovery codes, formatted as xxxxx-xxxxx,
+// along with the hashes to be stored.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		for j := range b {
+			b[j] = recoveryCodeAlphabet[int(b[j])%len(recoveryCodeAlphabet)]
+		}
+		code := string(b)
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+		hashes = append(hashes, hashOpaqueToken(code))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+func invalidCodeError() error {
+	validationErr := &model.ValidationError{}
+	validationErr.AddError("code", "is invalid")
+	return validationErr
+}
+
+// loginResult returns the tokens of a professor that passed the password
+// check, or the MFA token when a second factor is still required.
+func (s *Service) loginResult(professor *model.Professor, client *model.SessionClient) ([]string, string, error) {
+	if professor.TotpEnabledAt.Valid {
+		mfaToken, err := generateMfaToken(professor.Email, model.RoleProfessor, professor.TokenVersion)
+		if err != nil {
+			return nil, "", &model.ApplicationError{
+				Message: err.Error(),
+			}
+		}
+		return nil, mfaToken, nil
+	}
+
+	tokens, err := s.generateTokens(professor.Email, model.RoleProfessor, professor.TokenVersion, client)
+	if err != nil {
+		return nil, "", &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	return tokens, "", nil
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/database"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/oidc"
+	"github.com/cleysonph/hyperprof/internal/validator"
+)
+
+// oidcStateDuration is how long the user has to sign in with the provider
+// once the login has started.
+const oidcStateDuration = 10 * time.Minute
+
+// StartOidcLogin starts a login with providerName and returns the URL of the
+// provider the user must be sent to. The state, nonce and PKCE verifier are
+// kept until the login is completed by LoginOidc.
+func (s *Service) StartOidcLogin(providerName string) (string, error) {
+	provider, err := findOidcProvider(providerName)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := generateOpaqueToken()
+	if err != nil {
+		return "", &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	nonce, err := generateOpaqueToken()
+	if err != nil {
+		return "", &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	codeVerifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	authorizationURL, err := provider.AuthCodeURL(state, nonce, oidc.CodeChallenge(codeVerifier))
+	if err != nil {
+		return "", &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	err = s.tokens.CreateOidcState(&model.OidcState{
+		Provider:     provider.Name,
+		StateHash:    hashOpaqueToken(state),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    time.Now().Add(oidcStateDuration),
+	})
+	if err != nil {
+		return "", &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return authorizationURL, nil
+}
+
+// LoginOidc completes a login started by StartOidcLogin. The professor is
+// found through the identity linked to the provider account, or through the
+// email verified by the provider, and is created when there is none. Like
+// Login, only a MFA token is returned when the professor has TOTP enabled.
+func (s *Service) LoginOidc(code, state string, client *model.SessionClient) ([]string, string, error) {
+	if err := validator.ValidateOidcLogin(code, state); err != nil {
+		return nil, "", err
+	}
+
+	oidcState, err := s.tokens.UseOidcState(hashOpaqueToken(state))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "", &model.BadCredentialsError{
+				Message: "Invalid or expired login state",
+			}
+		}
+		return nil, "", &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	provider, err := findOidcProvider(oidcState.Provider)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rawIDToken, err := provider.Exchange(code, oidcState.CodeVerifier)
+	if err != nil {
+		return nil, "", &model.BadCredentialsError{
+			Message: err.Error(),
+		}
+	}
+
+	idToken, err := provider.VerifyIDToken(rawIDToken, oidcState.Nonce)
+	if err != nil {
+		return nil, "", &model.BadCredentialsError{
+			Message: err.Error(),
+		}
+	}
+
+	professor, err := s.findOrCreateOidcProfessor(provider.Name, idToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if professor.SuspendedAt.Valid {
+		return nil, "", &model.ForbiddenError{
+			Message: "Professor account is suspended",
+		}
+	}
+
+	return s.loginResult(professor, client)
+}
+
+func (s *Service) findOrCreateOidcProfessor(providerName string, idToken *oidc.IDToken) (*model.Professor, error) {
+	professor, err := s.professores.FindByIdentity(providerName, idToken.Subject)
+	if err == nil {
+		return professor, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	// Linking by email is only safe when the provider vouches for it
+	if idToken.Email == "" || !idToken.EmailVerified {
+		return nil, &model.BadCredentialsError{
+			Message: "The provider did not verify the email of this account",
+		}
+	}
+
+	identity := &model.ProfessorIdentity{
+		Provider: providerName,
+		Subject:  idToken.Subject,
+		Email:    idToken.Email,
+	}
+
+	// Professors signing in with a provider do not know this password, they
+	// can set one through the password reset
+	password, err := generateOpaqueToken()
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	professor, err = s.professores.FindByEmail(idToken.Email)
+	if err == nil {
+		identity.ProfessorID = professor.ID
+		if err := s.professores.LinkIdentity(identity, passwordHash); err != nil {
+			return nil, &model.ApplicationError{
+				Message: err.Error(),
+			}
+		}
+		// Linking verifies the email of professores who never did
+		s.reindexProfessor(professor.ID)
+		return s.findProfessorForLogin(professor.Email)
+	}
+	if err != sql.ErrNoRows {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	professor, err = s.professores.CreateWithIdentity(&model.Professor{
+		Nome:        oidcProfessorName(idToken),
+		Email:       idToken.Email,
+		DuracaoAula: model.DefaultDuracaoAula,
+		Password:    passwordHash,
+	}, identity)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	professor.Disciplinas = []*model.Disciplina{}
+	indexProfessor(professor)
+	return s.findProfessorForLogin(professor.Email)
+}
+
+// findProfessorForLogin loads the professor along with the fields needed to
+// issue their tokens.
+func (s *Service) findProfessorForLogin(email string) (*model.Professor, error) {
+	professor, err := s.professores.FindByEmail(email)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	return professor, nil
+}
+
+// oidcProfessorName falls back to the local part of the email when the
+// provider does not share the name of the user.
+func oidcProfessorName(idToken *oidc.IDToken) string {
+	name := strings.TrimSpace(idToken.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(idToken.Email, "@")
+	}
+	if len(name) > 100 {
+		name = strings.ToValidUTF8(name[:100], "")
+	}
+	return name
+}
+
+func findOidcProvider(name string) (*oidc.Provider, error) {
+	provider, ok := oidc.Lookup(name)
+	if !ok {
+		return nil, &model.OidcProviderNotFoundError{
+			Message: "Oidc provider " + name + " is not configured",
+		}
+	}
+	return provider, nil
+}
+
+// PurgeExpiredOidcStates forgets the logins that were never completed.
+func PurgeExpiredOidcStates() (int64, error) {
+	return database.DeleteExpiredOidcStates(time.Now())
+}
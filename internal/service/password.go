@@ -0,0 +1,158 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/cleysonph/hyperprof/config"
+	"github.com/cleysonph/hyperprof/internal/mailer"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/validator"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultResetDuration and defaultResetResendDelay are used when
+// RESET_DURATION and RESET_RESEND_DELAY are not configured.
+const (
+	defaultResetDuration    = time.Hour
+	defaultResetResendDelay = time.Minute
+)
+
+// ForgotPassword emails a single-use reset token to the professor registered
+// with email. Unknown or suspended accounts are silently ignored so the
+// endpoint cannot be used to find out which emails are registered. For the
+// same reason a professor only receives one email per RESET_RESEND_DELAY and
+// failures to send it are only logged.
+func (s *Service) ForgotPassword(email string) error {
+	if err := validator.ValidateEmail(email); err != nil {
+		return err
+	}
+
+	professor, err := s.professores.FindByEmail(email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	if professor.SuspendedAt.Valid {
+		return nil
+	}
+
+	if s.tokens.ExistsRecentPasswordReset(professor.ID, int64(resetResendDelay().Seconds())) {
+		return nil
+	}
+
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	reset := &model.PasswordReset{
+		ProfessorID: professor.ID,
+		TokenHash:   hashOpaqueToken(token),
+		ExpiresAt:   time.Now().Add(resetDuration()),
+	}
+	if err := s.tokens.CreatePasswordReset(reset); err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	err = mailer.Send(&mailer.Message{
+		To:      professor.Email,
+		Subject: "Redefinição de senha",
+		Body: fmt.Sprintf(
+			"Olá, %s.\n\nRecebemos um pedido para redefinir a sua senha. Use o link abaixo até %s:\n\n%s/redefinir-senha?token=%s\n\nSe você não fez este pedido, ignore este email.\n",
+			professor.Nome,
+			reset.ExpiresAt.In(config.Location).Format("02/01/2006 15:04"),
+			config.AppURL,
+			token,
+		),
+	})
+	if err != nil {
+		log.Error().Err(err).Int64("professor_id", professor.ID).Msg("failed to send password reset")
+	}
+
+	return nil
+}
+
+// ResetPassword replaces the password of the professor that requested token
+// and revokes every access and refresh token issued to them.
+func (s *Service) ResetPassword(token, password, passwordConfirmation string) error {
+	if err := validator.ValidatePasswordReset(token, password, passwordConfirmation); err != nil {
+		return err
+	}
+
+	reset, err := s.tokens.FindValidPasswordReset(hashOpaqueToken(token))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return invalidResetTokenError()
+		}
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	err = s.tokens.ResetPassword(reset, hash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return invalidResetTokenError()
+		}
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+func invalidResetTokenError() error {
+	validationErr := &model.ValidationError{}
+	validationErr.AddError("token", "is invalid or expired")
+	return validationErr
+}
+
+func resetDuration() time.Duration {
+	if config.ResetDuration <= 0 {
+		return defaultResetDuration
+	}
+	return time.Duration(config.ResetDuration) * time.Second
+}
+
+func resetResendDelay() time.Duration {
+	if config.ResetResendDelay <= 0 {
+		return defaultResetResendDelay
+	}
+	return time.Duration(config.ResetResendDelay) * time.Second
+}
+
+// generateOpaqueToken returns a random token meant to be sent to the user.
+// Only its hash is stored.
+func generateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"database/sql"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/rs/zerolog/log"
+)
+
+// useRefreshToken rotates the refresh token described by claims. Presenting a
+// token that was already rotated means it leaked, since only its holder should
+// have the newer one, so the whole family and its session are revoked and
+// both parties have to log in again.
+func (s *Service) useRefreshToken(claims *tokenClaims) (*model.RefreshToken, error) {
+	refreshToken, err := s.tokens.FindRefreshTokenByJTI(claims.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &model.JwtTokenError{
+				Message: "Invalid refresh token",
+			}
+		}
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	if refreshToken.RevokedAt.Valid {
+		return nil, &model.JwtTokenError{
+			Message: "Refresh token has been revoked",
+		}
+	}
+
+	rotated, err := s.tokens.RotateRefreshToken(refreshToken.JTI)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	if !rotated {
+		if err := s.tokens.RevokeSession(refreshToken.FamilyID); err != nil {
+			return nil, &model.ApplicationError{
+				Message: err.Error(),
+			}
+		}
+
+		log.Warn().
+			Str("event", "refresh_token_reuse").
+			Str("jti", refreshToken.JTI).
+			Str("family_id", refreshToken.FamilyID).
+			Str("subject", refreshToken.Subject).
+			Str("role", refreshToken.Role).
+			Msg("Refresh token reuse detected, session revoked")
+
+		return nil, &model.JwtTokenError{
+			Message: "Refresh token has already been used",
+		}
+	}
+
+	return refreshToken, nil
+}
+
+// revokeRefreshToken ends the session the refresh token described by claims
+// belongs to.
+func (s *Service) revokeRefreshToken(claims *tokenClaims) error {
+	refreshToken, err := s.tokens.FindRefreshTokenByJTI(claims.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &model.JwtTokenError{
+				Message: "Invalid refresh token",
+			}
+		}
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	if err := s.tokens.RevokeSession(refreshToken.FamilyID); err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
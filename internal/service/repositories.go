@@ -0,0 +1,152 @@
+package service
+
+import (
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/validator"
+)
+
+// ProfessorRepository keeps the professores, along with the subjects they
+// teach and their availability. Lookups of a single professor return
+// sql.ErrNoRows when there is none.
+type ProfessorRepository interface {
+	validator.Agenda
+	// FindAll returns the requested page of the professores visible in the
+	// catalogue that match filter, along with how many match it in total.
+	FindAll(filter *model.ProfessorFilter) ([]*model.Professor, int64, error)
+	// FindActiveByID ignores the professores hidden from the public.
+	FindActiveByID(id int64) (*model.Professor, error)
+	// FindByID returns the professor regardless of the account state.
+	FindByID(id int64) (*model.Professor, error)
+	// FindAllForAdmin returns every professor, including the ones hidden
+	// from the public, oldest first.
+	FindAllForAdmin() ([]*model.Professor, error)
+	// FindByEmail also returns the credentials of the professor.
+	FindByEmail(email string) (*model.Professor, error)
+	ExistsByEmail(email string) bool
+	ExistsByEmailAndNotID(email string, id int64) bool
+	Create(professor *model.Professor) (*model.Professor, error)
+	Update(professor *model.Professor) (*model.Professor, error)
+	UpdateFotoPerfil(id int64, fotoPerfil string) error
+	Suspend(id int64) error
+	Reactivate(id int64) error
+	// Delete removes the professor and all of their bookings.
+	Delete(id int64) error
+	// FindDisciplinaIDs returns the ids of the subjects taught by each of the
+	// professores.
+	FindDisciplinaIDs(professorIDs []int64) (map[int64][]int64, error)
+	// FindByIdentity returns the professor linked to the account subject of
+	// provider, along with their credentials.
+	FindByIdentity(provider, subject string) (*model.Professor, error)
+	// LinkIdentity links identity to an existing professor. When the
+	// professor never verified their email, it is marked as verified, their
+	// password is replaced by passwordHash and their tokens are revoked.
+	LinkIdentity(identity *model.ProfessorIdentity, passwordHash string) error
+	// CreateWithIdentity creates a professor whose email was verified by the
+	// provider of identity and links them together.
+	CreateWithIdentity(professor *model.Professor, identity *model.ProfessorIdentity) (*model.Professor, error)
+	// UpdateTotpSecret stores a secret that is pending confirmation. It does
+	// nothing when the professor already has TOTP enabled.
+	UpdateTotpSecret(id int64, secret string) error
+	// EnableTotp turns on TOTP for the professor and replaces their recovery
+	// codes by the ones in codeHashes.
+	EnableTotp(id int64, codeHashes []string) error
+	// DisableTotp turns off TOTP for the professor and drops their recovery
+	// codes.
+	DisableTotp(id int64) error
+	// UseTotpStep records step as the last time step a code was accepted
+	// for. It reports false when a code for step, or a later one, was
+	// already used.
+	UseTotpStep(id int64, step int64) (bool, error)
+	// UseRecoveryCode consumes the recovery code identified by codeHash. It
+	// reports false when no unused code matches.
+	UseRecoveryCode(id int64, codeHash string) (bool, error)
+}
+
+// AlunoRepository keeps the lessons booked with the professores, along with
+// their history, and the accounts of the students who booked them. Lookups of
+// a single lesson or student return sql.ErrNoRows when there is none.
+type AlunoRepository interface {
+	// Create rejects lessons overlapping another active booking of the same
+	// professor with database.ErrAulaConflict.
+	Create(aluno *model.Aluno) (*model.Aluno, error)
+	FindByID(id int64) (*model.Aluno, error)
+	FindByProfessorID(professorID int64, status string) ([]*model.Aluno, error)
+	// FindByEstudanteID returns the lessons linked to the student, each along
+	// with its professor, latest first.
+	FindByEstudanteID(estudanteID int64, status string) ([]*model.Aluno, error)
+	// UpdateAgenda persists a status transition of the lesson together with
+	// historico. It returns database.ErrAulaChanged when the lesson no longer
+	// has the previous status and date of historico. Lessons that stay active
+	// are rejected with database.ErrAulaConflict when they overlap another
+	// booking.
+	UpdateAgenda(aluno *model.Aluno, historico *model.AlunoHistorico) (*model.Aluno, error)
+	// FindHistorico returns the history of the lesson, oldest first.
+	FindHistorico(alunoID int64) ([]*model.AlunoHistorico, error)
+	CreateEstudante(estudante *model.Estudante) (*model.Estudante, error)
+	// FindEstudanteByEmail also returns the credentials of the student.
+	FindEstudanteByEmail(email string) (*model.Estudante, error)
+}
+
+// TokenRepository keeps what the issued tokens are checked against: the
+// sessions, the refresh tokens of each session, the access tokens revoked
+// before they expire, the email verification and password reset links, the
+// logins started with an OIDC provider and the accounts of the
+// administradores, who are only known through their tokens. Lookups of a
+// single token return sql.ErrNoRows for unknown, used or expired tokens.
+type TokenRepository interface {
+	CreateSession(session *model.Session) error
+	FindSession(id string) (*model.Session, error)
+	ExistsActiveSession(id string) bool
+	// FindActiveSessions returns the sessions of the account that were
+	// neither revoked nor expired, most recently used first.
+	FindActiveSessions(subject, role string) ([]*model.Session, error)
+	TouchSession(id string, client *model.SessionClient, expiresAt time.Time) error
+	// RevokeSession revokes the session and every refresh token of its
+	// family.
+	RevokeSession(id string) error
+	// RevokeOtherSessions revokes every session of the account except the
+	// one identified by currentID, along with their refresh tokens.
+	RevokeOtherSessions(subject, role, currentID string) error
+	CreateRefreshToken(refreshToken *model.RefreshToken) error
+	FindRefreshTokenByJTI(jti string) (*model.RefreshToken, error)
+	// RotateRefreshToken reports false when the token was already rotated or
+	// revoked.
+	RotateRefreshToken(jti string) (bool, error)
+	CreateInvalidatedToken(jti string, expiresAt time.Time) error
+	ExistsInvalidatedToken(jti string) bool
+	CreateEmailVerification(verification *model.EmailVerification) error
+	FindValidEmailVerification(tokenHash string) (*model.EmailVerification, error)
+	// ExistsRecentEmailVerification reports whether a verification was sent
+	// to the professor in the last seconds.
+	ExistsRecentEmailVerification(professorID int64, seconds int64) bool
+	// VerifyProfessorEmail consumes verification and marks the email of its
+	// professor as verified, invalidating any other pending verification. It
+	// returns sql.ErrNoRows when verification was used concurrently.
+	VerifyProfessorEmail(verification *model.EmailVerification) error
+	CreateEstudanteEmailVerification(verification *model.EstudanteEmailVerification) error
+	FindValidEstudanteEmailVerification(tokenHash string) (*model.EstudanteEmailVerification, error)
+	ExistsRecentEstudanteEmailVerification(estudanteID int64, seconds int64) bool
+	// VerifyEstudanteEmail works like VerifyProfessorEmail and also links
+	// every booking made with the email to the student.
+	VerifyEstudanteEmail(verification *model.EstudanteEmailVerification) error
+	CreatePasswordReset(reset *model.PasswordReset) error
+	// ExistsRecentPasswordReset reports whether a reset was sent to the
+	// professor in the last seconds.
+	ExistsRecentPasswordReset(professorID int64, seconds int64) bool
+	FindValidPasswordReset(tokenHash string) (*model.PasswordReset, error)
+	// ResetPassword consumes reset and every other pending reset of the
+	// professor, stores passwordHash and revokes the tokens issued to them.
+	// It returns sql.ErrNoRows when reset was used concurrently.
+	ResetPassword(reset *model.PasswordReset, passwordHash string) error
+	CreateOidcState(state *model.OidcState) error
+	// UseOidcState marks the state as used, so every login can only be
+	// completed once. It returns sql.ErrNoRows when the state is unknown,
+	// used or expired.
+	UseOidcState(stateHash string) (*model.OidcState, error)
+	CreateAdministrador(administrador *model.Administrador) (*model.Administrador, error)
+	// FindAdministradorByEmail also returns the credentials of the
+	// administrador.
+	FindAdministradorByEmail(email string) (*model.Administrador, error)
+}
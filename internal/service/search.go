@@ -0,0 +1,125 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/cleysonph/hyperprof/internal/database"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/search"
+	"github.com/rs/zerolog/log"
+)
+
+// maxSearchHits bounds how many professores a search query can match, past
+// the most relevant ones the results are not worth paging through. Only the
+// professores visible to the public are searchable, so the hidden ones never
+// take the place of a visible one within the bound.
+const maxSearchHits = 1000
+
+// searchProfessorIDs returns the ids of the professores matching q, best
+// match first.
+func searchProfessorIDs(q string) ([]int64, error) {
+	hits, err := search.Search(q, maxSearchHits)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	return ids, nil
+}
+
+// highlightProfessor fills the highlights of the fields of professor that
+// match q.
+func highlightProfessor(professor *model.Professor, q string) {
+	highlights := map[string]string{}
+	if fragment := search.Highlight(professor.Nome, q); fragment != "" {
+		highlights["nome"] = fragment
+	}
+	if fragment := search.Highlight(professor.Descricao, q); fragment != "" {
+		highlights["descricao"] = fragment
+	}
+	caminhos := make([]string, len(professor.Disciplinas))
+	for i, disciplina := range professor.Disciplinas {
+		caminhos[i] = strings.Join(disciplina.Caminho, caminhoSeparator)
+	}
+	if fragment := search.Highlight(strings.Join(caminhos, ", "), q); fragment != "" {
+		highlights["disciplinas"] = fragment
+	}
+	if len(highlights) > 0 {
+		professor.Highlights = highlights
+	}
+}
+
+func professorDocument(professor *model.Professor) *search.Document {
+	return &search.Document{
+		ID:          professor.ID,
+		Nome:        professor.Nome,
+		Descricao:   professor.Descricao,
+		Disciplinas: disciplinaNames(professor.Disciplinas),
+	}
+}
+
+// isSearchable reports whether the professor is visible to the public, the
+// others are left out of the index.
+func isSearchable(professor *model.Professor) bool {
+	return !professor.SuspendedAt.Valid && professor.EmailVerifiedAt.Valid
+}
+
+// indexProfessor makes the changes to professor searchable, its subjects
+// must be loaded. The professor is already saved at this point and the index
+// is rebuilt periodically, so a failure is only logged.
+func indexProfessor(professor *model.Professor) {
+	if !isSearchable(professor) {
+		unindexProfessor(professor.ID)
+		return
+	}
+	if err := search.Index(professorDocument(professor)); err != nil {
+		log.Error().Err(err).Int64("professor_id", professor.ID).Msg("failed to index professor")
+	}
+}
+
+func unindexProfessor(professorID int64) {
+	if err := search.Remove(professorID); err != nil {
+		log.Error().Err(err).Int64("professor_id", professorID).Msg("failed to unindex professor")
+	}
+}
+
+// reindexProfessor indexes the professor again once they became visible to
+// the public or were hidden from it.
+func (s *Service) reindexProfessor(professorID int64) {
+	professor, err := s.findAnyProfessorByID(professorID)
+	if err != nil {
+		log.Error().Err(err).Int64("professor_id", professorID).Msg("failed to index professor")
+		return
+	}
+	indexProfessor(professor)
+}
+
+// RebuildSearchIndex indexes every professor visible to the public again,
+// returning how many were indexed.
+func RebuildSearchIndex() (int64, error) {
+	professores, err := database.FindAllProfessoresForAdmin()
+	if err != nil {
+		return 0, err
+	}
+	disciplinaIDs, err := database.FindAllProfessorDisciplinaIDs()
+	if err != nil {
+		return 0, err
+	}
+	if err := attachDisciplinas(professores, disciplinaIDs); err != nil {
+		return 0, err
+	}
+
+	docs := make([]*search.Document, 0, len(professores))
+	for _, professor := range professores {
+		if isSearchable(professor) {
+			docs = append(docs, professorDocument(professor))
+		}
+	}
+	if err := search.Rebuild(docs); err != nil {
+		return 0, err
+	}
+	return int64(len(docs)), nil
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"mime/multipart"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/database"
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+// Service runs the use cases against the repositories it is given, so they
+// can be exercised without MySQL.
+type Service struct {
+	professores ProfessorRepository
+	alunos      AlunoRepository
+	tokens      TokenRepository
+}
+
+func New(professores ProfessorRepository, alunos AlunoRepository, tokens TokenRepository) *Service {
+	return &Service{
+		professores: professores,
+		alunos:      alunos,
+		tokens:      tokens,
+	}
+}
+
+var std = New(database.ProfessorRepository{}, database.AlunoRepository{}, database.TokenRepository{})
+
+// Init sets the service used by the package functions.
+func Init(s *Service) {
+	std = s
+}
+
+func FindAllProfessores(filter *model.ProfessorFilter) (*model.Page[*model.Professor], error) {
+	return std.FindAllProfessores(filter)
+}
+
+func FindProfessorByID(professorID int64) (*model.Professor, error) {
+	return std.FindProfessorByID(professorID)
+}
+
+func GetProfessorByPrincipal(principal *model.Principal) (*model.Professor, error) {
+	return std.GetProfessorByPrincipal(principal)
+}
+
+func CreateProfessor(professor *model.Professor, passwordConfirmation string) (*model.Professor, error) {
+	return std.CreateProfessor(professor, passwordConfirmation)
+}
+
+func UpdateProfessorByPrincipal(principal *model.Principal, professorData *model.Professor, passwordConfirmation string) (*model.Professor, error) {
+	return std.UpdateProfessorByPrincipal(principal, professorData, passwordConfirmation)
+}
+
+func UpdateProfessorFotoByPrincipal(principal *model.Principal, file multipart.File, fileHeader *multipart.FileHeader) error {
+	return std.UpdateProfessorFotoByPrincipal(principal, file, fileHeader)
+}
+
+func DeleteProfessorByPrincipal(principal *model.Principal) error {
+	return std.DeleteProfessorByPrincipal(principal)
+}
+
+func CreateAluno(aluno *model.Aluno) (*model.Aluno, error) {
+	return std.CreateAluno(aluno)
+}
+
+func GetAlunosByPrincipal(principal *model.Principal, status string) ([]*model.Aluno, error) {
+	return std.GetAlunosByPrincipal(principal, status)
+}
+
+func Login(email, password string, client *model.SessionClient) ([]string, string, error) {
+	return std.Login(email, password, client)
+}
+
+func Refresh(refreshToken string, client *model.SessionClient) ([]string, error) {
+	return std.Refresh(refreshToken, client)
+}
+
+func Logout(principal *model.Principal, refreshToken string) error {
+	return std.Logout(principal, refreshToken)
+}
+
+func Authenticate(token string) (*model.Principal, error) {
+	return std.Authenticate(token)
+}
+
+func StartOidcLogin(providerName string) (string, error) {
+	return std.StartOidcLogin(providerName)
+}
+
+func LoginOidc(code, state string, client *model.SessionClient) ([]string, string, error) {
+	return std.LoginOidc(code, state, client)
+}
+
+func EnrollTotpByPrincipal(principal *model.Principal) (string, string, error) {
+	return std.EnrollTotpByPrincipal(principal)
+}
+
+func ConfirmTotpByPrincipal(principal *model.Principal, code string) ([]string, error) {
+	return std.ConfirmTotpByPrincipal(principal, code)
+}
+
+func DisableTotpByPrincipal(principal *model.Principal, code string) error {
+	return std.DisableTotpByPrincipal(principal, code)
+}
+
+func LoginMfa(mfaToken, code string, client *model.SessionClient) ([]string, error) {
+	return std.LoginMfa(mfaToken, code, client)
+}
+
+func GetSessionsByPrincipal(principal *model.Principal) ([]*model.Session, error) {
+	return std.GetSessionsByPrincipal(principal)
+}
+
+func RevokeSessionByPrincipal(principal *model.Principal, sessionID string) error {
+	return std.RevokeSessionByPrincipal(principal, sessionID)
+}
+
+func RevokeOtherSessionsByPrincipal(principal *model.Principal) error {
+	return std.RevokeOtherSessionsByPrincipal(principal)
+}
+
+func CreateEstudante(estudante *model.Estudante, passwordConfirmation string) (*model.Estudante, error) {
+	return std.CreateEstudante(estudante, passwordConfirmation)
+}
+
+func GetEstudanteByPrincipal(principal *model.Principal) (*model.Estudante, error) {
+	return std.GetEstudanteByPrincipal(principal)
+}
+
+func GetAulasByPrincipal(principal *model.Principal, status string) ([]*model.Aluno, error) {
+	return std.GetAulasByPrincipal(principal, status)
+}
+
+func LoginEstudante(email, password string, client *model.SessionClient) ([]string, error) {
+	return std.LoginEstudante(email, password, client)
+}
+
+func CreateAdministrador(administrador *model.Administrador, passwordConfirmation string) (*model.Administrador, error) {
+	return std.CreateAdministrador(administrador, passwordConfirmation)
+}
+
+func LoginAdministrador(email, password string, client *model.SessionClient) ([]string, error) {
+	return std.LoginAdministrador(email, password, client)
+}
+
+func FindAllProfessoresByAdminPrincipal(principal *model.Principal) ([]*model.Professor, error) {
+	return std.FindAllProfessoresByAdminPrincipal(principal)
+}
+
+func SuspendProfessorByAdminPrincipal(principal *model.Principal, professorID int64) (*model.Professor, error) {
+	return std.SuspendProfessorByAdminPrincipal(principal, professorID)
+}
+
+func ReactivateProfessorByAdminPrincipal(principal *model.Principal, professorID int64) (*model.Professor, error) {
+	return std.ReactivateProfessorByAdminPrincipal(principal, professorID)
+}
+
+func DeleteProfessorByAdminPrincipal(principal *model.Principal, professorID int64) error {
+	return std.DeleteProfessorByAdminPrincipal(principal, professorID)
+}
+
+func CancelAlunoByPrincipal(principal *model.Principal, alunoID int64, motivo string) (*model.Aluno, error) {
+	return std.CancelAlunoByPrincipal(principal, alunoID, motivo)
+}
+
+func RescheduleAlunoByPrincipal(principal *model.Principal, alunoID int64, dataAula time.Time, motivo string) (*model.Aluno, error) {
+	return std.RescheduleAlunoByPrincipal(principal, alunoID, dataAula, motivo)
+}
+
+func CompleteAlunoByPrincipal(principal *model.Principal, alunoID int64) (*model.Aluno, error) {
+	return std.CompleteAlunoByPrincipal(principal, alunoID)
+}
+
+func GetAlunoHistoricoByPrincipal(principal *model.Principal, alunoID int64) ([]*model.AlunoHistorico, error) {
+	return std.GetAlunoHistoricoByPrincipal(principal, alunoID)
+}
+
+func GetAlunoBySignature(alunoID int64, signature string) (*model.Aluno, error) {
+	return std.GetAlunoBySignature(alunoID, signature)
+}
+
+func CancelAlunoBySignature(alunoID int64, signature string, motivo string) (*model.Aluno, error) {
+	return std.CancelAlunoBySignature(alunoID, signature, motivo)
+}
+
+func RescheduleAlunoBySignature(alunoID int64, signature string, dataAula time.Time, motivo string) (*model.Aluno, error) {
+	return std.RescheduleAlunoBySignature(alunoID, signature, dataAula, motivo)
+}
+
+func GetAlunoHistoricoBySignature(alunoID int64, signature string) ([]*model.AlunoHistorico, error) {
+	return std.GetAlunoHistoricoBySignature(alunoID, signature)
+}
+
+func VerifyEmail(token string) error {
+	return std.VerifyEmail(token)
+}
+
+func ResendEmailVerification(email string) error {
+	return std.ResendEmailVerification(email)
+}
+
+func VerifyEstudanteEmail(token string) error {
+	return std.VerifyEstudanteEmail(token)
+}
+
+func ResendEstudanteEmailVerification(email string) error {
+	return std.ResendEstudanteEmailVerification(email)
+}
+
+func ForgotPassword(email string) error {
+	return std.ForgotPassword(email)
+}
+
+func ResetPassword(token, password, passwordConfirmation string) error {
+	return std.ResetPassword(token, password, passwordConfirmation)
+}
+
+func CreateAvaliacaoBySignature(alunoID int64, signature string, avaliacao *model.Avaliacao) (*model.Avaliacao, error) {
+	return std.CreateAvaliacaoBySignature(alunoID, signature, avaliacao)
+}
+
+func CreateAvaliacaoByEstudantePrincipal(principal *model.Principal, alunoID int64, avaliacao *model.Avaliacao) (*model.Avaliacao, error) {
+	return std.CreateAvaliacaoByEstudantePrincipal(principal, alunoID, avaliacao)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+func (s *Service) GetSessionsByPrincipal(principal *model.Principal) ([]*model.Session, error) {
+	if _, err := professorFromPrincipal(principal); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.tokens.FindActiveSessions(principal.Subject, principal.Role)
+	if err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID == principal.SessionID
+	}
+	return sessions, nil
+}
+
+func (s *Service) RevokeSessionByPrincipal(principal *model.Principal, sessionID string) error {
+	if _, err := professorFromPrincipal(principal); err != nil {
+		return err
+	}
+
+	session, err := s.tokens.FindSession(sessionID)
+	if err != nil && err != sql.ErrNoRows {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	if err == sql.ErrNoRows || session.Subject != principal.Subject || session.Role != principal.Role {
+		return &model.SessionNotFoundError{
+			Message: fmt.Sprintf("Session with ID %s not found", sessionID),
+		}
+	}
+
+	if err := s.tokens.RevokeSession(session.ID); err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// RevokeOtherSessionsByPrincipal logs the professor out of every device but
+// the one making the request.
+func (s *Service) RevokeOtherSessionsByPrincipal(principal *model.Principal) error {
+	if _, err := professorFromPrincipal(principal); err != nil {
+		return err
+	}
+
+	if err := s.tokens.RevokeOtherSessions(principal.Subject, principal.Role, principal.SessionID); err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/cleysonph/hyperprof/config"
+)
+
+// signAluno returns the signature that authorizes the holder of a manage link
+// to act on the given booking.
+func signAluno(alunoID int64) string {
+	mac := hmac.New(sha256.New, []byte(config.ManageSecret))
+	mac.Write([]byte("aluno:" + strconv.FormatInt(alunoID, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func checkAlunoSignature(alunoID int64, signature string) bool {
+	expected, err := hex.DecodeString(signAluno(alunoID))
+	if err != nil {
+		return false
+	}
+	actual, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, actual)
+}
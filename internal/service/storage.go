@@ -2,35 +2,24 @@ package service
 
 import (
 	"fmt"
-	"io"
-	"mime/multipart"
-	"os"
-	"path"
 
-	"github.com/cleysonph/hyperprof/config"
-	"github.com/google/uuid"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/storage"
 )
 
-func uploadFile(file multipart.File, fileHeader *multipart.FileHeader) (string, error) {
-	err := os.MkdirAll("uploads", os.ModePerm)
-	if err != nil {
-		return "", err
+// GetArquivo returns a stored file, for the clients that cannot reach the
+// storage themselves. The caller must close its body.
+func GetArquivo(key string) (*storage.Object, error) {
+	object, err := storage.Get(key)
+	if err == storage.ErrNotFound || err == storage.ErrInvalidKey {
+		return nil, &model.ArquivoNotFoundError{
+			Message: fmt.Sprintf("Arquivo %s not found", key),
+		}
 	}
-
-	filename := fmt.Sprintf("%s-%s", uuid.New().String(), fileHeader.Filename)
-	filepath := path.Join("uploads", filename)
-	tempFile, err := os.Create(filepath)
 	if err != nil {
-		return "", err
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
 	}
-	defer tempFile.Close()
-
-	fileBytes, err := io.ReadAll(file)
-	if err != nil {
-		return "", err
-	}
-
-	tempFile.Write(fileBytes)
-
-	return fmt.Sprintf("http://%s/%s", config.Addr(), filepath), nil
+	return object, nil
 }
@@ -8,20 +8,80 @@ import (
 	"github.com/cleysonph/hyperprof/internal/database"
 	"github.com/cleysonph/hyperprof/internal/model"
 	"github.com/cleysonph/hyperprof/internal/validator"
+	"github.com/rs/zerolog/log"
 )
 
-func FindAllProfessores(q string) ([]*model.Professor, error) {
-	professores, err := database.FindAllProfessores(q)
+// defaultPageLimit is the size of a page when the client does not ask for
+// one.
+const defaultPageLimit = 20
+
+func (s *Service) FindAllProfessores(filter *model.ProfessorFilter) (*model.Page[*model.Professor], error) {
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageLimit
+	}
+	if filter.Sort == "" && filter.Q != "" {
+		filter.Sort = model.ProfessorSortRelevance
+	} else if filter.Sort == "" {
+		filter.Sort = model.ProfessorSortOldest
+	}
+
+	if err := validator.ValidateProfessorFilter(filter); err != nil {
+		return nil, err
+	}
+
+	if filter.Subject > 0 {
+		disciplinaIDs, err := subjectFilterIDs(filter.Subject)
+		if err != nil {
+			return nil, err
+		}
+		filter.DisciplinaIDs = disciplinaIDs
+	}
+
+	if filter.Q != "" {
+		ids, err := searchProfessorIDs(filter.Q)
+		if err != nil {
+			return nil, &model.ApplicationError{
+				Message: err.Error(),
+			}
+		}
+		if len(ids) == 0 {
+			return &model.Page[*model.Professor]{
+				Data:       []*model.Professor{},
+				Pagination: model.NewPagination(filter.Page, filter.Limit, 0),
+			}, nil
+		}
+		filter.IDs = ids
+	}
+
+	professores, total, err := s.professores.FindAll(filter)
 	if err != nil {
 		return nil, &model.ApplicationError{
 			Message: err.Error(),
 		}
 	}
-	return professores, nil
+	if err := s.loadProfessorDetails(professores...); err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	if filter.Q != "" {
+		for _, professor := range professores {
+			highlightProfessor(professor, filter.Q)
+		}
+	}
+
+	return &model.Page[*model.Professor]{
+		Data:       professores,
+		Pagination: model.NewPagination(filter.Page, filter.Limit, total),
+	}, nil
 }
 
-func FindProfessorByID(professorID int64) (*model.Professor, error) {
-	professor, err := database.FindProfessorByID(professorID)
+func (s *Service) FindProfessorByID(professorID int64) (*model.Professor, error) {
+	professor, err := s.professores.FindActiveByID(professorID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, &model.ProfessorNotFoundError{
@@ -32,34 +92,44 @@ func FindProfessorByID(professorID int64) (*model.Professor, error) {
 			Message: err.Error(),
 		}
 	}
+	if err := s.loadProfessorDetails(professor); err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
 	return professor, nil
 }
 
-func GetProfessorByToken(token string) (*model.Professor, error) {
-	email, err := getSubFromAccessToken(token)
-	if err != nil {
-		return nil, &model.JwtTokenError{
-			Message: err.Error(),
-		}
+// loadProfessorDetails fills what the professores returned by the API carry
+// beyond their own row.
+func (s *Service) loadProfessorDetails(professores ...*model.Professor) error {
+	if err := s.loadDisciplinas(professores...); err != nil {
+		return err
 	}
+	resolveFotos(professores...)
+	return nil
+}
 
-	professor, err := database.FindProfessorByEmail(email)
+func (s *Service) GetProfessorByPrincipal(principal *model.Principal) (*model.Professor, error) {
+	professor, err := professorFromPrincipal(principal)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, &model.JwtTokenError{
-				Message: fmt.Sprintf("Professor with email %s not found", email),
-			}
-		}
+		return nil, err
+	}
+	if err := s.loadProfessorDetails(professor); err != nil {
 		return nil, &model.ApplicationError{
 			Message: err.Error(),
 		}
 	}
-
 	return professor, nil
 }
 
-func CreateProfessor(professor *model.Professor, passwordConfirmation string) (*model.Professor, error) {
-	if err := validator.ValidateProfessor(professor, passwordConfirmation); err != nil {
+func (s *Service) CreateProfessor(professor *model.Professor, passwordConfirmation string) (*model.Professor, error) {
+	if professor.DuracaoAula == 0 {
+		professor.DuracaoAula = model.DefaultDuracaoAula
+	}
+
+	emailTaken := s.professores.ExistsByEmail(professor.Email)
+	if err := validator.ValidateProfessor(professor, passwordConfirmation, emailTaken); err != nil {
 		return nil, err
 	}
 
@@ -71,38 +141,37 @@ func CreateProfessor(professor *model.Professor, passwordConfirmation string) (*
 	}
 
 	professor.Password = hash
-	professor, err = database.CreateProfessor(professor)
+	professor, err = s.professores.Create(professor)
 	if err != nil {
 		return nil, &model.ApplicationError{
 			Message: err.Error(),
 		}
 	}
+	professor.Disciplinas = []*model.Disciplina{}
+	professor.Fotos = []*model.FotoVariante{}
+	indexProfessor(professor)
+
+	// The account is already created at this point, a failure here can be
+	// recovered through ResendEmailVerification.
+	if err := s.sendEmailVerification(professor); err != nil {
+		log.Error().Err(err).Int64("professor_id", professor.ID).Msg("failed to send email verification")
+	}
 
 	return professor, nil
 }
 
-func UpdateProfessorByToken(token string, professorData *model.Professor, passwordConfirmation string) (*model.Professor, error) {
-	professorEmail, err := getSubFromAccessToken(token)
-	if err != nil {
-		return nil, &model.JwtTokenError{
-			Message: err.Error(),
-		}
-	}
-
-	professor, err := database.FindProfessorByEmail(professorEmail)
+func (s *Service) UpdateProfessorByPrincipal(principal *model.Principal, professorData *model.Professor, passwordConfirmation string) (*model.Professor, error) {
+	professor, err := professorFromPrincipal(principal)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, &model.JwtTokenError{
-				Message: fmt.Sprintf("Professor with email %s not found", professorEmail),
-			}
-		}
-		return nil, &model.ApplicationError{
-			Message: err.Error(),
-		}
+		return nil, err
 	}
 
 	professorData.ID = professor.ID
-	if err := validator.ValidateProfessor(professorData, passwordConfirmation); err != nil {
+	if professorData.DuracaoAula == 0 {
+		professorData.DuracaoAula = professor.DuracaoAula
+	}
+	emailTaken := s.professores.ExistsByEmailAndNotID(professorData.Email, professorData.ID)
+	if err := validator.ValidateProfessor(professorData, passwordConfirmation, emailTaken); err != nil {
 		return nil, err
 	}
 
@@ -114,100 +183,112 @@ func UpdateProfessorByToken(token string, professorData *model.Professor, passwo
 	}
 	professorData.Password = hash
 
-	professor, err = database.UpdateProfessor(professorData)
+	professor, err = s.professores.Update(professorData)
 	if err != nil {
 		return nil, &model.ApplicationError{
 			Message: err.Error(),
 		}
 	}
+	if err := s.loadProfessorDetails(professor); err != nil {
+		return nil, &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	indexProfessor(professor)
 
 	return professor, nil
 }
 
-func UpdateProfessorFotoByToken(token string, file multipart.File, fileHeader *multipart.FileHeader) error {
+func (s *Service) UpdateProfessorFotoByPrincipal(principal *model.Principal, file multipart.File, fileHeader *multipart.FileHeader) error {
 	if err := validator.ValidateProfessorFoto(fileHeader); err != nil {
 		return err
 	}
 
-	professorEmail, err := getSubFromAccessToken(token)
+	professor, err := professorFromPrincipal(principal)
 	if err != nil {
-		return &model.JwtTokenError{
-			Message: err.Error(),
-		}
+		return err
 	}
 
-	filepath, err := uploadFile(file, fileHeader)
+	dir, err := processFoto(file)
 	if err != nil {
-		return &model.ApplicationError{
-			Message: err.Error(),
-		}
+		return err
 	}
 
-	err = database.UpdateProfessorFotoPerfilByEmail(professorEmail, filepath)
+	err = s.professores.UpdateFotoPerfil(professor.ID, dir)
 	if err != nil {
+		deleteFoto(dir)
 		return &model.ApplicationError{
 			Message: err.Error(),
 		}
 	}
+	deleteFoto(professor.FotoPerfil.String)
 
 	return nil
 }
 
-func DeleteProfessorByToken(token string) error {
-	professorEmail, err := getSubFromAccessToken(token)
+func (s *Service) DeleteProfessorByPrincipal(principal *model.Principal) error {
+	professor, err := professorFromPrincipal(principal)
 	if err != nil {
-		return &model.JwtTokenError{
-			Message: err.Error(),
-		}
-	}
-
-	err = database.DeleteAlunosByProfessorEmail(professorEmail)
-	if err != nil {
-		return &model.ApplicationError{
-			Message: err.Error(),
-		}
+		return err
 	}
 
-	err = database.DeleteProfessorByEmail(professorEmail)
+	err = s.professores.Delete(professor.ID)
 	if err != nil {
 		return &model.ApplicationError{
 			Message: err.Error(),
 		}
 	}
+	unindexProfessor(professor.ID)
+	deleteFoto(professor.FotoPerfil.String)
 
 	return nil
 }
 
-func CreateAluno(aluno *model.Aluno) (*model.Aluno, error) {
-	if !database.ExistsProfessorByID(aluno.ProfessorID) {
-		return nil, &model.ProfessorNotFoundError{
-			Message: fmt.Sprintf("Professor with ID %d not found", aluno.ProfessorID),
-		}
+func (s *Service) CreateAluno(aluno *model.Aluno) (*model.Aluno, error) {
+	professor, err := s.FindProfessorByID(aluno.ProfessorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if aluno.Duracao == 0 {
+		aluno.Duracao = professor.DuracaoAula
 	}
 
 	if err := validator.ValidateAluno(aluno); err != nil {
 		return nil, err
 	}
 
-	aluno, err := database.CreateAluno(aluno)
+	if err := validator.ValidateAlunoDisponibilidade(aluno, s.professores); err != nil {
+		return nil, err
+	}
+
+	aluno, err = s.alunos.Create(aluno)
 	if err != nil {
+		if err == database.ErrAulaConflict {
+			return nil, &model.ConflictError{
+				Message: "The professor already has a lesson booked at this time",
+			}
+		}
 		return nil, &model.ApplicationError{
 			Message: err.Error(),
 		}
 	}
 
+	aluno.Assinatura = signAluno(aluno.ID)
 	return aluno, nil
 }
 
-func GetAlunosByProfessorToken(token string) ([]*model.Aluno, error) {
-	professorEmail, err := getSubFromAccessToken(token)
+func (s *Service) GetAlunosByPrincipal(principal *model.Principal, status string) ([]*model.Aluno, error) {
+	if err := validator.ValidateAlunoStatus(status); err != nil {
+		return nil, err
+	}
+
+	professor, err := professorFromPrincipal(principal)
 	if err != nil {
-		return nil, &model.JwtTokenError{
-			Message: err.Error(),
-		}
+		return nil, err
 	}
 
-	alunos, err := database.FindAlunosByProfessorEmail(professorEmail)
+	alunos, err := s.alunos.FindByProfessorID(professor.ID, status)
 	if err != nil {
 		return nil, &model.ApplicationError{
 			Message: err.Error(),
@@ -217,99 +298,101 @@ func GetAlunosByProfessorToken(token string) ([]*model.Aluno, error) {
 	return alunos, nil
 }
 
-func Login(email, password string) ([]string, error) {
+// Login checks the credentials of a professor. When the professor has TOTP
+// enabled no tokens are returned; the MFA token must instead be exchanged for
+// them through LoginMfa.
+func (s *Service) Login(email, password string, client *model.SessionClient) ([]string, string, error) {
 	if err := validator.ValidateLogin(email, password); err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	if err := checkLoginAllowed(model.RoleProfessor, email, client); err != nil {
+		return nil, "", err
 	}
 
-	professor, err := database.FindProfessorByEmail(email)
+	professor, err := s.professores.FindByEmail(email)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, &model.BadCredentialsError{
-				Message: "Invalid credentials",
-			}
+			return nil, "", badCredentials(model.RoleProfessor, email, client)
 		}
-		return nil, &model.ApplicationError{
+		return nil, "", &model.ApplicationError{
 			Message: err.Error(),
 		}
 	}
 
 	match := checkPasswordHash(password, professor.Password)
 	if !match {
-		return nil, &model.BadCredentialsError{
-			Message: "Invalid credentials",
-		}
+		return nil, "", badCredentials(model.RoleProfessor, email, client)
 	}
-
-	accessToken, err := generateAccessToken(professor.Email)
-	if err != nil {
-		return nil, &model.ApplicationError{
-			Message: err.Error(),
-		}
+	// With TOTP enabled the password is only the first factor, so the
+	// failures are kept until LoginMfa accepts the second one.
+	if !professor.TotpEnabledAt.Valid {
+		loginSucceeded(model.RoleProfessor, email)
 	}
-	refreshtoken, err := generateRefreshToken(professor.Email)
-	if err != nil {
-		return nil, &model.ApplicationError{
-			Message: err.Error(),
+
+	if professor.SuspendedAt.Valid {
+		return nil, "", &model.ForbiddenError{
+			Message: "Professor account is suspended",
 		}
 	}
 
-	return []string{accessToken, refreshtoken}, nil
+	return s.loginResult(professor, client)
 }
 
-func Refresh(refreshToken string) ([]string, error) {
+// Refresh rotates refreshToken, returning a new access and refresh token
+// pair. A refresh token can only be used once.
+func (s *Service) Refresh(refreshToken string, client *model.SessionClient) ([]string, error) {
 	if err := validator.ValidateRefresh(refreshToken); err != nil {
 		return nil, err
 	}
 
-	email, err := getSubFromRefreshToken(refreshToken)
+	claims, err := s.getClaimsFromRefreshToken(refreshToken)
 	if err != nil {
 		return nil, &model.JwtTokenError{
 			Message: err.Error(),
 		}
 	}
-	_, err = database.FindProfessorByEmail(email)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, &model.JwtTokenError{
-				Message: "Invalid refresh token",
-			}
-		}
-		return nil, &model.ApplicationError{
-			Message: err.Error(),
-		}
-	}
 
-	invalidateTokens(refreshToken)
+	if _, err := s.loadPrincipal(claims); err != nil {
+		return nil, err
+	}
 
-	accessToken, err := generateAccessToken(email)
+	current, err := s.useRefreshToken(claims)
 	if err != nil {
-		return nil, &model.ApplicationError{
-			Message: err.Error(),
-		}
+		return nil, err
 	}
-	newRefreshToken, err := generateRefreshToken(email)
+
+	tokens, err := s.rotateTokens(claims, current, client)
 	if err != nil {
 		return nil, &model.ApplicationError{
 			Message: err.Error(),
 		}
 	}
 
-	return []string{accessToken, newRefreshToken}, nil
+	return tokens, nil
 }
 
-func Logout(token, refreshToken string) error {
+func (s *Service) Logout(principal *model.Principal, refreshToken string) error {
 	if err := validator.ValidateRefresh(refreshToken); err != nil {
 		return err
 	}
 
-	_, err := getSubFromRefreshToken(refreshToken)
+	claims, err := s.getClaimsFromRefreshToken(refreshToken)
 	if err != nil {
 		return &model.JwtTokenError{
 			Message: err.Error(),
 		}
 	}
+	if claims.Subject != principal.Subject || claims.role() != principal.Role {
+		return &model.JwtTokenError{
+			Message: "Refresh token does not belong to the authenticated user",
+		}
+	}
+
+	if err := s.revokeRefreshToken(claims); err != nil {
+		return err
+	}
 
-	invalidateTokens(token, refreshToken)
+	s.invalidateToken(principal.TokenID, principal.TokenExpiresAt)
 	return nil
 }
@@ -0,0 +1,1317 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math/big"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cleysonph/hyperprof/config"
+	"github.com/cleysonph/hyperprof/internal/lockout"
+	"github.com/cleysonph/hyperprof/internal/mailer"
+	"github.com/cleysonph/hyperprof/internal/memory"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/oidc"
+	"github.com/cleysonph/hyperprof/internal/search"
+	"github.com/cleysonph/hyperprof/internal/storage"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const testPassword = "secret123"
+
+var testClient = &model.SessionClient{Device: "test", IP: "127.0.0.1", UserAgent: "go test"}
+
+var (
+	_ ProfessorRepository = (*memory.ProfessorRepository)(nil)
+	_ AlunoRepository     = (*memory.AlunoRepository)(nil)
+	_ TokenRepository     = (*memory.TokenRepository)(nil)
+)
+
+// newTestService returns a service backed by an empty in-memory database,
+// resetting the other backends the use cases reach.
+func newTestService(t *testing.T) (*Service, *memory.Database) {
+	t.Helper()
+
+	config.TokenSecret = "test-token-secret"
+	config.RefreshSecret = "test-refresh-secret"
+	config.ManageSecret = "test-manage-secret"
+	config.TokenDuration = 900
+	config.RefreshDuration = 3600
+	config.Location = time.UTC
+
+	lockout.Init(lockout.NewMemoryTracker())
+	mailer.Init(mailer.NewLogMailer(""))
+	search.Init(search.NewMemorySearcher())
+	storage.Init(storage.NewLocalStorage(t.TempDir(), "http://localhost/api/arquivos"))
+
+	db := memory.NewDatabase()
+	return New(db.Professores(), db.Alunos(), db.Tokens()), db
+}
+
+func newProfessor(nome, email string, valorHora float64, idade int32) *model.Professor {
+	return &model.Professor{
+		Nome:      nome,
+		Email:     email,
+		Idade:     idade,
+		Descricao: "Professor de matemática e física",
+		ValorHora: valorHora,
+		Password:  testPassword,
+	}
+}
+
+// createProfessor creates a professor whose email is already verified.
+func createProfessor(t *testing.T, s *Service, db *memory.Database, professor *model.Professor) *model.Professor {
+	t.Helper()
+
+	created, err := s.CreateProfessor(professor, testPassword)
+	if err != nil {
+		t.Fatalf("CreateProfessor(%s) error = %v", professor.Email, err)
+	}
+	db.VerifyProfessorEmail(created.ID)
+	s.reindexProfessor(created.ID)
+	return created
+}
+
+// login logs the professor in and authenticates the access token, returning
+// the principal along with the refresh token.
+func login(t *testing.T, s *Service, email string) (*model.Principal, string) {
+	t.Helper()
+
+	tokens, _, err := s.Login(email, testPassword, testClient)
+	if err != nil {
+		t.Fatalf("Login(%s) error = %v", email, err)
+	}
+	principal, err := s.Authenticate(tokens[0])
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	return principal, tokens[1]
+}
+
+func assertErrorAs[T error](t *testing.T, err error) T {
+	t.Helper()
+
+	var target T
+	if !errors.As(err, &target) {
+		t.Fatalf("error = %v (%T), want %T", err, err, target)
+	}
+	return target
+}
+
+func assertValidationField(t *testing.T, err error, field string) {
+	t.Helper()
+
+	validationErr := assertErrorAs[*model.ValidationError](t, err)
+	if _, ok := validationErr.Errors[field]; !ok {
+		t.Fatalf("validation errors = %v, want an error on %s", validationErr.Errors, field)
+	}
+}
+
+func professorIDs(professores []*model.Professor) []int64 {
+	ids := make([]int64, len(professores))
+	for i, professor := range professores {
+		ids[i] = professor.ID
+	}
+	return ids
+}
+
+func equalIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFindAllProfessores(t *testing.T) {
+	s, db := newTestService(t)
+
+	ana := createProfessor(t, s, db, newProfessor("Ana Souza", "ana@example.com", 50, 30))
+	bruno := createProfessor(t, s, db, newProfessor("Bruno Lima", "bruno@example.com", 120, 45))
+	carla := createProfessor(t, s, db, newProfessor("Carla Dias", "carla@example.com", 80, 25))
+	suspended := createProfessor(t, s, db, newProfessor("Daniel Rocha", "daniel@example.com", 60, 35))
+	db.SuspendProfessor(suspended.ID)
+	if _, err := s.CreateProfessor(newProfessor("Elisa Prado", "elisa@example.com", 70, 40), testPassword); err != nil {
+		t.Fatalf("CreateProfessor() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter model.ProfessorFilter
+		want   []int64
+		total  int64
+	}{
+		{"defaults", model.ProfessorFilter{}, []int64{ana.ID, bruno.ID, carla.ID}, 3},
+		{"newest", model.ProfessorFilter{Sort: model.ProfessorSortNewest}, []int64{carla.ID, bruno.ID, ana.ID}, 3},
+		{"price asc", model.ProfessorFilter{Sort: model.ProfessorSortPriceAsc}, []int64{ana.ID, carla.ID, bruno.ID}, 3},
+		{"price desc", model.ProfessorFilter{Sort: model.ProfessorSortPriceDesc}, []int64{bruno.ID, carla.ID, ana.ID}, 3},
+		{"nome", model.ProfessorFilter{Nome: "LIMA"}, []int64{bruno.ID}, 1},
+		{"valor hora", model.ProfessorFilter{ValorHoraMin: 60, ValorHoraMax: 100}, []int64{carla.ID}, 1},
+		{"idade", model.ProfessorFilter{IdadeMin: 28}, []int64{ana.ID, bruno.ID}, 2},
+		{"first page", model.ProfessorFilter{Page: 1, Limit: 2}, []int64{ana.ID, bruno.ID}, 3},
+		{"last page", model.ProfessorFilter{Page: 2, Limit: 2}, []int64{carla.ID}, 3},
+		{"search", model.ProfessorFilter{Q: "carla"}, []int64{carla.ID}, 1},
+		{"search without hits", model.ProfessorFilter{Q: "quimica"}, []int64{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			page, err := s.FindAllProfessores(&filter)
+			if err != nil {
+				t.Fatalf("FindAllProfessores() error = %v", err)
+			}
+			if got := professorIDs(page.Data); !equalIDs(got, tt.want) {
+				t.Errorf("professores = %v, want %v", got, tt.want)
+			}
+			if page.Pagination.Total != tt.total {
+				t.Errorf("total = %d, want %d", page.Pagination.Total, tt.total)
+			}
+		})
+	}
+
+	t.Run("invalid filter", func(t *testing.T) {
+		_, err := s.FindAllProfessores(&model.ProfessorFilter{Sort: "popular"})
+		assertValidationField(t, err, "sort")
+	})
+
+	t.Run("hidden professores are not searched", func(t *testing.T) {
+		admin := &model.Principal{Administrador: &model.Administrador{ID: 1}}
+		searched := func(q string) []int64 {
+			t.Helper()
+			hits, err := search.Search(q, maxSearchHits)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			ids := []int64{}
+			for _, hit := range hits {
+				ids = append(ids, hit.ID)
+			}
+			return ids
+		}
+
+		if got := searched("elisa"); len(got) != 0 {
+			t.Errorf("unverified professor hits = %v", got)
+		}
+		if _, err := s.SuspendProfessorByAdminPrincipal(admin, carla.ID); err != nil {
+			t.Fatalf("SuspendProfessorByAdminPrincipal() error = %v", err)
+		}
+		if got := searched("carla"); len(got) != 0 {
+			t.Errorf("suspended professor hits = %v", got)
+		}
+		if _, err := s.ReactivateProfessorByAdminPrincipal(admin, carla.ID); err != nil {
+			t.Fatalf("ReactivateProfessorByAdminPrincipal() error = %v", err)
+		}
+		if got := searched("carla"); !equalIDs(got, []int64{carla.ID}) {
+			t.Errorf("reactivated professor hits = %v", got)
+		}
+	})
+
+	t.Run("hides credentials", func(t *testing.T) {
+		page, err := s.FindAllProfessores(&model.ProfessorFilter{})
+		if err != nil {
+			t.Fatalf("FindAllProfessores() error = %v", err)
+		}
+		for _, professor := range page.Data {
+			if professor.Password != "" {
+				t.Errorf("professor %d carries its password hash", professor.ID)
+			}
+			if professor.Disciplinas == nil || professor.Fotos == nil {
+				t.Errorf("professor %d details are not loaded", professor.ID)
+			}
+		}
+	})
+}
+
+func TestFindProfessorByID(t *testing.T) {
+	s, db := newTestService(t)
+
+	professor := createProfessor(t, s, db, newProfessor("Ana Souza", "ana@example.com", 50, 30))
+	unverified, err := s.CreateProfessor(newProfessor("Bruno Lima", "bruno@example.com", 50, 30), testPassword)
+	if err != nil {
+		t.Fatalf("CreateProfessor() error = %v", err)
+	}
+
+	found, err := s.FindProfessorByID(professor.ID)
+	if err != nil {
+		t.Fatalf("FindProfessorByID() error = %v", err)
+	}
+	if found.Email != professor.Email {
+		t.Errorf("email = %s, want %s", found.Email, professor.Email)
+	}
+
+	for _, id := range []int64{unverified.ID, 999} {
+		_, err := s.FindProfessorByID(id)
+		assertErrorAs[*model.ProfessorNotFoundError](t, err)
+	}
+}
+
+func TestGetProfessorByPrincipal(t *testing.T) {
+	s, db := newTestService(t)
+
+	professor := createProfessor(t, s, db, newProfessor("Ana Souza", "ana@example.com", 50, 30))
+	principal, _ := login(t, s, professor.Email)
+
+	found, err := s.GetProfessorByPrincipal(principal)
+	if err != nil {
+		t.Fatalf("GetProfessorByPrincipal() error = %v", err)
+	}
+	if found.ID != professor.ID {
+		t.Errorf("id = %d, want %d", found.ID, professor.ID)
+	}
+
+	_, err = s.GetProfessorByPrincipal(&model.Principal{Role: model.RoleStudent})
+	assertErrorAs[*model.ForbiddenError](t, err)
+}
+
+func TestCreateProfessor(t *testing.T) {
+	s, db := newTestService(t)
+
+	professor, err := s.CreateProfessor(newProfessor("Ana Souza", "ana@example.com", 50, 30), testPassword)
+	if err != nil {
+		t.Fatalf("CreateProfessor() error = %v", err)
+	}
+	if professor.ID == 0 {
+		t.Error("professor has no id")
+	}
+	if professor.DuracaoAula != model.DefaultDuracaoAula {
+		t.Errorf("duracao_aula = %d, want %d", professor.DuracaoAula, model.DefaultDuracaoAula)
+	}
+	if professor.Password != "" {
+		t.Error("created professor carries its password hash")
+	}
+	if verifications := db.EmailVerifications(professor.ID); len(verifications) != 1 {
+		t.Errorf("email verifications = %d, want 1", len(verifications))
+	}
+
+	t.Run("duplicate email", func(t *testing.T) {
+		_, err := s.CreateProfessor(newProfessor("Ana Lima", "ana@example.com", 50, 30), testPassword)
+		assertValidationField(t, err, "email")
+	})
+
+	t.Run("password confirmation", func(t *testing.T) {
+		_, err := s.CreateProfessor(newProfessor("Bruno Lima", "bruno@example.com", 50, 30), "other123")
+		assertValidationField(t, err, "password_confirmation")
+	})
+
+	t.Run("invalid fields", func(t *testing.T) {
+		_, err := s.CreateProfessor(newProfessor("Bo", "bruno@example.com", 5, 16), testPassword)
+		for _, field := range []string{"nome", "valor_hora", "idade"} {
+			assertValidationField(t, err, field)
+		}
+	})
+}
+
+func TestUpdateProfessorByPrincipal(t *testing.T) {
+	s, db := newTestService(t)
+
+	professor := createProfessor(t, s, db, newProfessor("Ana Souza", "ana@example.com", 50, 30))
+	createProfessor(t, s, db, newProfessor("Bruno Lima", "bruno@example.com", 50, 30))
+	principal, _ := login(t, s, professor.Email)
+
+	t.Run("email taken", func(t *testing.T) {
+		_, err := s.UpdateProfessorByPrincipal(principal, newProfessor("Ana Souza", "bruno@example.com", 50, 30), testPassword)
+		assertValidationField(t, err, "email")
+	})
+
+	data := newProfessor("Ana Souza Lima", "ana.lima@example.com", 90, 31)
+	data.Password = "newsecret"
+	updated, err := s.UpdateProfessorByPrincipal(principal, data, "newsecret")
+	if err != nil {
+		t.Fatalf("UpdateProfessorByPrincipal() error = %v", err)
+	}
+	if updated.Nome != "Ana Souza Lima" || updated.ValorHora != 90 || updated.DuracaoAula != model.DefaultDuracaoAula {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	if _, _, err := s.Login("ana.lima@example.com", "newsecret", testClient); err != nil {
+		t.Errorf("Login() with the new credentials error = %v", err)
+	}
+	_, _, err = s.Login(professor.Email, testPassword, testClient)
+	assertErrorAs[*model.BadCredentialsError](t, err)
+}
+
+func pngFoto(t *testing.T, size int) (multipart.File, *multipart.FileHeader) {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("foto", "foto.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(part, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+
+	fileHeader := form.File["foto"][0]
+	file, err := fileHeader.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return file, fileHeader
+}
+
+func TestUpdateProfessorFotoByPrincipal(t *testing.T) {
+	s, db := newTestService(t)
+
+	professor := createProfessor(t, s, db, newProfessor("Ana Souza", "ana@example.com", 50, 30))
+
+	upload := func() []*model.FotoVariante {
+		t.Helper()
+
+		principal, _ := login(t, s, professor.Email)
+		file, fileHeader := pngFoto(t, 128)
+		if err := s.UpdateProfessorFotoByPrincipal(principal, file, fileHeader); err != nil {
+			t.Fatalf("UpdateProfessorFotoByPrincipal() error = %v", err)
+		}
+		found, err := s.FindProfessorByID(professor.ID)
+		if err != nil {
+			t.Fatalf("FindProfessorByID() error = %v", err)
+		}
+		return found.Fotos
+	}
+
+	first := upload()
+	if len(first) != len(model.FotoTamanhos)*len(fotoFormatos) {
+		t.Fatalf("fotos = %d, want %d", len(first), len(model.FotoTamanhos)*len(fotoFormatos))
+	}
+	for i, foto := range first {
+		tamanho := model.FotoTamanhos[i/len(fotoFormatos)]
+		formato := fotoFormatos[i%len(fotoFormatos)]
+		if foto.Tamanho != tamanho || foto.Formato != formato.nome || !strings.HasPrefix(foto.URL, "http://localhost/api/arquivos/"+fotoKeyPrefix) {
+			t.Errorf("foto = %+v", foto)
+		}
+
+		key := strings.TrimPrefix(foto.URL, "http://localhost/api/arquivos/")
+		object, err := storage.Get(key)
+		if err != nil {
+			t.Fatalf("storage.Get(%s) error = %v", key, err)
+		}
+		data, _ := io.ReadAll(object.Body)
+		object.Body.Close()
+		if got := http.DetectContentType(data); got != formato.contentType {
+			t.Errorf("%s content type = %s, want %s", key, got, formato.contentType)
+		}
+	}
+
+	second := upload()
+	if second[0].URL == first[0].URL {
+		t.Error("replaced photo kept its url")
+	}
+	objects, err := storage.List(fotoKeyPrefix)
+	if err != nil {
+		t.Fatalf("storage.List() error = %v", err)
+	}
+	if len(objects) != len(model.FotoTamanhos)*len(fotoFormatos) {
+		t.Errorf("stored objects = %d, the replaced photo was not deleted", len(objects))
+	}
+
+	t.Run("not an image", func(t *testing.T) {
+		principal, _ := login(t, s, professor.Email)
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, _ := writer.CreateFormFile("foto", "foto.png")
+		part.Write([]byte("not an image"))
+		writer.Close()
+		form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer form.RemoveAll()
+		file, _ := form.File["foto"][0].Open()
+		defer file.Close()
+
+		err = s.UpdateProfessorFotoByPrincipal(principal, file, form.File["foto"][0])
+		assertValidationField(t, err, "foto")
+	})
+}
+
+func TestDeleteProfessorByPrincipal(t *testing.T) {
+	s, db := newTestService(t)
+
+	professor := createProfessor(t, s, db, newProfessor("Ana Souza", "ana@example.com", 50, 30))
+	principal, _ := login(t, s, professor.Email)
+
+	if err := s.DeleteProfessorByPrincipal(principal); err != nil {
+		t.Fatalf("DeleteProfessorByPrincipal() error = %v", err)
+	}
+
+	_, err := s.FindProfessorByID(professor.ID)
+	assertErrorAs[*model.ProfessorNotFoundError](t, err)
+	page, err := s.FindAllProfessores(&model.ProfessorFilter{Q: "ana"})
+	if err != nil {
+		t.Fatalf("FindAllProfessores() error = %v", err)
+	}
+	if len(page.Data) != 0 {
+		t.Errorf("deleted professor is still searchable")
+	}
+}
+
+// nextAula returns tomorrow at the given time, in UTC.
+func nextAula(hour, minute int) time.Time {
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	return time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), hour, minute, 0, 0, time.UTC)
+}
+
+func TestCreateAluno(t *testing.T) {
+	s, db := newTestService(t)
+
+	professor := createProfessor(t, s, db, newProfessor("Ana Souza", "ana@example.com", 50, 30))
+	db.CreateDisponibilidade(&model.Disponibilidade{
+		ProfessorID: professor.ID,
+		DiaSemana:   int32(nextAula(0, 0).Weekday()),
+		HoraInicio:  "08:00",
+		HoraFim:     "12:00",
+	})
+
+	newAluno := func(dataAula time.Time) *model.Aluno {
+		return &model.Aluno{
+			ProfessorID: professor.ID,
+			Nome:        "Carlos",
+			Email:       "carlos@example.com",
+			DataAula:    dataAula,
+		}
+	}
+
+	aluno, err := s.CreateAluno(newAluno(nextAula(9, 0)))
+	if err != nil {
+		t.Fatalf("CreateAluno() error = %v", err)
+	}
+	if aluno.Duracao != professor.DuracaoAula {
+		t.Errorf("duracao = %d, want %d", aluno.Duracao, professor.DuracaoAula)
+	}
+	if aluno.Status != model.AulaStatusScheduled {
+		t.Errorf("status = %s, want %s", aluno.Status, model.AulaStatusScheduled)
+	}
+	if !checkAlunoSignature(aluno.ID, aluno.Assinatura) {
+		t.Error("aluno signature does not match")
+	}
+
+	t.Run("conflict", func(t *testing.T) {
+		_, err := s.CreateAluno(newAluno(nextAula(9, 30)))
+		assertErrorAs[*model.ConflictError](t, err)
+	})
+
+	t.Run("outside availability", func(t *testing.T) {
+		_, err := s.CreateAluno(newAluno(nextAula(14, 0)))
+		assertValidationField(t, err, "data_aula")
+	})
+
+	t.Run("blocked", func(t *testing.T) {
+		db.CreateDisponibilidadeExcecao(&model.DisponibilidadeExcecao{
+			ProfessorID: professor.ID,
+			Inicio:      nextAula(10, 30),
+			Fim:         nextAula(12, 0),
+		})
+		_, err := s.CreateAluno(newAluno(nextAula(11, 0)))
+		assertValidationField(t, err, "data_aula")
+	})
+
+	t.Run("in the past", func(t *testing.T) {
+		_, err := s.CreateAluno(newAluno(nextAula(9, 0).AddDate(0, 0, -7)))
+		assertValidationField(t, err, "data_aula")
+	})
+
+	t.Run("unknown professor", func(t *testing.T) {
+		aluno := newAluno(nextAula(10, 0))
+		aluno.ProfessorID = 999
+		_, err := s.CreateAluno(aluno)
+		assertErrorAs[*model.ProfessorNotFoundError](t, err)
+	})
+}
+
+func TestGetAlunosByPrincipal(t *testing.T) {
+	s, db := newTestService(t)
+
+	professor := createProfessor(t, s, db, newProfessor("Ana Souza", "ana@example.com", 50, 30))
+	other := createProfessor(t, s, db, newProfessor("Bruno Lima", "bruno@example.com", 50, 30))
+	for _, professorID := range []int64{professor.ID, other.ID} {
+		db.CreateDisponibilidade(&model.Disponibilidade{
+			ProfessorID: professorID,
+			DiaSemana:   int32(nextAula(0, 0).Weekday()),
+			HoraInicio:  "08:00",
+			HoraFim:     "18:00",
+		})
+	}
+	for _, aluno := range []*model.Aluno{
+		{ProfessorID: professor.ID, Nome: "Carlos", Email: "carlos@example.com", DataAula: nextAula(9, 0)},
+		{ProfessorID: professor.ID, Nome: "Diana", Email: "diana@example.com", DataAula: nextAula(10, 0)},
+		{ProfessorID: other.ID, Nome: "Eduardo", Email: "eduardo@example.com", DataAula: nextAula(9, 0)},
+	} {
+		if _, err := s.CreateAluno(aluno); err != nil {
+			t.Fatalf("CreateAluno() error = %v", err)
+		}
+	}
+	principal, _ := login(t, s, professor.Email)
+
+	alunos, err := s.GetAlunosByPrincipal(principal, "")
+	if err != nil {
+		t.Fatalf("GetAlunosByPrincipal() error = %v", err)
+	}
+	if len(alunos) != 2 || alunos[0].Nome != "Carlos" || alunos[1].Nome != "Diana" {
+		t.Errorf("alunos = %+v", alunos)
+	}
+
+	alunos, err = s.GetAlunosByPrincipal(principal, model.AulaStatusCancelled)
+	if err != nil {
+		t.Fatalf("GetAlunosByPrincipal() error = %v", err)
+	}
+	if len(alunos) != 0 {
+		t.Errorf("cancelled alunos = %d, want 0", len(alunos))
+	}
+
+	_, err = s.GetAlunosByPrincipal(principal, "pending")
+	assertValidationField(t, err, "status")
+}
+
+func TestCancelAlunoByPrincipal(t *testing.T) {
+	s, db := newTestService(t)
+
+	professor := createProfessor(t, s, db, newProfessor("Ana Souza", "ana@example.com", 50, 30))
+	db.CreateDisponibilidade(&model.Disponibilidade{
+		ProfessorID: professor.ID,
+		DiaSemana:   int32(nextAula(0, 0).Weekday()),
+		HoraInicio:  "08:00",
+		HoraFim:     "12:00",
+	})
+	createAluno := func(dataAula time.Time) *model.Aluno {
+		aluno, err := s.CreateAluno(&model.Aluno{
+			ProfessorID: professor.ID,
+			Nome:        "Carlos",
+			Email:       "carlos@example.com",
+			DataAula:    dataAula,
+		})
+		if err != nil {
+			t.Fatalf("CreateAluno() error = %v", err)
+		}
+		return aluno
+	}
+	principal, _ := login(t, s, professor.Email)
+
+	aluno := createAluno(nextAula(9, 0))
+	cancelled, err := s.CancelAlunoByPrincipal(principal, aluno.ID, "Imprevisto")
+	if err != nil {
+		t.Fatalf("CancelAlunoByPrincipal() error = %v", err)
+	}
+	if cancelled.Status != model.AulaStatusCancelled {
+		t.Errorf("status = %s, want %s", cancelled.Status, model.AulaStatusCancelled)
+	}
+	historico, err := s.GetAlunoHistoricoByPrincipal(principal, aluno.ID)
+	if err != nil {
+		t.Fatalf("GetAlunoHistoricoByPrincipal() error = %v", err)
+	}
+	if len(historico) != 2 || historico[1].StatusAnterior != model.AulaStatusScheduled || historico[1].Motivo != "Imprevisto" {
+		t.Errorf("historico = %+v", historico)
+	}
+
+	t.Run("already cancelled", func(t *testing.T) {
+		_, err := s.CancelAlunoByPrincipal(principal, aluno.ID, "")
+		assertErrorAs[*model.ConflictError](t, err)
+	})
+
+	t.Run("changed by another request", func(t *testing.T) {
+		aluno := createAluno(nextAula(10, 0))
+		stale, err := s.findAlunoByPrincipal(principal, aluno.ID)
+		if err != nil {
+			t.Fatalf("findAlunoByPrincipal() error = %v", err)
+		}
+		if _, err := s.CancelAlunoByPrincipal(principal, aluno.ID, ""); err != nil {
+			t.Fatalf("CancelAlunoByPrincipal() error = %v", err)
+		}
+
+		// The stale copy still looks scheduled, so only the update itself can
+		// tell the lesson was cancelled since it was read.
+		_, err = s.rescheduleAluno(stale, model.HistoricoAutorProfessor, nextAula(11, 0), "")
+		assertErrorAs[*model.ConflictError](t, err)
+		historico, err := s.GetAlunoHistoricoByPrincipal(principal, aluno.ID)
+		if err != nil {
+			t.Fatalf("GetAlunoHistoricoByPrincipal() error = %v", err)
+		}
+		if len(historico) != 2 {
+			t.Errorf("historico = %+v", historico)
+		}
+	})
+}
+
+func TestLogin(t *testing.T) {
+	s, db := newTestService(t)
+
+	professor := createProfessor(t, s, db, newProfessor("Ana Souza", "ana@example.com", 50, 30))
+
+	tokens, mfaToken, err := s.Login(professor.Email, testPassword, testClient)
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if len(tokens) != 2 || mfaToken != "" {
+		t.Fatalf("Login() = %v, %q", tokens, mfaToken)
+	}
+	principal, err := s.Authenticate(tokens[0])
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if principal.Professor == nil || principal.Professor.ID != professor.ID {
+		t.Errorf("principal = %+v", principal)
+	}
+
+	t.Run("bad credentials", func(t *testing.T) {
+		_, _, err := s.Login(professor.Email, "wrong-password", testClient)
+		assertErrorAs[*model.BadCredentialsError](t, err)
+		_, _, err = s.Login("nobody@example.com", testPassword, testClient)
+		assertErrorAs[*model.BadCredentialsError](t, err)
+	})
+
+	t.Run("missing fields", func(t *testing.T) {
+		_, _, err := s.Login("", "", testClient)
+		assertErrorAs[*model.ValidationError](t, err)
+	})
+
+	t.Run("suspended", func(t *testing.T) {
+		db.SuspendProfessor(professor.ID)
+		_, _, err := s.Login(professor.Email, testPassword, testClient)
+		assertErrorAs[*model.ForbiddenError](t, err)
+		_, err = s.Authenticate(tokens[0])
+		assertErrorAs[*model.ForbiddenError](t, err)
+	})
+}
+
+// enableTotp turns on TOTP for the professor, returning their secret. Each
+// step authenticates again, like the requests of the API would.
+func enableTotp(t *testing.T, s *Service, email string) string {
+	t.Helper()
+
+	principal, _ := login(t, s, email)
+	secret, _, err := s.EnrollTotpByPrincipal(principal)
+	if err != nil {
+		t.Fatalf("EnrollTotpByPrincipal() error = %v", err)
+	}
+	code, err := totpCode(secret, totpStep(time.Now()))
+	if err != nil {
+		t.Fatalf("totpCode() error = %v", err)
+	}
+	principal, _ = login(t, s, email)
+	if _, err := s.ConfirmTotpByPrincipal(principal, code); err != nil {
+		t.Fatalf("ConfirmTotpByPrincipal() error = %v", err)
+	}
+	return secret
+}
+
+func TestLoginMfa(t *testing.T) {
+	s, db := newTestService(t)
+
+	professor := createProfessor(t, s, db, newProfessor("Ana Souza", "ana@example.com", 50, 30))
+	secret := enableTotp(t, s, professor.Email)
+
+	tokens, mfaToken, err := s.Login(professor.Email, testPassword, testClient)
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if len(tokens) != 0 || mfaToken == "" {
+		t.Fatalf("Login() = %v, %q, want a MFA token", tokens, mfaToken)
+	}
+	// The code accepted by ConfirmTotpByPrincipal cannot be replayed, so the
+	// next time step is used.
+	code, _ := totpCode(secret, totpStep(time.Now())+1)
+	tokens, err = s.LoginMfa(mfaToken, code, testClient)
+	if err != nil {
+		t.Fatalf("LoginMfa() error = %v", err)
+	}
+	if _, err := s.Authenticate(tokens[0]); err != nil {
+		t.Errorf("Authenticate() error = %v", err)
+	}
+
+	t.Run("wrong codes lock the account", func(t *testing.T) {
+		// Entering the right password again to get a new MFA token must not
+		// clear the failures of the second factor.
+		for i := 0; i < int(loginMaxAttempts()); i++ {
+			if i%mfaMaxAttempts == 0 {
+				_, mfaToken, err = s.Login(professor.Email, testPassword, testClient)
+				if err != nil {
+					t.Fatalf("Login() error = %v", err)
+				}
+			}
+			_, err := s.LoginMfa(mfaToken, "aaaaa-bbbbb", testClient)
+			assertErrorAs[*model.BadCredentialsError](t, err)
+		}
+
+		_, _, err := s.Login(professor.Email, testPassword, testClient)
+		assertErrorAs[*model.AccountLockedError](t, err)
+	})
+}
+
+// fakeMailer keeps the messages sent, failing with err when it is set.
+type fakeMailer struct {
+	mu       sync.Mutex
+	err      error
+	messages []*mailer.Message
+}
+
+func (m *fakeMailer) Send(msg *mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+func (m *fakeMailer) sent() []*mailer.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*mailer.Message{}, m.messages...)
+}
+
+func TestForgotPassword(t *testing.T) {
+	s, db := newTestService(t)
+
+	professor := createProfessor(t, s, db, newProfessor("Ana Souza", "ana@example.com", 50, 30))
+	other := createProfessor(t, s, db, newProfessor("Bruno Lima", "bruno@example.com", 50, 30))
+	m := &fakeMailer{}
+	mailer.Init(m)
+
+	if err := s.ForgotPassword(professor.Email); err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+	// A second request within RESET_RESEND_DELAY is ignored
+	if err := s.ForgotPassword(professor.Email); err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+	sent := m.sent()
+	if len(sent) != 1 || sent[0].To != professor.Email {
+		t.Fatalf("sent = %+v", sent)
+	}
+
+	_, token, _ := strings.Cut(sent[0].Body, "token=")
+	token = strings.TrimSpace(strings.SplitN(token, "\n", 2)[0])
+	if err := s.ResetPassword(token, "new-secret", "new-secret"); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+	if _, _, err := s.Login(professor.Email, "new-secret", testClient); err != nil {
+		t.Errorf("Login() with the new password error = %v", err)
+	}
+	err := s.ResetPassword(token, "other-secret", "other-secret")
+	assertValidationField(t, err, "token")
+
+	t.Run("unknown email", func(t *testing.T) {
+		if err := s.ForgotPassword("nobody@example.com"); err != nil {
+			t.Errorf("ForgotPassword() error = %v", err)
+		}
+	})
+
+	t.Run("mailer failure", func(t *testing.T) {
+		// Failing only for registered emails would tell them apart
+		m.mu.Lock()
+		m.err = errors.New("smtp unavailable")
+		m.mu.Unlock()
+		if err := s.ForgotPassword(other.Email); err != nil {
+			t.Errorf("ForgotPassword() error = %v", err)
+		}
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	s, db := newTestService(t)
+
+	professor := createProfessor(t, s, db, newProfessor("Ana Souza", "ana@example.com", 50, 30))
+	tokens, _, err := s.Login(professor.Email, testPassword, testClient)
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+
+	rotated, err := s.Refresh(tokens[1], testClient)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if _, err := s.Authenticate(rotated[0]); err != nil {
+		t.Fatalf("Authenticate() with the rotated token error = %v", err)
+	}
+
+	// Using the first refresh token again means it leaked, so the whole
+	// session is revoked.
+	_, err = s.Refresh(tokens[1], testClient)
+	assertErrorAs[*model.JwtTokenError](t, err)
+	_, err = s.Refresh(rotated[1], testClient)
+	assertErrorAs[*model.JwtTokenError](t, err)
+	_, err = s.Authenticate(rotated[0])
+	assertErrorAs[*model.JwtTokenError](t, err)
+
+	t.Run("access token", func(t *testing.T) {
+		_, err := s.Refresh(rotated[0], testClient)
+		assertErrorAs[*model.JwtTokenError](t, err)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	s, db := newTestService(t)
+
+	professor := createProfessor(t, s, db, newProfessor("Ana Souza", "ana@example.com", 50, 30))
+	other := createProfessor(t, s, db, newProfessor("Bruno Lima", "bruno@example.com", 50, 30))
+	principal, refreshToken := login(t, s, professor.Email)
+	_, otherRefreshToken := login(t, s, other.Email)
+
+	err := s.Logout(principal, otherRefreshToken)
+	assertErrorAs[*model.JwtTokenError](t, err)
+
+	tokens, _, err := s.Login(professor.Email, testPassword, testClient)
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+
+	if err := s.Logout(principal, refreshToken); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	_, err = s.Refresh(refreshToken, testClient)
+	assertErrorAs[*model.JwtTokenError](t, err)
+	if _, err := s.Refresh(tokens[1], testClient); err != nil {
+		t.Errorf("Refresh() of another session error = %v", err)
+	}
+}
+
+func TestRevokeOtherSessionsByPrincipal(t *testing.T) {
+	s, db := newTestService(t)
+
+	professor := createProfessor(t, s, db, newProfessor("Ana Souza", "ana@example.com", 50, 30))
+	other := createProfessor(t, s, db, newProfessor("Bruno Lima", "bruno@example.com", 50, 30))
+	principal, refreshToken := login(t, s, professor.Email)
+	_, secondRefreshToken := login(t, s, professor.Email)
+	otherPrincipal, otherRefreshToken := login(t, s, other.Email)
+
+	sessions, err := s.GetSessionsByPrincipal(principal)
+	if err != nil {
+		t.Fatalf("GetSessionsByPrincipal() error = %v", err)
+	}
+	current := 0
+	for _, session := range sessions {
+		if session.Current {
+			current++
+		}
+	}
+	if len(sessions) != 2 || current != 1 {
+		t.Fatalf("sessions = %+v", sessions)
+	}
+
+	err = s.RevokeSessionByPrincipal(principal, otherPrincipal.SessionID)
+	assertErrorAs[*model.SessionNotFoundError](t, err)
+
+	if err := s.RevokeOtherSessionsByPrincipal(principal); err != nil {
+		t.Fatalf("RevokeOtherSessionsByPrincipal() error = %v", err)
+	}
+	_, err = s.Refresh(secondRefreshToken, testClient)
+	assertErrorAs[*model.JwtTokenError](t, err)
+	for _, token := range []string{refreshToken, otherRefreshToken} {
+		if _, err := s.Refresh(token, testClient); err != nil {
+			t.Errorf("Refresh() of a kept session error = %v", err)
+		}
+	}
+}
+
+const (
+	testOidcClientID    = "test-client"
+	testOidcSecret      = "test-client-secret"
+	testOidcRedirectURL = "http://localhost/auth/callback"
+)
+
+// fakeOidcProvider serves the discovery document, the signing keys and the
+// token endpoint of an OpenID Connect provider. Authorization codes are
+// handed out by authorize, standing in for the user signing in.
+type fakeOidcProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	// issuer is the issuer advertised by the discovery document, it may hold
+	// the tenant placeholder.
+	issuer string
+
+	mu    sync.Mutex
+	codes map[string]fakeOidcCode
+}
+
+type fakeOidcCode struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+// newFakeOidcProvider registers a provider named name whose issuer is served
+// under issuerPath. The discovery document advertises advertisedIssuerPath.
+func newFakeOidcProvider(t *testing.T, name, issuerPath, advertisedIssuerPath string) *fakeOidcProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+	f := &fakeOidcProvider{t: t, key: key, codes: map[string]fakeOidcCode{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(issuerPath+"/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 f.issuer,
+			"authorization_endpoint": f.server.URL + "/authorize",
+			"token_endpoint":         f.server.URL + "/token",
+			"jwks_uri":               f.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test-key",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", f.token)
+
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	f.issuer = f.server.URL + advertisedIssuerPath
+
+	oidc.Register(oidc.NewProvider(name, f.server.URL+issuerPath, testOidcClientID, testOidcSecret, testOidcRedirectURL))
+	return f
+}
+
+func (f *fakeOidcProvider) token(w http.ResponseWriter, r *http.Request) {
+	tokenError := func(code string) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": code})
+	}
+
+	if r.PostFormValue("grant_type") != "authorization_code" ||
+		r.PostFormValue("client_id") != testOidcClientID ||
+		r.PostFormValue("client_secret") != testOidcSecret ||
+		r.PostFormValue("redirect_uri") != testOidcRedirectURL {
+		tokenError("invalid_client")
+		return
+	}
+
+	f.mu.Lock()
+	code, ok := f.codes[r.PostFormValue("code")]
+	delete(f.codes, r.PostFormValue("code"))
+	f.mu.Unlock()
+	if !ok || oidc.CodeChallenge(r.PostFormValue("code_verifier")) != code.challenge {
+		tokenError("invalid_grant")
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"token_type": "Bearer",
+		"id_token":   f.sign(code.claims),
+	})
+}
+
+func (f *fakeOidcProvider) sign(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test-key"
+	signed, err := token.SignedString(f.key)
+	if err != nil {
+		f.t.Fatalf("SignedString() error = %v", err)
+	}
+	return signed
+}
+
+// authorize checks the URL the user was sent to and returns the state along
+// with the code the provider redirects them back with. The ID token carries
+// claims over the defaults, a nil claim is left out.
+func (f *fakeOidcProvider) authorize(authorizationURL string, claims jwt.MapClaims) (string, string) {
+	f.t.Helper()
+
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		f.t.Fatalf("url.Parse() error = %v", err)
+	}
+	query := parsed.Query()
+	if parsed.Path != "/authorize" ||
+		query.Get("response_type") != "code" ||
+		query.Get("client_id") != testOidcClientID ||
+		query.Get("redirect_uri") != testOidcRedirectURL ||
+		query.Get("code_challenge_method") != "S256" ||
+		query.Get("state") == "" ||
+		query.Get("nonce") == "" ||
+		query.Get("code_challenge") == "" {
+		f.t.Fatalf("authorization URL = %s", authorizationURL)
+	}
+
+	idClaims := jwt.MapClaims{
+		"iss":            f.issuer,
+		"aud":            testOidcClientID,
+		"sub":            "subject-1",
+		"email":          "ana@example.com",
+		"email_verified": true,
+		"name":           "Ana Souza",
+		"nonce":          query.Get("nonce"),
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+	for name, value := range claims {
+		if value == nil {
+			delete(idClaims, name)
+			continue
+		}
+		idClaims[name] = value
+	}
+
+	code, err := generateOpaqueToken()
+	if err != nil {
+		f.t.Fatalf("generateOpaqueToken() error = %v", err)
+	}
+	f.mu.Lock()
+	f.codes[code] = fakeOidcCode{challenge: query.Get("code_challenge"), claims: idClaims}
+	f.mu.Unlock()
+	return query.Get("state"), code
+}
+
+// oidcLogin signs in with the provider registered as name, which issues an
+// ID token carrying claims.
+func oidcLogin(t *testing.T, s *Service, f *fakeOidcProvider, name string, claims jwt.MapClaims) ([]string, string, error) {
+	t.Helper()
+
+	authorizationURL, err := s.StartOidcLogin(name)
+	if err != nil {
+		t.Fatalf("StartOidcLogin() error = %v", err)
+	}
+	state, code := f.authorize(authorizationURL, claims)
+	return s.LoginOidc(code, state, testClient)
+}
+
+func TestLoginOidc(t *testing.T) {
+	s, _ := newTestService(t)
+	f := newFakeOidcProvider(t, "fake", "", "")
+
+	tokens, mfaToken, err := oidcLogin(t, s, f, "fake", nil)
+	if err != nil {
+		t.Fatalf("LoginOidc() error = %v", err)
+	}
+	if len(tokens) != 2 || mfaToken != "" {
+		t.Fatalf("LoginOidc() = %v, %q", tokens, mfaToken)
+	}
+	principal, err := s.Authenticate(tokens[0])
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	professor := principal.Professor
+	if professor == nil || professor.Email != "ana@example.com" || professor.Nome != "Ana Souza" || !professor.EmailVerifiedAt.Valid {
+		t.Fatalf("principal = %+v", principal)
+	}
+
+	// The account is found through the identity once linked, whatever email
+	// the provider sends afterwards
+	tokens, _, err = oidcLogin(t, s, f, "fake", jwt.MapClaims{"email": "ana.souza@example.com"})
+	if err != nil {
+		t.Fatalf("LoginOidc() error = %v", err)
+	}
+	principal, err = s.Authenticate(tokens[0])
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if principal.Professor.ID != professor.ID {
+		t.Errorf("professor = %d, want %d", principal.Professor.ID, professor.ID)
+	}
+
+	t.Run("missing fields", func(t *testing.T) {
+		_, _, err := s.LoginOidc("", "", testClient)
+		assertValidationField(t, err, "code")
+		assertValidationField(t, err, "state")
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		_, err := s.StartOidcLogin("unknown")
+		assertErrorAs[*model.OidcProviderNotFoundError](t, err)
+	})
+
+	t.Run("state", func(t *testing.T) {
+		authorizationURL, err := s.StartOidcLogin("fake")
+		if err != nil {
+			t.Fatalf("StartOidcLogin() error = %v", err)
+		}
+		state, code := f.authorize(authorizationURL, nil)
+
+		_, _, err = s.LoginOidc(code, "unknown-state", testClient)
+		assertErrorAs[*model.BadCredentialsError](t, err)
+
+		if _, _, err := s.LoginOidc(code, state, testClient); err != nil {
+			t.Fatalf("LoginOidc() error = %v", err)
+		}
+		// Every login can only be completed once
+		_, code = f.authorize(authorizationURL, nil)
+		_, _, err = s.LoginOidc(code, state, testClient)
+		assertErrorAs[*model.BadCredentialsError](t, err)
+	})
+
+	t.Run("pkce", func(t *testing.T) {
+		// A code issued to another login is useless without its verifier
+		first, err := s.StartOidcLogin("fake")
+		if err != nil {
+			t.Fatalf("StartOidcLogin() error = %v", err)
+		}
+		second, err := s.StartOidcLogin("fake")
+		if err != nil {
+			t.Fatalf("StartOidcLogin() error = %v", err)
+		}
+		_, code := f.authorize(first, nil)
+		state, _ := f.authorize(second, nil)
+
+		_, _, err = s.LoginOidc(code, state, testClient)
+		assertErrorAs[*model.BadCredentialsError](t, err)
+	})
+
+	t.Run("nonce", func(t *testing.T) {
+		_, _, err := oidcLogin(t, s, f, "fake", jwt.MapClaims{"nonce": "replayed-nonce"})
+		assertErrorAs[*model.BadCredentialsError](t, err)
+		_, _, err = oidcLogin(t, s, f, "fake", jwt.MapClaims{"nonce": nil})
+		assertErrorAs[*model.BadCredentialsError](t, err)
+	})
+
+	t.Run("issuer and audience", func(t *testing.T) {
+		_, _, err := oidcLogin(t, s, f, "fake", jwt.MapClaims{"iss": "https://attacker.example.com"})
+		assertErrorAs[*model.BadCredentialsError](t, err)
+		_, _, err = oidcLogin(t, s, f, "fake", jwt.MapClaims{"aud": "another-client"})
+		assertErrorAs[*model.BadCredentialsError](t, err)
+	})
+}
+
+func TestLoginOidcTenantIssuer(t *testing.T) {
+	s, _ := newTestService(t)
+	f := newFakeOidcProvider(t, "tenants", "/common/v2.0", "/{tenantid}/v2.0")
+
+	_, _, err := oidcLogin(t, s, f, "tenants", jwt.MapClaims{
+		"iss": f.server.URL + "/tenant-a/v2.0",
+		"tid": "tenant-a",
+	})
+	if err != nil {
+		t.Fatalf("LoginOidc() error = %v", err)
+	}
+
+	// The issuer must be the one of the tenant the token claims
+	_, _, err = oidcLogin(t, s, f, "tenants", jwt.MapClaims{
+		"iss": f.server.URL + "/tenant-b/v2.0",
+		"tid": "tenant-a",
+	})
+	assertErrorAs[*model.BadCredentialsError](t, err)
+	_, _, err = oidcLogin(t, s, f, "tenants", jwt.MapClaims{
+		"iss": f.server.URL + "/tenant-a/v2.0",
+	})
+	assertErrorAs[*model.BadCredentialsError](t, err)
+}
+
+func TestLoginOidcEmailVerified(t *testing.T) {
+	s, _ := newTestService(t)
+	f := newFakeOidcProvider(t, "fake", "", "")
+
+	// Without a verified email there is nothing to link or create the
+	// account by
+	for _, verified := range []interface{}{false, "false", nil} {
+		_, _, err := oidcLogin(t, s, f, "fake", jwt.MapClaims{"email_verified": verified})
+		assertErrorAs[*model.BadCredentialsError](t, err)
+	}
+	_, _, err := oidcLogin(t, s, f, "fake", jwt.MapClaims{"email": nil})
+	assertErrorAs[*model.BadCredentialsError](t, err)
+	if _, err := s.professores.FindByEmail("ana@example.com"); err == nil {
+		t.Fatal("professor was created without a verified email")
+	}
+
+	// Some providers send the claim as a string
+	if _, _, err := oidcLogin(t, s, f, "fake", jwt.MapClaims{"email_verified": "true"}); err != nil {
+		t.Fatalf("LoginOidc() error = %v", err)
+	}
+}
+
+func TestLoginOidcLinksExistingProfessor(t *testing.T) {
+	s, db := newTestService(t)
+	f := newFakeOidcProvider(t, "fake", "", "")
+
+	t.Run("verified", func(t *testing.T) {
+		professor := createProfessor(t, s, db, newProfessor("Bruno Lima", "bruno@example.com", 50, 30))
+		previous, _, err := s.Login(professor.Email, testPassword, testClient)
+		if err != nil {
+			t.Fatalf("Login() error = %v", err)
+		}
+
+		tokens, _, err := oidcLogin(t, s, f, "fake", jwt.MapClaims{"sub": "bruno", "email": professor.Email})
+		if err != nil {
+			t.Fatalf("LoginOidc() error = %v", err)
+		}
+		linked, err := s.Authenticate(tokens[0])
+		if err != nil {
+			t.Fatalf("Authenticate() error = %v", err)
+		}
+		if linked.Professor.ID != professor.ID {
+			t.Errorf("professor = %d, want %d", linked.Professor.ID, professor.ID)
+		}
+
+		// The owner of the account keeps their password and sessions
+		login(t, s, professor.Email)
+		if _, err := s.Authenticate(previous[0]); err != nil {
+			t.Errorf("Authenticate() of the previous session error = %v", err)
+		}
+	})
+
+	t.Run("unverified", func(t *testing.T) {
+		// Someone registered the email without owning it
+		squatter, err := s.CreateProfessor(newProfessor("Carla Dias", "carla@example.com", 50, 30), testPassword)
+		if err != nil {
+			t.Fatalf("CreateProfessor() error = %v", err)
+		}
+		previous, _, err := s.Login(squatter.Email, testPassword, testClient)
+		if err != nil {
+			t.Fatalf("Login() error = %v", err)
+		}
+
+		tokens, _, err := oidcLogin(t, s, f, "fake", jwt.MapClaims{"sub": "carla", "email": squatter.Email})
+		if err != nil {
+			t.Fatalf("LoginOidc() error = %v", err)
+		}
+		claimed, err := s.Authenticate(tokens[0])
+		if err != nil {
+			t.Fatalf("Authenticate() error = %v", err)
+		}
+		if claimed.Professor.ID != squatter.ID || !claimed.Professor.EmailVerifiedAt.Valid {
+			t.Errorf("professor = %+v", claimed.Professor)
+		}
+
+		// The provider vouches for the email, so the squatter is locked out
+		_, _, err = s.Login(squatter.Email, testPassword, testClient)
+		assertErrorAs[*model.BadCredentialsError](t, err)
+		_, err = s.Authenticate(previous[0])
+		assertErrorAs[*model.JwtTokenError](t, err)
+	})
+}
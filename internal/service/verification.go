@@ -0,0 +1,225 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/cleysonph/hyperprof/config"
+	"github.com/cleysonph/hyperprof/internal/mailer"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/validator"
+)
+
+// defaultVerifyDuration and defaultVerifyResendDelay are used when
+// VERIFY_DURATION and VERIFY_RESEND_DELAY are not configured.
+const (
+	defaultVerifyDuration    = 24 * time.Hour
+	defaultVerifyResendDelay = time.Minute
+)
+
+// VerifyEmail marks the email of the professor that received token as
+// verified, making them visible to the public.
+func (s *Service) VerifyEmail(token string) error {
+	if err := validator.ValidateEmailVerification(token); err != nil {
+		return err
+	}
+
+	verification, err := s.tokens.FindValidEmailVerification(hashOpaqueToken(token))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return invalidVerificationTokenError()
+		}
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	err = s.tokens.VerifyProfessorEmail(verification)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return invalidVerificationTokenError()
+		}
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	s.reindexProfessor(verification.ProfessorID)
+
+	return nil
+}
+
+// ResendEmailVerification sends a new verification link to the professor
+// registered with email. Like ForgotPassword it does not reveal whether the
+// email is registered: a professor only receives one link per
+// VERIFY_RESEND_DELAY, but requests made within it are silently ignored.
+func (s *Service) ResendEmailVerification(email string) error {
+	if err := validator.ValidateEmail(email); err != nil {
+		return err
+	}
+
+	professor, err := s.professores.FindByEmail(email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	if professor.EmailVerifiedAt.Valid || professor.SuspendedAt.Valid {
+		return nil
+	}
+
+	if s.tokens.ExistsRecentEmailVerification(professor.ID, int64(verifyResendDelay().Seconds())) {
+		return nil
+	}
+
+	if err := s.sendEmailVerification(professor); err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) sendEmailVerification(professor *model.Professor) error {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	verification := &model.EmailVerification{
+		ProfessorID: professor.ID,
+		TokenHash:   hashOpaqueToken(token),
+		ExpiresAt:   time.Now().Add(verifyDuration()),
+	}
+	if err := s.tokens.CreateEmailVerification(verification); err != nil {
+		return err
+	}
+
+	return mailer.Send(&mailer.Message{
+		To:      professor.Email,
+		Subject: "Confirme o seu email",
+		Body: fmt.Sprintf(
+			"Olá, %s.\n\nConfirme o seu email para que o seu perfil apareça nas buscas. Use o link abaixo até %s:\n\n%s/verificar-email?token=%s\n",
+			professor.Nome,
+			verification.ExpiresAt.In(config.Location).Format("02/01/2006 15:04"),
+			config.AppURL,
+			token,
+		),
+	})
+}
+
+// VerifyEstudanteEmail marks the email of the student that received token as
+// verified, linking the bookings made with it to their account.
+func (s *Service) VerifyEstudanteEmail(token string) error {
+	if err := validator.ValidateEmailVerification(token); err != nil {
+		return err
+	}
+
+	verification, err := s.tokens.FindValidEstudanteEmailVerification(hashOpaqueToken(token))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return invalidVerificationTokenError()
+		}
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	err = s.tokens.VerifyEstudanteEmail(verification)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return invalidVerificationTokenError()
+		}
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// ResendEstudanteEmailVerification sends a new verification link to the
+// student registered with email, under the same rules as
+// ResendEmailVerification.
+func (s *Service) ResendEstudanteEmailVerification(email string) error {
+	if err := validator.ValidateEmail(email); err != nil {
+		return err
+	}
+
+	estudante, err := s.alunos.FindEstudanteByEmail(email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	if estudante.EmailVerifiedAt.Valid {
+		return nil
+	}
+
+	if s.tokens.ExistsRecentEstudanteEmailVerification(estudante.ID, int64(verifyResendDelay().Seconds())) {
+		return nil
+	}
+
+	if err := s.sendEstudanteEmailVerification(estudante); err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) sendEstudanteEmailVerification(estudante *model.Estudante) error {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	verification := &model.EstudanteEmailVerification{
+		EstudanteID: estudante.ID,
+		TokenHash:   hashOpaqueToken(token),
+		ExpiresAt:   time.Now().Add(verifyDuration()),
+	}
+	if err := s.tokens.CreateEstudanteEmailVerification(verification); err != nil {
+		return err
+	}
+
+	return mailer.Send(&mailer.Message{
+		To:      estudante.Email,
+		Subject: "Confirme o seu email",
+		Body: fmt.Sprintf(
+			"Olá, %s.\n\nConfirme o seu email para ver as aulas agendadas com ele na sua conta. Use o link abaixo até %s:\n\n%s/alunos/verificar-email?token=%s\n",
+			estudante.Nome,
+			verification.ExpiresAt.In(config.Location).Format("02/01/2006 15:04"),
+			config.AppURL,
+			token,
+		),
+	})
+}
+
+func invalidVerificationTokenError() error {
+	validationErr := &model.ValidationError{}
+	validationErr.AddError("token", "is invalid or expired")
+	return validationErr
+}
+
+func verifyDuration() time.Duration {
+	if config.VerifyDuration <= 0 {
+		return defaultVerifyDuration
+	}
+	return time.Duration(config.VerifyDuration) * time.Second
+}
+
+func verifyResendDelay() time.Duration {
+	if config.VerifyResendDelay <= 0 {
+		return defaultVerifyResendDelay
+	}
+	return time.Duration(config.VerifyResendDelay) * time.Second
+}
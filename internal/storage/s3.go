@@ -0,0 +1,355 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3Service         = "s3"
+	s3DateFormat      = "20060102T150405Z"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// s3Timeout bounds every request made to the bucket.
+const s3Timeout = 30 * time.Second
+
+// S3Config points to a bucket of an S3 compatible service, such as AWS S3
+// or MinIO.
+type S3Config struct {
+	// Endpoint is the base URL of the service, e.g. https://s3.amazonaws.com
+	// or http://localhost:9000.
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle addresses the bucket as a path of the endpoint instead of a
+	// subdomain, which MinIO and most stand-ins require.
+	PathStyle bool
+	// PresignExpiry is how long the URLs returned by URL stay valid. When it
+	// is zero, files are served by the API under ProxyURL instead, which
+	// suits private buckets unreachable by clients.
+	PresignExpiry time.Duration
+	ProxyURL      string
+}
+
+type s3Storage struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+// NewS3Storage returns a Storage keeping the files in an S3 bucket. Requests
+// are signed with AWS Signature Version 4.
+func NewS3Storage(config S3Config) (Storage, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("storage: invalid S3 endpoint %q", config.Endpoint)
+	}
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("storage: S3 bucket is required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	return &s3Storage{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: s3Timeout},
+	}, nil
+}
+
+// Put buffers body to sign its hash, files are expected to be small.
+func (s *s3Storage) Put(key string, body io.Reader, size int64, contentType string) error {
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	headers := http.Header{}
+	if contentType != "" {
+		headers.Set("Content-Type", contentType)
+	}
+	res, err := s.do(http.MethodPut, key, payload, headers)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return checkS3Response(res)
+}
+
+func (s *s3Storage) Get(key string) (*Object, error) {
+	res, err := s.do(http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, ErrNotFound
+	}
+	if err := checkS3Response(res); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+
+	return &Object{
+		Body:        res.Body,
+		ContentType: res.Header.Get("Content-Type"),
+		Size:        res.ContentLength,
+	}, nil
+}
+
+func (s *s3Storage) Delete(key string) error {
+	res, err := s.do(http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return checkS3Response(res)
+}
+
+// listObjectsResult is the response of ListObjectsV2.
+type listObjectsResult struct {
+	Contents []struct {
+		Key          string
+		Size         int64
+		LastModified time.Time
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+func (s *s3Storage) List(prefix string) ([]*ObjectInfo, error) {
+	objects := []*ObjectInfo{}
+	query := map[string]string{
+		"list-type": "2",
+		"prefix":    prefix,
+	}
+	for {
+		u := s.objectURL("")
+		u.RawQuery = canonicalQueryString(query)
+		res, err := s.send(http.MethodGet, u, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkS3Response(res); err != nil {
+			res.Body.Close()
+			return nil, err
+		}
+
+		result := &listObjectsResult{}
+		err = xml.NewDecoder(res.Body).Decode(result)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, &ObjectInfo{
+				Key:        content.Key,
+				Size:       content.Size,
+				ModifiedAt: content.LastModified,
+			})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		query["continuation-token"] = result.NextContinuationToken
+	}
+}
+
+func (s *s3Storage) URL(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	if s.config.PresignExpiry <= 0 {
+		return proxyURL(s.config.ProxyURL, key), nil
+	}
+	return s.presign(http.MethodGet, key, s.config.PresignExpiry, time.Now()), nil
+}
+
+// objectURL returns the URL of the object stored under key.
+func (s *s3Storage) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	base := strings.TrimSuffix(u.Path, "/")
+	if s.config.PathStyle {
+		u.Path = base + "/" + s.config.Bucket + "/" + key
+		u.RawPath = escapePath(base + "/" + s.config.Bucket + "/" + key)
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+		u.Path = base + "/" + key
+		u.RawPath = escapePath(base + "/" + key)
+	}
+	return &u
+}
+
+func (s *s3Storage) do(method, key string, payload []byte, headers http.Header) (*http.Response, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	return s.send(method, s.objectURL(key), payload, headers)
+}
+
+// send signs and sends a request to u, whose query must already be in its
+// canonical form.
+func (s *s3Storage) send(method string, u *url.URL, payload []byte, headers http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+
+	now := time.Now().UTC()
+	payloadHash := hashHex(payload)
+	req.Header.Set("X-Amz-Date", now.Format(s3DateFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + u.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + now.Format(s3DateFormat) + "\n"
+	canonicalRequest := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		u.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := s.scope(now)
+	signature := s.sign(now, scope, canonicalRequest)
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.config.AccessKey, scope, signedHeaders, signature,
+	))
+
+	return s.client.Do(req)
+}
+
+// presign returns a URL granting method on the object to anyone holding it
+// until expiry elapses.
+func (s *s3Storage) presign(method, key string, expiry time.Duration, now time.Time) string {
+	now = now.UTC()
+	u := s.objectURL(key)
+	scope := s.scope(now)
+
+	query := map[string]string{
+		"X-Amz-Algorithm":     s3Algorithm,
+		"X-Amz-Credential":    s.config.AccessKey + "/" + scope,
+		"X-Amz-Date":          now.Format(s3DateFormat),
+		"X-Amz-Expires":       strconv.FormatInt(int64(expiry/time.Second), 10),
+		"X-Amz-SignedHeaders": "host",
+	}
+	canonicalQuery := canonicalQueryString(query)
+	canonicalRequest := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		canonicalQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+
+	u.RawQuery = canonicalQuery + "&X-Amz-Signature=" + s.sign(now, scope, canonicalRequest)
+	return u.String()
+}
+
+func (s *s3Storage) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.config.Region + "/" + s3Service + "/aws4_request"
+}
+
+func (s *s3Storage) sign(now time.Time, scope, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		now.Format(s3DateFormat),
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func checkS3Response(res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+	return fmt.Errorf("storage: S3 responded %s: %s", res.Status, strings.TrimSpace(string(body)))
+}
+
+func canonicalQueryString(query map[string]string) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = uriEncode(key, true) + "=" + uriEncode(query[key], true)
+	}
+	return strings.Join(pairs, "&")
+}
+
+// escapePath encodes every segment of path the way Signature Version 4
+// expects, keeping the slashes.
+func escapePath(path string) string {
+	return uriEncode(path, false)
+}
+
+// uriEncode percent-encodes everything but the unreserved characters of RFC
+// 3986, and slashes unless encodeSlash is set.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
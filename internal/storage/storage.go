@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by Get when no object is stored under the key.
+var ErrNotFound = errors.New("storage: object not found")
+
+// ErrInvalidKey is returned for keys that could escape the storage, such as
+// absolute paths or keys holding "..".
+var ErrInvalidKey = errors.New("storage: invalid key")
+
+// Object is the content of a stored file. Body must be closed by the caller.
+type Object struct {
+	Body        io.ReadCloser
+	ContentType string
+	Size        int64
+}
+
+// ObjectInfo describes a stored file, as returned by List.
+type ObjectInfo struct {
+	Key        string
+	Size       int64
+	ModifiedAt time.Time
+}
+
+// Storage keeps the files uploaded to the API under a key, such as
+// "fotos/8c4f0a1e.jpg".
+type Storage interface {
+	Put(key string, body io.Reader, size int64, contentType string) error
+	Get(key string) (*Object, error)
+	Delete(key string) error
+	// List returns the files whose key starts with prefix.
+	List(prefix string) ([]*ObjectInfo, error)
+	// URL returns where clients can download the file from.
+	URL(key string) (string, error)
+}
+
+var storage Storage = NewLocalStorage("uploads", "")
+
+// Init sets the storage used by the package functions.
+func Init(s Storage) {
+	storage = s
+}
+
+func Put(key string, body io.Reader, size int64, contentType string) error {
+	return storage.Put(key, body, size, contentType)
+}
+
+func Get(key string) (*Object, error) {
+	return storage.Get(key)
+}
+
+func Delete(key string) error {
+	return storage.Delete(key)
+}
+
+func List(prefix string) ([]*ObjectInfo, error) {
+	return storage.List(prefix)
+}
+
+func URL(key string) (string, error) {
+	return storage.URL(key)
+}
+
+// ValidKey reports whether key is a relative path made of plain segments.
+func ValidKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// proxyURL returns the URL of the route of the API serving the file through
+// the storage.
+func proxyURL(baseURL, key string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + escapePath(key)
+}
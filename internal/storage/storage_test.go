@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"fotos/a.jpg", true},
+		{"a.jpg", true},
+		{"fotos/2024/a b.jpg", true},
+		{"fotos/..a.jpg", true},
+		{"", false},
+		{"/fotos/a.jpg", false},
+		{"fotos/", false},
+		{"fotos//a.jpg", false},
+		{"./a.jpg", false},
+		{"fotos/../a.jpg", false},
+		{"..", false},
+		{"fotos\\a.jpg", false},
+		{"..\\a.jpg", false},
+	}
+	for _, tt := range tests {
+		if got := ValidKey(tt.key); got != tt.want {
+			t.Errorf("ValidKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "uploads")
+	s := NewLocalStorage(dir, "http://localhost/api/arquivos/")
+
+	// A missing directory holds no files yet
+	objects, err := s.List("")
+	if err != nil || len(objects) != 0 {
+		t.Fatalf("List() = %v, %v", objects, err)
+	}
+
+	keys := []string{"fotos/a.jpg", "fotos/b.webp", "outros/c.txt"}
+	for _, key := range keys {
+		if err := s.Put(key, strings.NewReader("content of "+key), -1, ""); err != nil {
+			t.Fatalf("Put(%s) error = %v", key, err)
+		}
+	}
+
+	object, err := s.Get("fotos/b.webp")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	body, _ := io.ReadAll(object.Body)
+	object.Body.Close()
+	if string(body) != "content of fotos/b.webp" || object.ContentType != "image/webp" || object.Size != int64(len(body)) {
+		t.Errorf("Get() = %q, %q, %d", body, object.ContentType, object.Size)
+	}
+
+	for _, key := range []string{"fotos/missing.jpg", "fotos"} {
+		if _, err := s.Get(key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%s) error = %v, want ErrNotFound", key, err)
+		}
+	}
+
+	t.Run("list", func(t *testing.T) {
+		// Uploads still being written are left out
+		if err := os.WriteFile(filepath.Join(dir, "fotos", ".upload-123"), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		objects, err := s.List("fotos/")
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		listed := []string{}
+		for _, object := range objects {
+			listed = append(listed, object.Key)
+			if object.Size != int64(len("content of "+object.Key)) || object.ModifiedAt.IsZero() {
+				t.Errorf("object = %+v", object)
+			}
+		}
+		if strings.Join(listed, ",") != "fotos/a.jpg,fotos/b.webp" {
+			t.Errorf("List() = %q", listed)
+		}
+	})
+
+	t.Run("keys escaping the directory", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(root, "secret.txt"), []byte("secret"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, key := range []string{"../secret.txt", "fotos/../../secret.txt", "/secret.txt", "..\\secret.txt"} {
+			if err := s.Put(key, strings.NewReader("x"), 1, ""); err != ErrInvalidKey {
+				t.Errorf("Put(%s) error = %v, want ErrInvalidKey", key, err)
+			}
+			if _, err := s.Get(key); err != ErrInvalidKey {
+				t.Errorf("Get(%s) error = %v, want ErrInvalidKey", key, err)
+			}
+			if err := s.Delete(key); err != ErrInvalidKey {
+				t.Errorf("Delete(%s) error = %v, want ErrInvalidKey", key, err)
+			}
+			if _, err := s.URL(key); err != ErrInvalidKey {
+				t.Errorf("URL(%s) error = %v, want ErrInvalidKey", key, err)
+			}
+		}
+		if content, err := os.ReadFile(filepath.Join(root, "secret.txt")); err != nil || string(content) != "secret" {
+			t.Errorf("secret.txt = %q, %v", content, err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := s.Delete("outros/c.txt"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		// The directories left empty go away, the storage itself stays
+		if _, err := os.Stat(filepath.Join(dir, "outros")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("empty directory was kept: %v", err)
+		}
+		if _, err := os.Stat(dir); err != nil {
+			t.Errorf("storage directory was removed: %v", err)
+		}
+		if err := s.Delete("outros/c.txt"); err != nil {
+			t.Errorf("Delete() of a missing file error = %v", err)
+		}
+	})
+
+	t.Run("url", func(t *testing.T) {
+		u, err := s.URL("fotos/a b.jpg")
+		if err != nil || u != "http://localhost/api/arquivos/fotos/a%20b.jpg" {
+			t.Errorf("URL() = %s, %v", u, err)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+)
+
+type principalKey struct{}
+
+// Authenticator validates the credentials sent with the request and returns
+// the principal they belong to.
+type Authenticator func(r *http.Request) (*model.Principal, error)
+
+// ErrorWriter writes err as the response to the request.
+type ErrorWriter func(w http.ResponseWriter, err error)
+
+// WithPrincipal returns a copy of ctx carrying principal.
+func WithPrincipal(ctx context.Context, principal *model.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored by Authenticate, if any.
+func PrincipalFromContext(ctx context.Context) (*model.Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*model.Principal)
+	return principal, ok && principal != nil
+}
+
+// Authenticate rejects the request unless authenticate succeeds and makes the
+// resulting principal available to the next handlers through the context.
+func Authenticate(authenticate Authenticator, writeError ErrorWriter) func(http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := authenticate(r)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+
+			handler.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// AuthenticateIf authenticates the request like Authenticate, but only when
+// present reports it carries credentials. Anonymous requests reach handler
+// untouched, so public routes can still tell who is calling them.
+func AuthenticateIf(present func(r *http.Request) bool, authenticate Authenticator, writeError ErrorWriter) func(http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		authenticated := Authenticate(authenticate, writeError)(handler)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if present(r) {
+				authenticated.ServeHTTP(w, r)
+				return
+			}
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireScope refuses API keys that were not granted scope. Other callers,
+// anonymous or not, are left to the checks of the handler.
+func RequireScope(writeError ErrorWriter, scope string) func(http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if ok && principal.ApiKey != nil && !principal.ApiKey.HasScope(scope) {
+				writeError(w, &model.ForbiddenError{
+					Message: "Api key is missing the " + scope + " scope",
+				})
+				return
+			}
+
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRoles only lets the request reach handler when the authenticated
+// principal holds one of roles. It must run after Authenticate.
+func RequireRoles(writeError ErrorWriter, roles ...string) func(http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				writeError(w, &model.JwtTokenError{
+					Message: "Authentication required",
+				})
+				return
+			}
+
+			for _, allowed := range roles {
+				if principal.Role == allowed {
+					handler.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			writeError(w, &model.ForbiddenError{
+				Message: "Role " + principal.Role + " is not allowed to access this resource",
+			})
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/ratelimit"
+	"github.com/rs/zerolog/log"
+)
+
+// ClientIP returns the address a request comes from.
+type ClientIP func(r *http.Request) string
+
+// RateLimit counts the request against policy and rejects it once the bucket
+// of the client is empty. Authenticated callers have a bucket of their own,
+// anyone else shares the bucket of their IP address. The RateLimit headers
+// tell clients how much of the policy is left.
+func RateLimit(policy ratelimit.Policy, clientIP ClientIP, writeError ErrorWriter) func(http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := "ip:" + clientIP(r)
+			if principal, ok := PrincipalFromContext(r.Context()); ok {
+				key = principal.Role + ":" + principal.Subject
+			}
+
+			result, err := ratelimit.Take(key, policy)
+			if err != nil {
+				// An unavailable store must not take the whole API down
+				log.Error().Err(err).Str("policy", policy.Name).Msg("failed to check rate limit")
+				handler.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, int64(policy.Period/time.Second)))
+			header.Set("RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+			header.Set("RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+			header.Set("RateLimit-Reset", strconv.FormatInt(seconds(result.Reset), 10))
+
+			if !result.Allowed {
+				writeError(w, &model.TooManyRequestsError{
+					Message:    "Rate limit exceeded, try again later",
+					RetryAfter: result.RetryAfter,
+				})
+				return
+			}
+
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
+
+// seconds rounds d up to whole seconds, so clients never retry too early.
+func seconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
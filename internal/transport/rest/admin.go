@@ -0,0 +1,170 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/cleysonph/hyperprof/internal/service"
+)
+
+func postAdminLogin(w http.ResponseWriter, r *http.Request) {
+	loginRequest := &loginRequest{}
+	if err := readJSON(r, &loginRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	tokens, err := service.LoginAdministrador(loginRequest.Email, loginRequest.Password, getSessionClient(r, loginRequest.Device))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := &loginResponse{
+		Token:        tokens[0],
+		RefreshToken: tokens[1],
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func getAdminProfessores(w http.ResponseWriter, r *http.Request) {
+	professores, err := service.FindAllProfessoresByAdminPrincipal(getPrincipal(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, professores)
+}
+
+func getAdminBloqueios(w http.ResponseWriter, r *http.Request) {
+	attempts, err := service.GetLockedLoginsByAdminPrincipal(getPrincipal(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, attempts)
+}
+
+func postAdminProfessorSuspensao(w http.ResponseWriter, r *http.Request) {
+	professorID, err := getInt64UrlParam(w, r, "professorID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	professor, err := service.SuspendProfessorByAdminPrincipal(getPrincipal(r), professorID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, professor)
+}
+
+func deleteAdminProfessorSuspensao(w http.ResponseWriter, r *http.Request) {
+	professorID, err := getInt64UrlParam(w, r, "professorID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	professor, err := service.ReactivateProfessorByAdminPrincipal(getPrincipal(r), professorID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, professor)
+}
+
+func deleteAdminProfessor(w http.ResponseWriter, r *http.Request) {
+	professorID, err := getInt64UrlParam(w, r, "professorID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	err = service.DeleteProfessorByAdminPrincipal(getPrincipal(r), professorID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func getAdminApiKeys(w http.ResponseWriter, r *http.Request) {
+	apiKeys, err := service.FindAllApiKeysByAdminPrincipal(getPrincipal(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, apiKeys)
+}
+
+func postAdminApiKey(w http.ResponseWriter, r *http.Request) {
+	apiKeyRequest := &apiKeyRequest{}
+	if err := readJSON(r, &apiKeyRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	apiKey, key, err := service.CreateApiKeyByAdminPrincipal(getPrincipal(r), apiKeyRequest.ToModel())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, &apiKeyCreatedResponse{ApiKey: apiKey, Key: key})
+}
+
+func deleteAdminApiKey(w http.ResponseWriter, r *http.Request) {
+	apiKeyID, err := getInt64UrlParam(w, r, "apiKeyID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	err = service.RevokeApiKeyByAdminPrincipal(getPrincipal(r), apiKeyID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func postAdminDisciplina(w http.ResponseWriter, r *http.Request) {
+	disciplinaRequest := &disciplinaRequest{}
+	if err := readJSON(r, &disciplinaRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	disciplina, err := service.CreateDisciplinaByAdminPrincipal(getPrincipal(r), disciplinaRequest.ToModel())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, disciplina)
+}
+
+func deleteAdminDisciplina(w http.ResponseWriter, r *http.Request) {
+	disciplinaID, err := getInt64UrlParam(w, r, "disciplinaID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	err = service.DeleteDisciplinaByAdminPrincipal(getPrincipal(r), disciplinaID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,33 @@
+package rest
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/cleysonph/hyperprof/internal/service"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+func getArquivo(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	object, err := service.GetArquivo(key)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer object.Body.Close()
+
+	w.Header().Set("Content-Type", object.ContentType)
+	if object.Size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(object.Size, 10))
+	}
+	// Keys are never reused for another content
+	w.Header().Set("Cache-Control", "public, max-age=86400, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, object.Body); err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("failed to send file")
+	}
+}
@@ -0,0 +1,173 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/cleysonph/hyperprof/internal/service"
+)
+
+func postProfessorAlunoCancelar(w http.ResponseWriter, r *http.Request) {
+	alunoID, err := getInt64UrlParam(w, r, "alunoID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	cancelamentoRequest := &cancelamentoRequest{}
+	if err := readJSON(r, &cancelamentoRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	aluno, err := service.CancelAlunoByPrincipal(getPrincipal(r), alunoID, cancelamentoRequest.Motivo)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, aluno)
+}
+
+func postProfessorAlunoReagendar(w http.ResponseWriter, r *http.Request) {
+	alunoID, err := getInt64UrlParam(w, r, "alunoID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	reagendamentoRequest := &reagendamentoRequest{}
+	if err := readJSON(r, &reagendamentoRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	aluno, err := service.RescheduleAlunoByPrincipal(
+		getPrincipal(r),
+		alunoID,
+		reagendamentoRequest.DataAula,
+		reagendamentoRequest.Motivo,
+	)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, aluno)
+}
+
+func postProfessorAlunoConcluir(w http.ResponseWriter, r *http.Request) {
+	alunoID, err := getInt64UrlParam(w, r, "alunoID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	aluno, err := service.CompleteAlunoByPrincipal(getPrincipal(r), alunoID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, aluno)
+}
+
+func getProfessorAlunoHistorico(w http.ResponseWriter, r *http.Request) {
+	alunoID, err := getInt64UrlParam(w, r, "alunoID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	historico, err := service.GetAlunoHistoricoByPrincipal(getPrincipal(r), alunoID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, historico)
+}
+
+func getAluno(w http.ResponseWriter, r *http.Request) {
+	alunoID, err := getInt64UrlParam(w, r, "alunoID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	aluno, err := service.GetAlunoBySignature(alunoID, getStringQueryParam(w, r, "assinatura"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, aluno)
+}
+
+func postAlunoCancelar(w http.ResponseWriter, r *http.Request) {
+	alunoID, err := getInt64UrlParam(w, r, "alunoID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	cancelamentoRequest := &cancelamentoRequest{}
+	if err := readJSON(r, &cancelamentoRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	aluno, err := service.CancelAlunoBySignature(
+		alunoID,
+		getStringQueryParam(w, r, "assinatura"),
+		cancelamentoRequest.Motivo,
+	)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, aluno)
+}
+
+func postAlunoReagendar(w http.ResponseWriter, r *http.Request) {
+	alunoID, err := getInt64UrlParam(w, r, "alunoID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	reagendamentoRequest := &reagendamentoRequest{}
+	if err := readJSON(r, &reagendamentoRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	aluno, err := service.RescheduleAlunoBySignature(
+		alunoID,
+		getStringQueryParam(w, r, "assinatura"),
+		reagendamentoRequest.DataAula,
+		reagendamentoRequest.Motivo,
+	)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, aluno)
+}
+
+func getAlunoHistorico(w http.ResponseWriter, r *http.Request) {
+	alunoID, err := getInt64UrlParam(w, r, "alunoID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	historico, err := service.GetAlunoHistoricoBySignature(alunoID, getStringQueryParam(w, r, "assinatura"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, historico)
+}
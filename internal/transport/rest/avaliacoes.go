@@ -0,0 +1,103 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/cleysonph/hyperprof/internal/service"
+)
+
+func getProfessorAvaliacoes(w http.ResponseWriter, r *http.Request) {
+	professorID, err := getInt64UrlParam(w, r, "professorID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	page, err := getInt32QueryParam(w, r, "page")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	limit, err := getInt32QueryParam(w, r, "limit")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	avaliacoes, err := service.FindAvaliacoesByProfessorID(professorID, page, limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, avaliacoes)
+}
+
+func postAlunoAvaliacao(w http.ResponseWriter, r *http.Request) {
+	alunoID, err := getInt64UrlParam(w, r, "alunoID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	avaliacaoRequest := &avaliacaoRequest{}
+	if err := readJSON(r, &avaliacaoRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	avaliacao, err := service.CreateAvaliacaoBySignature(
+		alunoID,
+		getStringQueryParam(w, r, "assinatura"),
+		avaliacaoRequest.ToModel(),
+	)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, avaliacao)
+}
+
+func postEstudanteAulaAvaliacao(w http.ResponseWriter, r *http.Request) {
+	alunoID, err := getInt64UrlParam(w, r, "alunoID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	avaliacaoRequest := &avaliacaoRequest{}
+	if err := readJSON(r, &avaliacaoRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	avaliacao, err := service.CreateAvaliacaoByEstudantePrincipal(getPrincipal(r), alunoID, avaliacaoRequest.ToModel())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, avaliacao)
+}
+
+func postAvaliacaoResposta(w http.ResponseWriter, r *http.Request) {
+	avaliacaoID, err := getInt64UrlParam(w, r, "avaliacaoID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	respostaRequest := &respostaRequest{}
+	if err := readJSON(r, &respostaRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	avaliacao, err := service.AnswerAvaliacaoByPrincipal(getPrincipal(r), avaliacaoID, respostaRequest.Resposta)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, avaliacao)
+}
@@ -0,0 +1,49 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/cleysonph/hyperprof/internal/service"
+)
+
+func getDisciplinas(w http.ResponseWriter, r *http.Request) {
+	disciplinas, err := service.FindAllDisciplinas()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, disciplinas)
+}
+
+func putProfessorDisciplinas(w http.ResponseWriter, r *http.Request) {
+	disciplinasRequest := &professorDisciplinasRequest{}
+	if err := readJSON(r, &disciplinasRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	disciplinas, err := service.SetDisciplinasByPrincipal(getPrincipal(r), disciplinasRequest.DisciplinaIDs)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, disciplinas)
+}
+
+func deleteProfessorDisciplina(w http.ResponseWriter, r *http.Request) {
+	disciplinaID, err := getInt64UrlParam(w, r, "disciplinaID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	err = service.DeleteDisciplinaByPrincipal(getPrincipal(r), disciplinaID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/cleysonph/hyperprof/internal/service"
+)
+
+func getProfessorDisponibilidade(w http.ResponseWriter, r *http.Request) {
+	professorID, err := getInt64UrlParam(w, r, "professorID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	disponibilidades, excecoes, err := service.FindDisponibilidadesByProfessorID(professorID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &disponibilidadeResponse{Semanal: disponibilidades, Excecoes: excecoes})
+}
+
+func getDisponibilidade(w http.ResponseWriter, r *http.Request) {
+	disponibilidades, excecoes, err := service.GetDisponibilidadesByPrincipal(getPrincipal(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &disponibilidadeResponse{Semanal: disponibilidades, Excecoes: excecoes})
+}
+
+func postDisponibilidade(w http.ResponseWriter, r *http.Request) {
+	disponibilidadeRequest := &disponibilidadeRequest{}
+	if err := readJSON(r, &disponibilidadeRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	disponibilidade, err := service.CreateDisponibilidadeByPrincipal(getPrincipal(r), disponibilidadeRequest.ToModel())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, disponibilidade)
+}
+
+func deleteDisponibilidade(w http.ResponseWriter, r *http.Request) {
+	disponibilidadeID, err := getInt64UrlParam(w, r, "disponibilidadeID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	err = service.DeleteDisponibilidadeByPrincipal(getPrincipal(r), disponibilidadeID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func postDisponibilidadeExcecao(w http.ResponseWriter, r *http.Request) {
+	excecaoRequest := &disponibilidadeExcecaoRequest{}
+	if err := readJSON(r, &excecaoRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	excecao, err := service.CreateDisponibilidadeExcecaoByPrincipal(getPrincipal(r), excecaoRequest.ToModel())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, excecao)
+}
+
+func deleteDisponibilidadeExcecao(w http.ResponseWriter, r *http.Request) {
+	excecaoID, err := getInt64UrlParam(w, r, "excecaoID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	err = service.DeleteDisponibilidadeExcecaoByPrincipal(getPrincipal(r), excecaoID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
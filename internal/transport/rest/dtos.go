@@ -14,6 +14,7 @@ type alunoRequest struct {
 	Nome     string    `json:"nome"`
 	Email    string    `json:"email"`
 	DataAula time.Time `json:"data_aula"`
+	Duracao  int32     `json:"duracao"`
 }
 
 func (a *alunoRequest) ToModel() *model.Aluno {
@@ -21,33 +22,61 @@ func (a *alunoRequest) ToModel() *model.Aluno {
 		Nome:     a.Nome,
 		Email:    a.Email,
 		DataAula: a.DataAula,
+		Duracao:  a.Duracao,
 	}
 }
 
+type cancelamentoRequest struct {
+	Motivo string `json:"motivo"`
+}
+
+type reagendamentoRequest struct {
+	DataAula time.Time `json:"data_aula"`
+	Motivo   string    `json:"motivo"`
+}
+
 type professorRequest struct {
 	Nome                 string  `json:"nome"`
 	Email                string  `json:"email"`
 	Idade                int32   `json:"idade"`
 	Descricao            string  `json:"descricao"`
 	ValorHora            float64 `json:"valor_hora"`
+	DuracaoAula          int32   `json:"duracao_aula"`
 	Password             string  `json:"password"`
 	PasswordConfirmation string  `json:"password_confirmation"`
 }
 
 func (p *professorRequest) ToModel() *model.Professor {
 	return &model.Professor{
-		Nome:      p.Nome,
-		Email:     p.Email,
-		Idade:     p.Idade,
-		Descricao: p.Descricao,
-		ValorHora: p.ValorHora,
-		Password:  p.Password,
+		Nome:        p.Nome,
+		Email:       p.Email,
+		Idade:       p.Idade,
+		Descricao:   p.Descricao,
+		ValorHora:   p.ValorHora,
+		DuracaoAula: p.DuracaoAula,
+		Password:    p.Password,
+	}
+}
+
+type estudanteRequest struct {
+	Nome                 string `json:"nome"`
+	Email                string `json:"email"`
+	Password             string `json:"password"`
+	PasswordConfirmation string `json:"password_confirmation"`
+}
+
+func (e *estudanteRequest) ToModel() *model.Estudante {
+	return &model.Estudante{
+		Nome:     e.Nome,
+		Email:    e.Email,
+		Password: e.Password,
 	}
 }
 
 type loginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Device   string `json:"device"`
 }
 
 type loginResponse struct {
@@ -55,10 +84,83 @@ type loginResponse struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+type mfaChallengeResponse struct {
+	MfaRequired bool   `json:"mfa_required"`
+	MfaToken    string `json:"mfa_token"`
+}
+
+type mfaLoginRequest struct {
+	MfaToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+	Device   string `json:"device"`
+}
+
+type totpCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type totpEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
 type refreshRequest struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+type emailRequest struct {
+	Email string `json:"email"`
+}
+
+type resetPasswordRequest struct {
+	Token                string `json:"token"`
+	Password             string `json:"password"`
+	PasswordConfirmation string `json:"password_confirmation"`
+}
+
+type emailVerificationRequest struct {
+	Token string `json:"token"`
+}
+
+type disponibilidadeRequest struct {
+	DiaSemana  int32  `json:"dia_semana"`
+	HoraInicio string `json:"hora_inicio"`
+	HoraFim    string `json:"hora_fim"`
+}
+
+func (d *disponibilidadeRequest) ToModel() *model.Disponibilidade {
+	return &model.Disponibilidade{
+		DiaSemana:  d.DiaSemana,
+		HoraInicio: d.HoraInicio,
+		HoraFim:    d.HoraFim,
+	}
+}
+
+type disponibilidadeExcecaoRequest struct {
+	Inicio     time.Time `json:"inicio"`
+	Fim        time.Time `json:"fim"`
+	Disponivel bool      `json:"disponivel"`
+	Motivo     string    `json:"motivo"`
+}
+
+func (d *disponibilidadeExcecaoRequest) ToModel() *model.DisponibilidadeExcecao {
+	return &model.DisponibilidadeExcecao{
+		Inicio:     d.Inicio,
+		Fim:        d.Fim,
+		Disponivel: d.Disponivel,
+		Motivo:     d.Motivo,
+	}
+}
+
+type disponibilidadeResponse struct {
+	Semanal  []*model.Disponibilidade        `json:"semanal"`
+	Excecoes []*model.DisponibilidadeExcecao `json:"excecoes"`
+}
+
 type errorResponse struct {
 	Message   string    `json:"message"`
 	Timestamp time.Time `json:"timestamp"`
@@ -71,3 +173,67 @@ type validationErrorResponse struct {
 	errorResponse
 	Errors map[string][]string `json:"errors"`
 }
+
+type apiKeyRequest struct {
+	Nome   string   `json:"nome"`
+	Scopes []string `json:"scopes"`
+}
+
+func (a *apiKeyRequest) ToModel() *model.ApiKey {
+	return &model.ApiKey{
+		Nome:   a.Nome,
+		Scopes: a.Scopes,
+	}
+}
+
+// apiKeyCreatedResponse is the only response that carries the key itself.
+type apiKeyCreatedResponse struct {
+	*model.ApiKey
+	Key string `json:"key"`
+}
+
+type avaliacaoRequest struct {
+	Nota       int32  `json:"nota"`
+	Comentario string `json:"comentario"`
+}
+
+func (a *avaliacaoRequest) ToModel() *model.Avaliacao {
+	return &model.Avaliacao{
+		Nota:       a.Nota,
+		Comentario: a.Comentario,
+	}
+}
+
+type respostaRequest struct {
+	Resposta string `json:"resposta"`
+}
+
+type disciplinaRequest struct {
+	Nome     string `json:"nome"`
+	ParentID *int64 `json:"parent_id"`
+}
+
+func (d *disciplinaRequest) ToModel() *model.Disciplina {
+	disciplina := &model.Disciplina{
+		Nome: d.Nome,
+	}
+	if d.ParentID != nil {
+		disciplina.ParentID.Int64 = *d.ParentID
+		disciplina.ParentID.Valid = true
+	}
+	return disciplina
+}
+
+type professorDisciplinasRequest struct {
+	DisciplinaIDs []int64 `json:"disciplina_ids"`
+}
+
+type oidcAuthorizationResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+type oidcCallbackRequest struct {
+	Code   string `json:"code"`
+	State  string `json:"state"`
+	Device string `json:"device"`
+}
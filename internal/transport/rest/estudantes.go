@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/cleysonph/hyperprof/internal/service"
+)
+
+func postEstudante(w http.ResponseWriter, r *http.Request) {
+	estudanteRequest := &estudanteRequest{}
+	if err := readJSON(r, &estudanteRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	estudante, err := service.CreateEstudante(estudanteRequest.ToModel(), estudanteRequest.PasswordConfirmation)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, estudante)
+}
+
+func getEstudanteMe(w http.ResponseWriter, r *http.Request) {
+	estudante, err := service.GetEstudanteByPrincipal(getPrincipal(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, estudante)
+}
+
+func getEstudanteAulas(w http.ResponseWriter, r *http.Request) {
+	aulas, err := service.GetAulasByPrincipal(getPrincipal(r), getStringQueryParam(w, r, "status"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, aulas)
+}
+
+func postEstudanteLogin(w http.ResponseWriter, r *http.Request) {
+	loginRequest := &loginRequest{}
+	if err := readJSON(r, &loginRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	tokens, err := service.LoginEstudante(loginRequest.Email, loginRequest.Password, getSessionClient(r, loginRequest.Device))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := &loginResponse{
+		Token:        tokens[0],
+		RefreshToken: tokens[1],
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
@@ -3,11 +3,18 @@ package rest
 import (
 	"net/http"
 
+	"github.com/cleysonph/hyperprof/internal/model"
 	"github.com/cleysonph/hyperprof/internal/service"
 )
 
 func getProfessores(w http.ResponseWriter, r *http.Request) {
-	professores, err := service.FindAllProfessores(getStringQueryParam(w, r, "q"))
+	filter, err := getProfessorFilter(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	professores, err := service.FindAllProfessores(filter)
 	if err != nil {
 		writeError(w, err)
 		return
@@ -15,6 +22,39 @@ func getProfessores(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, professores)
 }
 
+func getProfessorFilter(w http.ResponseWriter, r *http.Request) (*model.ProfessorFilter, error) {
+	filter := &model.ProfessorFilter{
+		Q:    getStringQueryParam(w, r, "q"),
+		Nome: getStringQueryParam(w, r, "nome"),
+		Sort: getStringQueryParam(w, r, "sort"),
+	}
+
+	var err error
+	if filter.ValorHoraMin, err = getFloat64QueryParam(w, r, "valor_hora_min"); err != nil {
+		return nil, err
+	}
+	if filter.ValorHoraMax, err = getFloat64QueryParam(w, r, "valor_hora_max"); err != nil {
+		return nil, err
+	}
+	if filter.Subject, err = getInt64QueryParam(w, r, "subject"); err != nil {
+		return nil, err
+	}
+	if filter.IdadeMin, err = getInt32QueryParam(w, r, "idade_min"); err != nil {
+		return nil, err
+	}
+	if filter.IdadeMax, err = getInt32QueryParam(w, r, "idade_max"); err != nil {
+		return nil, err
+	}
+	if filter.Page, err = getInt32QueryParam(w, r, "page"); err != nil {
+		return nil, err
+	}
+	if filter.Limit, err = getInt32QueryParam(w, r, "limit"); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
 func getProfessorByID(w http.ResponseWriter, r *http.Request) {
 	professorID, err := getInt64UrlParam(w, r, "professorID")
 	if err != nil {
@@ -48,7 +88,7 @@ func postProfessor(w http.ResponseWriter, r *http.Request) {
 }
 
 func deleteProfessor(w http.ResponseWriter, r *http.Request) {
-	err := service.DeleteProfessorByToken(getTokenFromHeader(r))
+	err := service.DeleteProfessorByPrincipal(getPrincipal(r))
 	if err != nil {
 		writeError(w, err)
 		return
@@ -64,8 +104,8 @@ func putProfessor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	professor, err := service.UpdateProfessorByToken(
-		getTokenFromHeader(r),
+	professor, err := service.UpdateProfessorByPrincipal(
+		getPrincipal(r),
 		professorRequest.ToModel(),
 		professorRequest.PasswordConfirmation,
 	)
@@ -86,7 +126,7 @@ func postProfessorFoto(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	err = service.UpdateProfessorFotoByToken(getTokenFromHeader(r), file, handler)
+	err = service.UpdateProfessorFotoByPrincipal(getPrincipal(r), file, handler)
 	if err != nil {
 		writeError(w, err)
 		return
@@ -120,7 +160,7 @@ func postAluno(w http.ResponseWriter, r *http.Request) {
 }
 
 func getProfessorAlunos(w http.ResponseWriter, r *http.Request) {
-	alunos, err := service.GetAlunosByProfessorToken(getTokenFromHeader(r))
+	alunos, err := service.GetAlunosByPrincipal(getPrincipal(r), getStringQueryParam(w, r, "status"))
 	if err != nil {
 		writeError(w, err)
 		return
@@ -136,12 +176,20 @@ func postLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tokens, err := service.Login(loginRequest.Email, loginRequest.Password)
+	tokens, mfaToken, err := service.Login(loginRequest.Email, loginRequest.Password, getSessionClient(r, loginRequest.Device))
 	if err != nil {
 		writeError(w, err)
 		return
 	}
 
+	if mfaToken != "" {
+		writeJSON(w, http.StatusOK, &mfaChallengeResponse{
+			MfaRequired: true,
+			MfaToken:    mfaToken,
+		})
+		return
+	}
+
 	response := &loginResponse{
 		Token:        tokens[0],
 		RefreshToken: tokens[1],
@@ -157,7 +205,7 @@ func postRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tokens, err := service.Refresh(refreshRequest.RefreshToken)
+	tokens, err := service.Refresh(refreshRequest.RefreshToken, getSessionClient(r, ""))
 	if err != nil {
 		writeError(w, err)
 		return
@@ -172,7 +220,7 @@ func postRefresh(w http.ResponseWriter, r *http.Request) {
 }
 
 func getMe(w http.ResponseWriter, r *http.Request) {
-	professor, err := service.GetProfessorByToken(getTokenFromHeader(r))
+	professor, err := service.GetProfessorByPrincipal(getPrincipal(r))
 	if err != nil {
 		writeError(w, err)
 		return
@@ -188,7 +236,7 @@ func postLogout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := service.Logout(getTokenFromHeader(r), refreshToken.RefreshToken); err != nil {
+	if err := service.Logout(getPrincipal(r), refreshToken.RefreshToken); err != nil {
 		writeError(w, err)
 		return
 	}
@@ -2,13 +2,17 @@ package rest
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
 	"reflect"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/cleysonph/hyperprof/config"
 	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/service"
+	"github.com/cleysonph/hyperprof/internal/transport/middleware"
 	"github.com/gorilla/mux"
 )
 
@@ -30,6 +34,41 @@ func writeError(w http.ResponseWriter, err error) {
 	case *model.ProfessorNotFoundError:
 		e := createJsonError(w, http.StatusNotFound, t)
 		writeJSON(w, http.StatusNotFound, e)
+	case *model.AlunoNotFoundError:
+		e := createJsonError(w, http.StatusNotFound, t)
+		writeJSON(w, http.StatusNotFound, e)
+	case *model.DisponibilidadeNotFoundError:
+		e := createJsonError(w, http.StatusNotFound, t)
+		writeJSON(w, http.StatusNotFound, e)
+	case *model.SessionNotFoundError:
+		e := createJsonError(w, http.StatusNotFound, t)
+		writeJSON(w, http.StatusNotFound, e)
+	case *model.ApiKeyNotFoundError:
+		e := createJsonError(w, http.StatusNotFound, t)
+		writeJSON(w, http.StatusNotFound, e)
+	case *model.DisciplinaNotFoundError:
+		e := createJsonError(w, http.StatusNotFound, t)
+		writeJSON(w, http.StatusNotFound, e)
+	case *model.AvaliacaoNotFoundError:
+		e := createJsonError(w, http.StatusNotFound, t)
+		writeJSON(w, http.StatusNotFound, e)
+	case *model.ArquivoNotFoundError:
+		e := createJsonError(w, http.StatusNotFound, t)
+		writeJSON(w, http.StatusNotFound, e)
+	case *model.OidcProviderNotFoundError:
+		e := createJsonError(w, http.StatusNotFound, t)
+		writeJSON(w, http.StatusNotFound, e)
+	case *model.ConflictError:
+		e := createJsonError(w, http.StatusConflict, t)
+		writeJSON(w, http.StatusConflict, e)
+	case *model.TooManyRequestsError:
+		setRetryAfter(w, t.RetryAfter)
+		e := createJsonError(w, http.StatusTooManyRequests, t)
+		writeJSON(w, http.StatusTooManyRequests, e)
+	case *model.AccountLockedError:
+		setRetryAfter(w, t.RetryAfter)
+		e := createJsonError(w, http.StatusLocked, t)
+		writeJSON(w, http.StatusLocked, e)
 	case *model.ConversionError:
 		e := createJsonError(w, http.StatusBadRequest, t)
 		writeJSON(w, http.StatusBadRequest, e)
@@ -45,12 +84,25 @@ func writeError(w http.ResponseWriter, err error) {
 	case *model.JwtTokenError:
 		e := createJsonError(w, http.StatusUnauthorized, t)
 		writeJSON(w, http.StatusUnauthorized, e)
+	case *model.ForbiddenError:
+		e := createJsonError(w, http.StatusForbidden, t)
+		writeJSON(w, http.StatusForbidden, e)
 	default:
 		e := createJsonError(w, http.StatusInternalServerError, t)
 		writeJSON(w, http.StatusInternalServerError, e)
 	}
 }
 
+// setRetryAfter tells the client how many seconds to wait before retrying,
+// rounding up so it never retries too early.
+func setRetryAfter(w http.ResponseWriter, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	seconds := int64((d + time.Second - 1) / time.Second)
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+}
+
 func createJsonError(w http.ResponseWriter, status int, err error) errorResponse {
 	return errorResponse{
 		Message:   err.Error(),
@@ -72,6 +124,51 @@ func getStringQueryParam(w http.ResponseWriter, r *http.Request, key string) str
 	return strings.Trim(r.URL.Query().Get(key), " ")
 }
 
+// getInt32QueryParam returns 0 when the parameter is missing.
+func getInt32QueryParam(w http.ResponseWriter, r *http.Request, key string) (int32, error) {
+	param := getStringQueryParam(w, r, key)
+	if param == "" {
+		return 0, nil
+	}
+	i, err := strconv.ParseInt(param, 10, 32)
+	if err != nil {
+		return 0, &model.ConversionError{
+			Message: key + " must be an integer",
+		}
+	}
+	return int32(i), nil
+}
+
+// getInt64QueryParam returns 0 when the parameter is missing.
+func getInt64QueryParam(w http.ResponseWriter, r *http.Request, key string) (int64, error) {
+	param := getStringQueryParam(w, r, key)
+	if param == "" {
+		return 0, nil
+	}
+	i, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return 0, &model.ConversionError{
+			Message: key + " must be an integer",
+		}
+	}
+	return i, nil
+}
+
+// getFloat64QueryParam returns 0 when the parameter is missing.
+func getFloat64QueryParam(w http.ResponseWriter, r *http.Request, key string) (float64, error) {
+	param := getStringQueryParam(w, r, key)
+	if param == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return 0, &model.ConversionError{
+			Message: key + " must be a number",
+		}
+	}
+	return f, nil
+}
+
 func getInt64UrlParam(w http.ResponseWriter, r *http.Request, key string) (int64, error) {
 	params := mux.Vars(r)
 	param, ok := params[key]
@@ -101,3 +198,56 @@ func getTokenFromHeader(r *http.Request) string {
 	token := r.Header.Get("Authorization")
 	return strings.TrimPrefix(token, "Bearer ")
 }
+
+// apiKeyHeader carries the keys of partner integrations, which are accepted
+// instead of a bearer token.
+const apiKeyHeader = "X-API-Key"
+
+func hasApiKey(r *http.Request) bool {
+	return r.Header.Get(apiKeyHeader) != ""
+}
+
+func authenticateRequest(r *http.Request) (*model.Principal, error) {
+	if hasApiKey(r) {
+		return service.AuthenticateApiKey(r.Header.Get(apiKeyHeader), getClientIP(r))
+	}
+	return service.Authenticate(getTokenFromHeader(r))
+}
+
+// getSessionClient describes the client that sent the request. The device
+// name is chosen by the client; the other fields are truncated to fit the
+// sessions table.
+func getSessionClient(r *http.Request, device string) *model.SessionClient {
+	return &model.SessionClient{
+		Device:    truncate(device, 100),
+		IP:        getClientIP(r),
+		UserAgent: truncate(r.UserAgent(), 512),
+	}
+}
+
+// getClientIP returns the address of the client. X-Forwarded-For is only
+// trusted when the API runs behind a proxy that sets it.
+func getClientIP(r *http.Request) string {
+	if config.TrustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return strings.ToValidUTF8(s[:max], "")
+}
+
+func getPrincipal(r *http.Request) *model.Principal {
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	return principal
+}
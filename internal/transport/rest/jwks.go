@@ -0,0 +1,13 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/cleysonph/hyperprof/internal/service"
+)
+
+func getJWKS(w http.ResponseWriter, r *http.Request) {
+	// Keys only change on restart, clients may cache them for a while
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(w, http.StatusOK, service.GetJWKS())
+}
@@ -0,0 +1,72 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/cleysonph/hyperprof/internal/service"
+)
+
+func postTotp(w http.ResponseWriter, r *http.Request) {
+	secret, uri, err := service.EnrollTotpByPrincipal(getPrincipal(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &totpEnrollmentResponse{
+		Secret:     secret,
+		OtpauthURI: uri,
+	})
+}
+
+func postTotpConfirmar(w http.ResponseWriter, r *http.Request) {
+	totpCodeRequest := &totpCodeRequest{}
+	if err := readJSON(r, &totpCodeRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	codes, err := service.ConfirmTotpByPrincipal(getPrincipal(r), totpCodeRequest.Code)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func deleteTotp(w http.ResponseWriter, r *http.Request) {
+	totpCodeRequest := &totpCodeRequest{}
+	if err := readJSON(r, &totpCodeRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := service.DisableTotpByPrincipal(getPrincipal(r), totpCodeRequest.Code); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func postLoginMfa(w http.ResponseWriter, r *http.Request) {
+	mfaLoginRequest := &mfaLoginRequest{}
+	if err := readJSON(r, &mfaLoginRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	tokens, err := service.LoginMfa(mfaLoginRequest.MfaToken, mfaLoginRequest.Code, getSessionClient(r, mfaLoginRequest.Device))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := &loginResponse{
+		Token:        tokens[0],
+		RefreshToken: tokens[1],
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
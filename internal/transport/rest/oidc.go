@@ -0,0 +1,53 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/cleysonph/hyperprof/internal/service"
+	"github.com/gorilla/mux"
+)
+
+func getOidcAuthorization(w http.ResponseWriter, r *http.Request) {
+	authorizationURL, err := service.StartOidcLogin(mux.Vars(r)["provider"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &oidcAuthorizationResponse{
+		AuthorizationURL: authorizationURL,
+	})
+}
+
+func postOidcCallback(w http.ResponseWriter, r *http.Request) {
+	oidcCallbackRequest := &oidcCallbackRequest{}
+	if err := readJSON(r, &oidcCallbackRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	tokens, mfaToken, err := service.LoginOidc(
+		oidcCallbackRequest.Code,
+		oidcCallbackRequest.State,
+		getSessionClient(r, oidcCallbackRequest.Device),
+	)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if mfaToken != "" {
+		writeJSON(w, http.StatusOK, &mfaChallengeResponse{
+			MfaRequired: true,
+			MfaToken:    mfaToken,
+		})
+		return
+	}
+
+	response := &loginResponse{
+		Token:        tokens[0],
+		RefreshToken: tokens[1],
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/cleysonph/hyperprof/internal/service"
+)
+
+func postForgotPassword(w http.ResponseWriter, r *http.Request) {
+	emailRequest := &emailRequest{}
+	if err := readJSON(r, &emailRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := service.ForgotPassword(emailRequest.Email); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, messageResponse{
+		Message: "Se o email estiver cadastrado, enviaremos as instruções para redefinir a senha",
+	})
+}
+
+func postResetPassword(w http.ResponseWriter, r *http.Request) {
+	resetPasswordRequest := &resetPasswordRequest{}
+	if err := readJSON(r, &resetPasswordRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	err := service.ResetPassword(
+		resetPasswordRequest.Token,
+		resetPasswordRequest.Password,
+		resetPasswordRequest.PasswordConfirmation,
+	)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, messageResponse{Message: "Senha redefinida com sucesso"})
+}
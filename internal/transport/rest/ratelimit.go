@@ -0,0 +1,28 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cleysonph/hyperprof/internal/ratelimit"
+	"github.com/cleysonph/hyperprof/internal/transport/middleware"
+	"github.com/rs/zerolog/log"
+)
+
+// Rate limits used when the RATE_LIMIT_* variables are not configured.
+var (
+	defaultRateLimit = ratelimit.Policy{Limit: 300, Period: time.Minute}
+	userRateLimit    = ratelimit.Policy{Limit: 120, Period: time.Minute}
+	authRateLimit    = ratelimit.Policy{Limit: 10, Period: time.Minute}
+	bookingRateLimit = ratelimit.Policy{Limit: 10, Period: time.Hour}
+)
+
+// rateLimit limits the requests to the policy written in value, falling back
+// to fallback when it is missing or invalid.
+func rateLimit(name, value string, fallback ratelimit.Policy) func(http.Handler) http.Handler {
+	policy, err := ratelimit.ParsePolicy(name, value, fallback)
+	if err != nil {
+		log.Warn().Err(err).Msg("using the default rate limit")
+	}
+	return middleware.RateLimit(policy, getClientIP, writeError)
+}
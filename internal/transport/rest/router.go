@@ -4,29 +4,102 @@ import (
 	"net/http"
 
 	"github.com/cleysonph/hyperprof/config"
+	"github.com/cleysonph/hyperprof/internal/model"
+	"github.com/cleysonph/hyperprof/internal/transport/middleware"
 	"github.com/gorilla/mux"
 )
 
+var allRoles = []string{model.RoleProfessor, model.RoleStudent, model.RoleAdmin}
+
 func NewRouter() http.Handler {
 	router := mux.NewRouter()
 
-	router.HandleFunc("/api/professores", getProfessores).Methods(http.MethodGet)
-	router.HandleFunc("/api/professores", postProfessor).Methods(http.MethodPost)
-	router.HandleFunc("/api/professores", putProfessor).Methods(http.MethodPut)
-	router.HandleFunc("/api/professores", deleteProfessor).Methods(http.MethodDelete)
-	router.HandleFunc("/api/professores/foto", postProfessorFoto).Methods(http.MethodPost)
-	router.HandleFunc("/api/professores/alunos", getProfessorAlunos).Methods(http.MethodGet)
-	router.HandleFunc("/api/professores/{professorID}", getProfessorByID).Methods(http.MethodGet)
-	router.HandleFunc("/api/professores/{professorID}/alunos", postAluno).Methods(http.MethodPost)
-	router.HandleFunc("/api/auth/login", postLogin).Methods(http.MethodPost)
-	router.HandleFunc("/api/me", getMe).Methods(http.MethodGet)
-	router.HandleFunc("/api/auth/refresh", postRefresh).Methods(http.MethodPost)
-	router.HandleFunc("/api/auth/logout", postLogout).Methods(http.MethodPost)
-
-	// Serve static files in development mode
-	if config.IsDev() {
-		router.PathPrefix("/uploads").Handler(http.StripPrefix("/uploads", http.FileServer(http.Dir("uploads"))))
+	authenticate := middleware.Authenticate(authenticateRequest, writeError)
+	limitUser := rateLimit("user", config.RateLimitUser, userRateLimit)
+	limitAuth := rateLimit("auth", config.RateLimitAuth, authRateLimit)
+	limitBooking := rateLimit("booking", config.RateLimitBooking, bookingRateLimit)
+
+	// Every request counts against the bucket of its IP address
+	router.Use(rateLimit("default", config.RateLimitDefault, defaultRateLimit))
+
+	// authorize only lets authenticated callers holding one of roles reach
+	// handler, counting their requests against a bucket of their own
+	authorize := func(handler http.HandlerFunc, roles ...string) http.Handler {
+		return authenticate(limitUser(middleware.RequireRoles(writeError, roles...)(handler)))
 	}
 
+	// partner lets anyone reach a public handler, but when an api key is sent
+	// it must be valid and hold scope
+	authenticateApiKey := middleware.AuthenticateIf(hasApiKey, authenticateRequest, writeError)
+	partner := func(handler http.Handler, scope string) http.Handler {
+		return authenticateApiKey(middleware.RequireScope(writeError, scope)(handler))
+	}
+
+	router.Handle("/api/professores", partner(limitUser(http.HandlerFunc(getProfessores)), model.ApiKeyScopeProfessoresRead)).Methods(http.MethodGet)
+	router.HandleFunc("/api/professores", postProfessor).Methods(http.MethodPost)
+	router.Handle("/api/professores", authorize(putProfessor, model.RoleProfessor)).Methods(http.MethodPut)
+	router.Handle("/api/professores", authorize(deleteProfessor, model.RoleProfessor)).Methods(http.MethodDelete)
+	router.Handle("/api/professores/foto", authorize(postProfessorFoto, model.RoleProfessor)).Methods(http.MethodPost)
+	router.Handle("/api/professores/alunos", authorize(getProfessorAlunos, model.RoleProfessor)).Methods(http.MethodGet)
+	router.Handle("/api/professores/alunos/{alunoID}/cancelar", authorize(postProfessorAlunoCancelar, model.RoleProfessor)).Methods(http.MethodPost)
+	router.Handle("/api/professores/alunos/{alunoID}/reagendar", authorize(postProfessorAlunoReagendar, model.RoleProfessor)).Methods(http.MethodPost)
+	router.Handle("/api/professores/alunos/{alunoID}/concluir", authorize(postProfessorAlunoConcluir, model.RoleProfessor)).Methods(http.MethodPost)
+	router.Handle("/api/professores/alunos/{alunoID}/historico", authorize(getProfessorAlunoHistorico, model.RoleProfessor)).Methods(http.MethodGet)
+	router.Handle("/api/professores/mfa/totp", authorize(postTotp, model.RoleProfessor)).Methods(http.MethodPost)
+	router.Handle("/api/professores/mfa/totp", authorize(deleteTotp, model.RoleProfessor)).Methods(http.MethodDelete)
+	router.Handle("/api/professores/mfa/totp/confirmar", authorize(postTotpConfirmar, model.RoleProfessor)).Methods(http.MethodPost)
+	router.Handle("/api/professores/sessoes", authorize(getSessoes, model.RoleProfessor)).Methods(http.MethodGet)
+	router.Handle("/api/professores/sessoes", authorize(deleteOutrasSessoes, model.RoleProfessor)).Methods(http.MethodDelete)
+	router.Handle("/api/professores/sessoes/{sessaoID}", authorize(deleteSessao, model.RoleProfessor)).Methods(http.MethodDelete)
+	router.Handle("/api/professores/disponibilidade", authorize(getDisponibilidade, model.RoleProfessor)).Methods(http.MethodGet)
+	router.Handle("/api/professores/disponibilidade", authorize(postDisponibilidade, model.RoleProfessor)).Methods(http.MethodPost)
+	router.Handle("/api/professores/disponibilidade/excecoes", authorize(postDisponibilidadeExcecao, model.RoleProfessor)).Methods(http.MethodPost)
+	router.Handle("/api/professores/disponibilidade/excecoes/{excecaoID}", authorize(deleteDisponibilidadeExcecao, model.RoleProfessor)).Methods(http.MethodDelete)
+	router.Handle("/api/professores/disponibilidade/{disponibilidadeID}", authorize(deleteDisponibilidade, model.RoleProfessor)).Methods(http.MethodDelete)
+	router.Handle("/api/professores/avaliacoes/{avaliacaoID}/resposta", authorize(postAvaliacaoResposta, model.RoleProfessor)).Methods(http.MethodPost)
+	router.Handle("/api/professores/disciplinas", authorize(putProfessorDisciplinas, model.RoleProfessor)).Methods(http.MethodPut)
+	router.Handle("/api/professores/disciplinas/{disciplinaID}", authorize(deleteProfessorDisciplina, model.RoleProfessor)).Methods(http.MethodDelete)
+	router.Handle("/api/professores/{professorID}", partner(limitUser(http.HandlerFunc(getProfessorByID)), model.ApiKeyScopeProfessoresRead)).Methods(http.MethodGet)
+	router.Handle("/api/professores/{professorID}/alunos", partner(limitBooking(http.HandlerFunc(postAluno)), model.ApiKeyScopeAulasWrite)).Methods(http.MethodPost)
+	router.Handle("/api/professores/{professorID}/avaliacoes", partner(limitUser(http.HandlerFunc(getProfessorAvaliacoes)), model.ApiKeyScopeProfessoresRead)).Methods(http.MethodGet)
+	router.Handle("/api/professores/{professorID}/disponibilidade", partner(limitUser(http.HandlerFunc(getProfessorDisponibilidade)), model.ApiKeyScopeProfessoresRead)).Methods(http.MethodGet)
+	router.Handle("/api/disciplinas", partner(limitUser(http.HandlerFunc(getDisciplinas)), model.ApiKeyScopeProfessoresRead)).Methods(http.MethodGet)
+	router.HandleFunc("/api/alunos", postEstudante).Methods(http.MethodPost)
+	router.Handle("/api/alunos/me", authorize(getEstudanteMe, model.RoleStudent)).Methods(http.MethodGet)
+	router.Handle("/api/alunos/me/aulas", authorize(getEstudanteAulas, model.RoleStudent)).Methods(http.MethodGet)
+	router.Handle("/api/alunos/me/aulas/{alunoID}/avaliacao", authorize(postEstudanteAulaAvaliacao, model.RoleStudent)).Methods(http.MethodPost)
+	router.HandleFunc("/api/alunos/{alunoID}", getAluno).Methods(http.MethodGet)
+	router.HandleFunc("/api/alunos/{alunoID}/cancelar", postAlunoCancelar).Methods(http.MethodPost)
+	router.HandleFunc("/api/alunos/{alunoID}/reagendar", postAlunoReagendar).Methods(http.MethodPost)
+	router.HandleFunc("/api/alunos/{alunoID}/avaliacao", postAlunoAvaliacao).Methods(http.MethodPost)
+	router.HandleFunc("/api/alunos/{alunoID}/historico", getAlunoHistorico).Methods(http.MethodGet)
+	router.Handle("/api/admin/professores", authorize(getAdminProfessores, model.RoleAdmin)).Methods(http.MethodGet)
+	router.Handle("/api/admin/professores/{professorID}", authorize(deleteAdminProfessor, model.RoleAdmin)).Methods(http.MethodDelete)
+	router.Handle("/api/admin/professores/{professorID}/suspensao", authorize(postAdminProfessorSuspensao, model.RoleAdmin)).Methods(http.MethodPost)
+	router.Handle("/api/admin/professores/{professorID}/suspensao", authorize(deleteAdminProfessorSuspensao, model.RoleAdmin)).Methods(http.MethodDelete)
+	router.Handle("/api/admin/disciplinas", authorize(postAdminDisciplina, model.RoleAdmin)).Methods(http.MethodPost)
+	router.Handle("/api/admin/disciplinas/{disciplinaID}", authorize(deleteAdminDisciplina, model.RoleAdmin)).Methods(http.MethodDelete)
+	router.Handle("/api/admin/chaves-api", authorize(getAdminApiKeys, model.RoleAdmin)).Methods(http.MethodGet)
+	router.Handle("/api/admin/chaves-api", authorize(postAdminApiKey, model.RoleAdmin)).Methods(http.MethodPost)
+	router.Handle("/api/admin/chaves-api/{apiKeyID}", authorize(deleteAdminApiKey, model.RoleAdmin)).Methods(http.MethodDelete)
+	router.Handle("/api/admin/bloqueios", authorize(getAdminBloqueios, model.RoleAdmin)).Methods(http.MethodGet)
+	router.Handle("/api/auth/login", limitAuth(http.HandlerFunc(postLogin))).Methods(http.MethodPost)
+	router.Handle("/api/auth/mfa", limitAuth(http.HandlerFunc(postLoginMfa))).Methods(http.MethodPost)
+	router.Handle("/api/auth/oidc/callback", limitAuth(http.HandlerFunc(postOidcCallback))).Methods(http.MethodPost)
+	router.Handle("/api/auth/oidc/{provider}", limitAuth(http.HandlerFunc(getOidcAuthorization))).Methods(http.MethodGet)
+	router.Handle("/api/auth/alunos/login", limitAuth(http.HandlerFunc(postEstudanteLogin))).Methods(http.MethodPost)
+	router.Handle("/api/auth/alunos/email/verify", limitAuth(http.HandlerFunc(postVerifyEstudanteEmail))).Methods(http.MethodPost)
+	router.Handle("/api/auth/alunos/email/resend", limitAuth(http.HandlerFunc(postResendEstudanteEmailVerification))).Methods(http.MethodPost)
+	router.Handle("/api/auth/admin/login", limitAuth(http.HandlerFunc(postAdminLogin))).Methods(http.MethodPost)
+	router.Handle("/api/me", authorize(getMe, model.RoleProfessor)).Methods(http.MethodGet)
+	router.Handle("/api/auth/refresh", limitAuth(http.HandlerFunc(postRefresh))).Methods(http.MethodPost)
+	router.Handle("/api/auth/password/forgot", limitAuth(http.HandlerFunc(postForgotPassword))).Methods(http.MethodPost)
+	router.Handle("/api/auth/password/reset", limitAuth(http.HandlerFunc(postResetPassword))).Methods(http.MethodPost)
+	router.Handle("/api/auth/email/verify", limitAuth(http.HandlerFunc(postVerifyEmail))).Methods(http.MethodPost)
+	router.Handle("/api/auth/email/resend", limitAuth(http.HandlerFunc(postResendEmailVerification))).Methods(http.MethodPost)
+	router.Handle("/api/auth/logout", authorize(postLogout, allRoles...)).Methods(http.MethodPost)
+	router.HandleFunc("/.well-known/jwks.json", getJWKS).Methods(http.MethodGet)
+	router.HandleFunc("/api/arquivos/{key:.+}", getArquivo).Methods(http.MethodGet)
+
 	return router
 }
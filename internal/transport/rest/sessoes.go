@@ -0,0 +1,37 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/cleysonph/hyperprof/internal/service"
+	"github.com/gorilla/mux"
+)
+
+func getSessoes(w http.ResponseWriter, r *http.Request) {
+	sessions, err := service.GetSessionsByPrincipal(getPrincipal(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+func deleteSessao(w http.ResponseWriter, r *http.Request) {
+	err := service.RevokeSessionByPrincipal(getPrincipal(r), mux.Vars(r)["sessaoID"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func deleteOutrasSessoes(w http.ResponseWriter, r *http.Request) {
+	if err := service.RevokeOtherSessionsByPrincipal(getPrincipal(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
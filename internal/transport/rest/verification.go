@@ -0,0 +1,71 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/cleysonph/hyperprof/internal/service"
+)
+
+func postVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	emailVerificationRequest := &emailVerificationRequest{}
+	if err := readJSON(r, &emailVerificationRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := service.VerifyEmail(emailVerificationRequest.Token); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, messageResponse{Message: "Email verificado com sucesso"})
+}
+
+func postResendEmailVerification(w http.ResponseWriter, r *http.Request) {
+	emailRequest := &emailRequest{}
+	if err := readJSON(r, &emailRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := service.ResendEmailVerification(emailRequest.Email); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, messageResponse{
+		Message: "Se o email estiver cadastrado e não verificado, enviaremos um novo link de verificação",
+	})
+}
+
+func postVerifyEstudanteEmail(w http.ResponseWriter, r *http.Request) {
+	emailVerificationRequest := &emailVerificationRequest{}
+	if err := readJSON(r, &emailVerificationRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := service.VerifyEstudanteEmail(emailVerificationRequest.Token); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, messageResponse{Message: "Email verificado com sucesso"})
+}
+
+func postResendEstudanteEmailVerification(w http.ResponseWriter, r *http.Request) {
+	emailRequest := &emailRequest{}
+	if err := readJSON(r, &emailRequest); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := service.ResendEstudanteEmailVerification(emailRequest.Email); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, messageResponse{
+		Message: "Se o email estiver cadastrado e não verificado, enviaremos um novo link de verificação",
+	})
+}
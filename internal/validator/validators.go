@@ -1,10 +1,13 @@
 package validator
 
 import (
+	"fmt"
 	"mime/multipart"
+	"net/mail"
 	"strings"
 	"time"
 
+	"github.com/cleysonph/hyperprof/config"
 	"github.com/cleysonph/hyperprof/internal/database"
 	"github.com/cleysonph/hyperprof/internal/model"
 )
@@ -18,8 +21,11 @@ func ValidateAluno(aluno *model.Aluno) error {
 	validationErr.AddErrorIf(aluno.Email == "", "email", "is required")
 	validationErr.AddErrorIf(len(aluno.Email) < 3, "email", "must be at least 3 characters")
 	validationErr.AddErrorIf(len(aluno.Email) > 255, "email", "must be at most 255 characters")
+	validationErr.AddErrorIf(aluno.Email != "" && !isEmail(aluno.Email), "email", "must be a valid email address")
 	validationErr.AddErrorIf(aluno.DataAula.IsZero(), "data_aula", "is required")
 	validationErr.AddErrorIf(aluno.DataAula.Before(time.Now()), "data_aula", "must be in the future")
+	validationErr.AddErrorIf(aluno.Duracao < 15, "duracao", "must be at least 15 minutes")
+	validationErr.AddErrorIf(aluno.Duracao > 480, "duracao", "must be at most 480 minutes")
 
 	if validationErr.HasErrors() {
 		return validationErr
@@ -27,7 +33,39 @@ func ValidateAluno(aluno *model.Aluno) error {
 	return nil
 }
 
-func ValidateProfessor(professor *model.Professor, passwordConfirmation string) error {
+func ValidateAlunoStatus(status string) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(
+		status != "" &&
+			status != model.AulaStatusScheduled &&
+			status != model.AulaStatusCancelled &&
+			status != model.AulaStatusRescheduled &&
+			status != model.AulaStatusCompleted,
+		"status",
+		"must be one of scheduled, cancelled, rescheduled or completed",
+	)
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+func ValidateMotivo(motivo string) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(len(motivo) > 255, "motivo", "must be at most 255 characters")
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+// ValidateProfessor checks the data of a professor being created or updated.
+// emailTaken reports whether another professor already uses the email.
+func ValidateProfessor(professor *model.Professor, passwordConfirmation string, emailTaken bool) error {
 	validationErr := &model.ValidationError{}
 
 	validationErr.AddErrorIf(professor.Nome == "", "nome", "is required")
@@ -36,6 +74,7 @@ func ValidateProfessor(professor *model.Professor, passwordConfirmation string)
 	validationErr.AddErrorIf(professor.Email == "", "email", "is required")
 	validationErr.AddErrorIf(len(professor.Email) < 3, "email", "must be at least 3 characters")
 	validationErr.AddErrorIf(len(professor.Email) > 255, "email", "must be at most 255 characters")
+	validationErr.AddErrorIf(professor.Email != "" && !isEmail(professor.Email), "email", "must be a valid email address")
 	validationErr.AddErrorIf(professor.Idade < 18, "idade", "must be at least 18 years old")
 	validationErr.AddErrorIf(professor.Idade > 100, "idade", "must be at most 100 years old")
 	validationErr.AddErrorIf(professor.Descricao == "", "descricao", "is required")
@@ -43,12 +82,56 @@ func ValidateProfessor(professor *model.Professor, passwordConfirmation string)
 	validationErr.AddErrorIf(len(professor.Descricao) > 500, "descricao", "must be at most 500 characters")
 	validationErr.AddErrorIf(professor.ValorHora < 10, "valor_hora", "must be at least 10")
 	validationErr.AddErrorIf(professor.ValorHora > 500, "valor_hora", "must be at most 500")
+	validationErr.AddErrorIf(professor.DuracaoAula < 15, "duracao_aula", "must be at least 15 minutes")
+	validationErr.AddErrorIf(professor.DuracaoAula > 480, "duracao_aula", "must be at most 480 minutes")
 	validationErr.AddErrorIf(professor.Password == "", "password", "is required")
 	validationErr.AddErrorIf(len(professor.Password) < 6, "password", "must be at least 6 characters")
 	validationErr.AddErrorIf(passwordConfirmation == "", "password_confirmation", "is required")
 	validationErr.AddErrorIf(professor.Password != passwordConfirmation, "password_confirmation", "must match password")
-	validationErr.AddErrorIf(professor.ID <= 0 && database.ExistsProfessorByEmail(professor.Email), "email", "already exists")
-	validationErr.AddErrorIf(professor.ID > 0 && database.ExistsProfessorByEmailAndNotID(professor.Email, professor.ID), "email", "already exists")
+	validationErr.AddErrorIf(emailTaken, "email", "already exists")
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+func ValidateEstudante(estudante *model.Estudante, passwordConfirmation string) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(estudante.Nome == "", "nome", "is required")
+	validationErr.AddErrorIf(len(estudante.Nome) < 3, "nome", "must be at least 3 characters")
+	validationErr.AddErrorIf(len(estudante.Nome) > 100, "nome", "must be at most 100 characters")
+	validationErr.AddErrorIf(estudante.Email == "", "email", "is required")
+	validationErr.AddErrorIf(len(estudante.Email) < 3, "email", "must be at least 3 characters")
+	validationErr.AddErrorIf(len(estudante.Email) > 255, "email", "must be at most 255 characters")
+	validationErr.AddErrorIf(estudante.Email != "" && !isEmail(estudante.Email), "email", "must be a valid email address")
+	validationErr.AddErrorIf(estudante.Password == "", "password", "is required")
+	validationErr.AddErrorIf(len(estudante.Password) < 6, "password", "must be at least 6 characters")
+	validationErr.AddErrorIf(passwordConfirmation == "", "password_confirmation", "is required")
+	validationErr.AddErrorIf(estudante.Password != passwordConfirmation, "password_confirmation", "must match password")
+	validationErr.AddErrorIf(database.ExistsEstudanteByEmail(estudante.Email), "email", "already exists")
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+func ValidateAdministrador(administrador *model.Administrador, passwordConfirmation string) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(administrador.Nome == "", "nome", "is required")
+	validationErr.AddErrorIf(len(administrador.Nome) < 3, "nome", "must be at least 3 characters")
+	validationErr.AddErrorIf(len(administrador.Nome) > 100, "nome", "must be at most 100 characters")
+	validationErr.AddErrorIf(administrador.Email == "", "email", "is required")
+	validationErr.AddErrorIf(len(administrador.Email) < 3, "email", "must be at least 3 characters")
+	validationErr.AddErrorIf(len(administrador.Email) > 255, "email", "must be at most 255 characters")
+	validationErr.AddErrorIf(administrador.Password == "", "password", "is required")
+	validationErr.AddErrorIf(len(administrador.Password) < 6, "password", "must be at least 6 characters")
+	validationErr.AddErrorIf(passwordConfirmation == "", "password_confirmation", "is required")
+	validationErr.AddErrorIf(administrador.Password != passwordConfirmation, "password_confirmation", "must match password")
+	validationErr.AddErrorIf(database.ExistsAdministradorByEmail(administrador.Email), "email", "already exists")
 
 	if validationErr.HasErrors() {
 		return validationErr
@@ -82,15 +165,384 @@ func ValidateRefresh(refreshToken string) error {
 	return nil
 }
 
+func ValidateEmail(email string) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(email == "", "email", "is required")
+	validationErr.AddErrorIf(len(email) > 255, "email", "must be at most 255 characters")
+	validationErr.AddErrorIf(email != "" && !isEmail(email), "email", "must be a valid email address")
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+func ValidatePasswordReset(token, password, passwordConfirmation string) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(token == "", "token", "is required")
+	validationErr.AddErrorIf(password == "", "password", "is required")
+	validationErr.AddErrorIf(len(password) < 6, "password", "must be at least 6 characters")
+	validationErr.AddErrorIf(passwordConfirmation == "", "password_confirmation", "is required")
+	validationErr.AddErrorIf(password != passwordConfirmation, "password_confirmation", "must match password")
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+func ValidateEmailVerification(token string) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(token == "", "token", "is required")
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+func ValidateTotpCode(code string) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(code == "", "code", "is required")
+	validationErr.AddErrorIf(len(code) > 20, "code", "must be at most 20 characters")
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+func ValidateMfaLogin(mfaToken, code string) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(mfaToken == "", "mfa_token", "is required")
+	validationErr.AddErrorIf(code == "", "code", "is required")
+	validationErr.AddErrorIf(len(code) > 20, "code", "must be at most 20 characters")
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
 func ValidateProfessorFoto(file *multipart.FileHeader) error {
 	validationErr := &model.ValidationError{}
 
 	validationErr.AddErrorIf(file == nil, "foto", "is required")
 	validationErr.AddErrorIf(file.Size > 2*1024*1024, "foto", "must be at most 2MB")
-	validationErr.AddErrorIf(!strings.HasPrefix(file.Header.Get("Content-Type"), "image/"), "foto", "must be an image")
 
 	if validationErr.HasErrors() {
 		return validationErr
 	}
 	return nil
 }
+
+// ValidateProfessorFotoImage checks the image found in an uploaded photo, as
+// reported by its decoder rather than by the client.
+func ValidateProfessorFotoImage(format string, width, height int) error {
+	validationErr := &model.ValidationError{}
+	smallest := model.FotoTamanhos[0]
+
+	validationErr.AddErrorIf(format != "jpeg" && format != "png" && format != "gif", "foto", "must be a JPEG, PNG or GIF image")
+	validationErr.AddErrorIf(format != "" && (width < smallest || height < smallest), "foto", fmt.Sprintf("must be at least %dx%d pixels", smallest, smallest))
+	validationErr.AddErrorIf(int64(width)*int64(height) > model.FotoMaxPixels, "foto", fmt.Sprintf("must be at most %d pixels", model.FotoMaxPixels))
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+// Agenda looks up the availability of the professores.
+type Agenda interface {
+	FindDisponibilidadesByDiaSemana(professorID int64, diaSemana int32) ([]*model.Disponibilidade, error)
+	FindDisponibilidadeExcecoesByPeriodo(professorID int64, inicio, fim time.Time) ([]*model.DisponibilidadeExcecao, error)
+}
+
+func ValidateAlunoDisponibilidade(aluno *model.Aluno, agenda Agenda) error {
+	validationErr := &model.ValidationError{}
+
+	disponivel, err := isProfessorDisponivel(agenda, aluno.ProfessorID, aluno.DataAula, aluno.FimAula())
+	if err != nil {
+		return &model.ApplicationError{
+			Message: err.Error(),
+		}
+	}
+	validationErr.AddErrorIf(!disponivel, "data_aula", "is outside the professor's availability")
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+// isProfessorDisponivel reports whether the whole period between inicio and
+// fim falls inside an open window of the professor. One-off exceptions take
+// precedence over the weekly windows: a blocking exception that overlaps the
+// period always closes it and an opening exception that covers it always
+// opens it.
+func isProfessorDisponivel(agenda Agenda, professorID int64, inicio, fim time.Time) (bool, error) {
+	excecoes, err := agenda.FindDisponibilidadeExcecoesByPeriodo(professorID, inicio, fim)
+	if err != nil {
+		return false, err
+	}
+
+	aberta := false
+	for _, excecao := range excecoes {
+		if !excecao.Disponivel {
+			return false, nil
+		}
+		if !excecao.Inicio.After(inicio) && !excecao.Fim.Before(fim) {
+			aberta = true
+		}
+	}
+	if aberta {
+		return true, nil
+	}
+
+	localInicio := inicio.In(config.Location)
+	localFim := fim.In(config.Location)
+	if localInicio.YearDay() != localFim.YearDay() || localInicio.Year() != localFim.Year() {
+		return false, nil
+	}
+
+	disponibilidades, err := agenda.FindDisponibilidadesByDiaSemana(professorID, int32(localInicio.Weekday()))
+	if err != nil {
+		return false, err
+	}
+
+	horaInicio := localInicio.Format(horaLayout)
+	horaFim := localFim.Format(horaLayout)
+	for _, disponibilidade := range disponibilidades {
+		if disponibilidade.HoraInicio <= horaInicio && horaFim <= disponibilidade.HoraFim {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+const horaLayout = "15:04"
+
+func isValidHora(hora string) bool {
+	_, err := time.Parse(horaLayout, hora)
+	return err == nil && len(hora) == len(horaLayout)
+}
+
+func ValidateDisponibilidade(disponibilidade *model.Disponibilidade) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(disponibilidade.DiaSemana < 0, "dia_semana", "must be at least 0")
+	validationErr.AddErrorIf(disponibilidade.DiaSemana > 6, "dia_semana", "must be at most 6")
+	validationErr.AddErrorIf(disponibilidade.HoraInicio == "", "hora_inicio", "is required")
+	validationErr.AddErrorIf(!isValidHora(disponibilidade.HoraInicio), "hora_inicio", "must be in the format HH:MM")
+	validationErr.AddErrorIf(disponibilidade.HoraFim == "", "hora_fim", "is required")
+	validationErr.AddErrorIf(!isValidHora(disponibilidade.HoraFim), "hora_fim", "must be in the format HH:MM")
+	validationErr.AddErrorIf(disponibilidade.HoraFim <= disponibilidade.HoraInicio, "hora_fim", "must be after hora_inicio")
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+
+	validationErr.AddErrorIf(
+		database.ExistsDisponibilidadeOverlap(
+			disponibilidade.ProfessorID,
+			disponibilidade.DiaSemana,
+			disponibilidade.HoraInicio,
+			disponibilidade.HoraFim,
+		),
+		"hora_inicio",
+		"overlaps an existing availability window",
+	)
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+func ValidateDisponibilidadeExcecao(excecao *model.DisponibilidadeExcecao) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(excecao.Inicio.IsZero(), "inicio", "is required")
+	validationErr.AddErrorIf(excecao.Fim.IsZero(), "fim", "is required")
+	validationErr.AddErrorIf(!excecao.Fim.After(excecao.Inicio), "fim", "must be after inicio")
+	validationErr.AddErrorIf(excecao.Fim.Before(time.Now()), "fim", "must be in the future")
+	validationErr.AddErrorIf(len(excecao.Motivo) > 255, "motivo", "must be at most 255 characters")
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+func ValidateApiKey(apiKey *model.ApiKey) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(apiKey.Nome == "", "nome", "is required")
+	validationErr.AddErrorIf(len(apiKey.Nome) < 3, "nome", "must be at least 3 characters")
+	validationErr.AddErrorIf(len(apiKey.Nome) > 100, "nome", "must be at most 100 characters")
+	validationErr.AddErrorIf(len(apiKey.Scopes) == 0, "scopes", "is required")
+	unknownScope := false
+	for _, scope := range apiKey.Scopes {
+		unknownScope = unknownScope || !isApiKeyScope(scope)
+	}
+	validationErr.AddErrorIf(unknownScope, "scopes", "must only contain "+strings.Join(model.ApiKeyScopes, ", "))
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+func isApiKeyScope(scope string) bool {
+	for _, s := range model.ApiKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidateOidcLogin(code, state string) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(code == "", "code", "is required")
+	validationErr.AddErrorIf(state == "", "state", "is required")
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+func ValidateAvaliacao(avaliacao *model.Avaliacao) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(
+		avaliacao.Nota < model.AvaliacaoNotaMin || avaliacao.Nota > model.AvaliacaoNotaMax,
+		"nota",
+		"must be between 1 and 5",
+	)
+	validationErr.AddErrorIf(len(avaliacao.Comentario) > 2000, "comentario", "must be at most 2000 characters")
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+func ValidateResposta(resposta string) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(resposta == "", "resposta", "is required")
+	validationErr.AddErrorIf(len(resposta) > 2000, "resposta", "must be at most 2000 characters")
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+func ValidatePagination(page, limit int32) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(page < 1, "page", "must be at least 1")
+	validationErr.AddErrorIf(limit < 1, "limit", "must be at least 1")
+	validationErr.AddErrorIf(limit > maxPageLimit, "limit", "must be at most 100")
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+// maxProfessorDisciplinas bounds how many subjects a professor can teach.
+const maxProfessorDisciplinas = 20
+
+func ValidateDisciplina(disciplina *model.Disciplina) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(disciplina.Nome == "", "nome", "is required")
+	validationErr.AddErrorIf(len(disciplina.Nome) < 2, "nome", "must be at least 2 characters")
+	validationErr.AddErrorIf(len(disciplina.Nome) > 100, "nome", "must be at most 100 characters")
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+func ValidateProfessorDisciplinas(disciplinaIDs []int64) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(len(disciplinaIDs) > maxProfessorDisciplinas, "disciplina_ids", "must have at most 20 items")
+	seen := map[int64]bool{}
+	duplicated := false
+	for _, id := range disciplinaIDs {
+		duplicated = duplicated || seen[id]
+		seen[id] = true
+	}
+	validationErr.AddErrorIf(duplicated, "disciplina_ids", "must not have duplicated items")
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+// maxPageLimit bounds how many items a single page can hold.
+const maxPageLimit = 100
+
+func ValidateProfessorFilter(filter *model.ProfessorFilter) error {
+	validationErr := &model.ValidationError{}
+
+	validationErr.AddErrorIf(filter.Page < 1, "page", "must be at least 1")
+	validationErr.AddErrorIf(filter.Limit < 1, "limit", "must be at least 1")
+	validationErr.AddErrorIf(filter.Limit > maxPageLimit, "limit", "must be at most 100")
+	validationErr.AddErrorIf(filter.ValorHoraMin < 0, "valor_hora_min", "must not be negative")
+	validationErr.AddErrorIf(filter.ValorHoraMax < 0, "valor_hora_max", "must not be negative")
+	validationErr.AddErrorIf(
+		filter.ValorHoraMin > 0 && filter.ValorHoraMax > 0 && filter.ValorHoraMin > filter.ValorHoraMax,
+		"valor_hora_max",
+		"must be greater than or equal to valor_hora_min",
+	)
+	validationErr.AddErrorIf(filter.Subject < 0, "subject", "must not be negative")
+	validationErr.AddErrorIf(filter.IdadeMin < 0, "idade_min", "must not be negative")
+	validationErr.AddErrorIf(filter.IdadeMax < 0, "idade_max", "must not be negative")
+	validationErr.AddErrorIf(
+		filter.IdadeMin > 0 && filter.IdadeMax > 0 && filter.IdadeMin > filter.IdadeMax,
+		"idade_max",
+		"must be greater than or equal to idade_min",
+	)
+	validationErr.AddErrorIf(
+		filter.Sort != model.ProfessorSortOldest &&
+			filter.Sort != model.ProfessorSortNewest &&
+			filter.Sort != model.ProfessorSortPriceAsc &&
+			filter.Sort != model.ProfessorSortPriceDesc &&
+			filter.Sort != model.ProfessorSortRelevance &&
+			filter.Sort != model.ProfessorSortRating,
+		"sort",
+		"must be one of oldest, newest, price_asc, price_desc, relevance or rating",
+	)
+	validationErr.AddErrorIf(
+		filter.Sort == model.ProfessorSortRelevance && filter.Q == "",
+		"sort",
+		"relevance requires q",
+	)
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+// isEmail reports whether email is a bare address, which also keeps line
+// breaks out of the headers of the messages sent to it.
+func isEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
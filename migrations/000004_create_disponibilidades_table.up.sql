@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS `disponibilidades`;
+CREATE TABLE IF NOT EXISTS `disponibilidades` (
+  `id` BIGINT PRIMARY KEY AUTO_INCREMENT,
+  `professor_id` BIGINT NOT NULL,
+  `dia_semana` TINYINT NOT NULL,
+  `hora_inicio` TIME NOT NULL,
+  `hora_fim` TIME NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+ALTER TABLE `disponibilidades` ADD FOREIGN KEY (`professor_id`) REFERENCES `professores`(`id`) ON DELETE CASCADE;
+CREATE INDEX `idx_disponibilidades_professor_dia` ON `disponibilidades` (`professor_id`, `dia_semana`);
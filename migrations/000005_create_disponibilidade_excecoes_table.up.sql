@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS `disponibilidade_excecoes`;
+CREATE TABLE IF NOT EXISTS `disponibilidade_excecoes` (
+  `id` BIGINT PRIMARY KEY AUTO_INCREMENT,
+  `professor_id` BIGINT NOT NULL,
+  `inicio` DATETIME NOT NULL,
+  `fim` DATETIME NOT NULL,
+  `disponivel` BOOLEAN NOT NULL DEFAULT FALSE,
+  `motivo` VARCHAR(255) NOT NULL DEFAULT '',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+ALTER TABLE `disponibilidade_excecoes` ADD FOREIGN KEY (`professor_id`) REFERENCES `professores`(`id`) ON DELETE CASCADE;
+CREATE INDEX `idx_disponibilidade_excecoes_professor_periodo` ON `disponibilidade_excecoes` (`professor_id`, `inicio`, `fim`);
@@ -0,0 +1,2 @@
+ALTER TABLE `alunos` DROP COLUMN `duracao`;
+ALTER TABLE `professores` DROP COLUMN `duracao_aula`;
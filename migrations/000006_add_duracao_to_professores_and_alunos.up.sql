@@ -0,0 +1,4 @@
+ALTER TABLE `professores` ADD COLUMN `duracao_aula` INT NOT NULL DEFAULT 60 AFTER `valor_hora`;
+ALTER TABLE `alunos` ADD COLUMN `duracao` INT NOT NULL DEFAULT 60 AFTER `data_aula`;
+
+CREATE INDEX `idx_alunos_professor_data_aula` ON `alunos` (`professor_id`, `data_aula`);
@@ -0,0 +1 @@
+ALTER TABLE `alunos` DROP COLUMN `status`;
@@ -0,0 +1,3 @@
+ALTER TABLE `alunos` ADD COLUMN `status` VARCHAR(20) NOT NULL DEFAULT 'scheduled' AFTER `duracao`;
+
+CREATE INDEX `idx_alunos_professor_status` ON `alunos` (`professor_id`, `status`);
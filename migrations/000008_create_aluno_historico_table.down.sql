@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `aluno_historico`;
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS `aluno_historico`;
+CREATE TABLE IF NOT EXISTS `aluno_historico` (
+  `id` BIGINT PRIMARY KEY AUTO_INCREMENT,
+  `aluno_id` BIGINT NOT NULL,
+  `status_anterior` VARCHAR(20) NOT NULL DEFAULT '',
+  `status_novo` VARCHAR(20) NOT NULL,
+  `data_aula_anterior` DATETIME NOT NULL,
+  `data_aula_nova` DATETIME NOT NULL,
+  `autor` VARCHAR(20) NOT NULL,
+  `motivo` VARCHAR(255) NOT NULL DEFAULT '',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE `aluno_historico` ADD FOREIGN KEY (`aluno_id`) REFERENCES `alunos`(`id`) ON DELETE CASCADE;
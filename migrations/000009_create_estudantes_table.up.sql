@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS `estudantes`;
+CREATE TABLE IF NOT EXISTS `estudantes` (
+  `id` BIGINT PRIMARY KEY AUTO_INCREMENT,
+  `nome` VARCHAR(100) NOT NULL,
+  `email` VARCHAR(255) NOT NULL UNIQUE,
+  `password` VARCHAR(255) NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
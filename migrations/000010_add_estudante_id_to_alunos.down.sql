@@ -0,0 +1,2 @@
+ALTER TABLE `alunos` DROP FOREIGN KEY `fk_alunos_estudante`;
+ALTER TABLE `alunos` DROP COLUMN `estudante_id`;
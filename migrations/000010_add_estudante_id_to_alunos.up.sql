@@ -0,0 +1,2 @@
+ALTER TABLE `alunos` ADD COLUMN `estudante_id` BIGINT NULL AFTER `professor_id`;
+ALTER TABLE `alunos` ADD CONSTRAINT `fk_alunos_estudante` FOREIGN KEY (`estudante_id`) REFERENCES `estudantes`(`id`) ON DELETE SET NULL;
@@ -0,0 +1 @@
+ALTER TABLE `professores` ADD COLUMN `suspended_at` TIMESTAMP NULL AFTER `password`;
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS `password_resets`;
+CREATE TABLE IF NOT EXISTS `password_resets` (
+  `id` BIGINT PRIMARY KEY AUTO_INCREMENT,
+  `professor_id` BIGINT NOT NULL,
+  `token_hash` CHAR(64) NOT NULL UNIQUE,
+  `expires_at` DATETIME NOT NULL,
+  `used_at` DATETIME NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE `password_resets` ADD FOREIGN KEY (`professor_id`) REFERENCES `professores`(`id`) ON DELETE CASCADE;
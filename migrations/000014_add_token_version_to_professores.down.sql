@@ -0,0 +1 @@
+ALTER TABLE `professores` DROP COLUMN `token_version`;
@@ -0,0 +1 @@
+ALTER TABLE `professores` ADD COLUMN `token_version` INT NOT NULL DEFAULT 0 AFTER `password`;
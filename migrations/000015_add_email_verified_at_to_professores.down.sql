@@ -0,0 +1 @@
+ALTER TABLE `professores` DROP COLUMN `email_verified_at`;
@@ -0,0 +1,3 @@
+ALTER TABLE `professores` ADD COLUMN `email_verified_at` TIMESTAMP NULL AFTER `suspended_at`;
+
+UPDATE `professores` SET `email_verified_at` = `created_at`;
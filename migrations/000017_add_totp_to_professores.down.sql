@@ -0,0 +1,3 @@
+ALTER TABLE `professores` DROP COLUMN `totp_last_step`;
+ALTER TABLE `professores` DROP COLUMN `totp_enabled_at`;
+ALTER TABLE `professores` DROP COLUMN `totp_secret`;
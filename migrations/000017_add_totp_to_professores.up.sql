@@ -0,0 +1,3 @@
+ALTER TABLE `professores` ADD COLUMN `totp_secret` VARCHAR(64) NULL AFTER `token_version`;
+ALTER TABLE `professores` ADD COLUMN `totp_enabled_at` TIMESTAMP NULL AFTER `totp_secret`;
+ALTER TABLE `professores` ADD COLUMN `totp_last_step` BIGINT NOT NULL DEFAULT 0 AFTER `totp_enabled_at`;
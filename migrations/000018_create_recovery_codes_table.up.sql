@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS `recovery_codes`;
+CREATE TABLE IF NOT EXISTS `recovery_codes` (
+  `id` BIGINT PRIMARY KEY AUTO_INCREMENT,
+  `professor_id` BIGINT NOT NULL,
+  `code_hash` CHAR(64) NOT NULL,
+  `used_at` DATETIME NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE `recovery_codes` ADD FOREIGN KEY (`professor_id`) REFERENCES `professores`(`id`) ON DELETE CASCADE;
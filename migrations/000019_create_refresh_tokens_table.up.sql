@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS `refresh_tokens`;
+CREATE TABLE IF NOT EXISTS `refresh_tokens` (
+  `jti` CHAR(36) PRIMARY KEY,
+  `family_id` CHAR(36) NOT NULL,
+  `parent_jti` CHAR(36) NULL,
+  `subject` VARCHAR(255) NOT NULL,
+  `role` VARCHAR(20) NOT NULL,
+  `expires_at` DATETIME NOT NULL,
+  `rotated_at` DATETIME NULL,
+  `revoked_at` DATETIME NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  INDEX `idx_refresh_tokens_family_id` (`family_id`)
+);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS `invalidated_tokens`;
+CREATE TABLE IF NOT EXISTS `invalidated_tokens` (
+  `jti` CHAR(36) PRIMARY KEY,
+  `expires_at` DATETIME NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS `sessions`;
+CREATE TABLE IF NOT EXISTS `sessions` (
+  `id` CHAR(36) PRIMARY KEY,
+  `subject` VARCHAR(255) NOT NULL,
+  `role` VARCHAR(20) NOT NULL,
+  `device` VARCHAR(100) NOT NULL DEFAULT '',
+  `ip` VARCHAR(45) NOT NULL DEFAULT '',
+  `user_agent` VARCHAR(512) NOT NULL DEFAULT '',
+  `expires_at` DATETIME NOT NULL,
+  `last_used_at` DATETIME NOT NULL,
+  `revoked_at` DATETIME NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  INDEX `idx_sessions_subject_role` (`subject`, `role`)
+);
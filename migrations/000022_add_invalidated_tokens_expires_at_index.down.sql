@@ -0,0 +1 @@
+ALTER TABLE `invalidated_tokens` DROP INDEX `idx_invalidated_tokens_expires_at`;
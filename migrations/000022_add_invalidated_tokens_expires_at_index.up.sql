@@ -0,0 +1 @@
+ALTER TABLE `invalidated_tokens` ADD INDEX `idx_invalidated_tokens_expires_at` (`expires_at`);
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS `login_attempts`;
+CREATE TABLE IF NOT EXISTS `login_attempts` (
+  `scope` VARCHAR(20) NOT NULL,
+  `role` VARCHAR(20) NOT NULL DEFAULT '',
+  `identifier` VARCHAR(255) NOT NULL,
+  `failures` INT NOT NULL DEFAULT 0,
+  `locked_until` DATETIME NULL,
+  `last_failure_at` DATETIME NOT NULL,
+  PRIMARY KEY (`scope`, `role`, `identifier`),
+  INDEX `idx_login_attempts_locked_until` (`locked_until`)
+);
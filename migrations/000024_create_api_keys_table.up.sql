@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS `api_keys`;
+CREATE TABLE IF NOT EXISTS `api_keys` (
+  `id` BIGINT PRIMARY KEY AUTO_INCREMENT,
+  `administrador_id` BIGINT NULL,
+  `nome` VARCHAR(100) NOT NULL,
+  `prefix` VARCHAR(16) NOT NULL,
+  `key_hash` CHAR(64) NOT NULL UNIQUE,
+  `scopes` VARCHAR(255) NOT NULL,
+  `last_used_at` TIMESTAMP NULL,
+  `last_used_ip` VARCHAR(45) NULL,
+  `revoked_at` TIMESTAMP NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+ALTER TABLE `api_keys` ADD FOREIGN KEY (`administrador_id`) REFERENCES `administradores`(`id`) ON DELETE SET NULL;
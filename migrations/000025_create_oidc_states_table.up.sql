@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS `oidc_states`;
+CREATE TABLE IF NOT EXISTS `oidc_states` (
+  `id` BIGINT PRIMARY KEY AUTO_INCREMENT,
+  `provider` VARCHAR(50) NOT NULL,
+  `state_hash` CHAR(64) NOT NULL UNIQUE,
+  `nonce` VARCHAR(64) NOT NULL,
+  `code_verifier` VARCHAR(128) NOT NULL,
+  `expires_at` DATETIME NOT NULL,
+  `used_at` DATETIME NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  INDEX `idx_oidc_states_expires_at` (`expires_at`)
+);
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS `professor_identities`;
+CREATE TABLE IF NOT EXISTS `professor_identities` (
+  `id` BIGINT PRIMARY KEY AUTO_INCREMENT,
+  `professor_id` BIGINT NOT NULL,
+  `provider` VARCHAR(50) NOT NULL,
+  `subject` VARCHAR(255) NOT NULL,
+  `email` VARCHAR(255) NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY `uk_professor_identities_provider_subject` (`provider`, `subject`)
+);
+
+ALTER TABLE `professor_identities` ADD FOREIGN KEY (`professor_id`) REFERENCES `professores`(`id`) ON DELETE CASCADE;
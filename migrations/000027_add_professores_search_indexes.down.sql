@@ -0,0 +1,2 @@
+ALTER TABLE `professores` DROP INDEX `idx_professores_created_at`;
+ALTER TABLE `professores` DROP INDEX `idx_professores_valor_hora`;
@@ -0,0 +1,2 @@
+ALTER TABLE `professores` ADD INDEX `idx_professores_valor_hora` (`valor_hora`);
+ALTER TABLE `professores` ADD INDEX `idx_professores_created_at` (`created_at`);
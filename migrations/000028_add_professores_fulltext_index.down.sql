@@ -0,0 +1 @@
+ALTER TABLE `professores` DROP INDEX `ft_professores_nome_descricao`;
@@ -0,0 +1 @@
+ALTER TABLE `professores` ADD FULLTEXT INDEX `ft_professores_nome_descricao` (`nome`, `descricao`);
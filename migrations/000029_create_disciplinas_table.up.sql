@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `disciplinas` (
+  `id` BIGINT PRIMARY KEY AUTO_INCREMENT,
+  `parent_id` BIGINT NULL,
+  `nome` VARCHAR(100) NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  UNIQUE KEY `uk_disciplinas_parent_id_nome` (`parent_id`, `nome`)
+);
+
+ALTER TABLE `disciplinas` ADD FOREIGN KEY (`parent_id`) REFERENCES `disciplinas`(`id`);
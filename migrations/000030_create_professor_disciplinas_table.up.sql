@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `professor_disciplinas` (
+  `professor_id` BIGINT NOT NULL,
+  `disciplina_id` BIGINT NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`professor_id`, `disciplina_id`),
+  KEY `idx_professor_disciplinas_disciplina_id` (`disciplina_id`)
+);
+
+ALTER TABLE `professor_disciplinas` ADD FOREIGN KEY (`professor_id`) REFERENCES `professores`(`id`) ON DELETE CASCADE;
+ALTER TABLE `professor_disciplinas` ADD FOREIGN KEY (`disciplina_id`) REFERENCES `disciplinas`(`id`);
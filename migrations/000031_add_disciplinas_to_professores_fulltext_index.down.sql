@@ -0,0 +1,3 @@
+ALTER TABLE `professores` DROP INDEX `ft_professores_busca`;
+ALTER TABLE `professores` ADD FULLTEXT INDEX `ft_professores_nome_descricao` (`nome`, `descricao`);
+ALTER TABLE `professores` DROP COLUMN `disciplinas_busca`;
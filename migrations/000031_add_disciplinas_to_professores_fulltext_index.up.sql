@@ -0,0 +1,3 @@
+ALTER TABLE `professores` ADD COLUMN `disciplinas_busca` TEXT NULL;
+ALTER TABLE `professores` DROP INDEX `ft_professores_nome_descricao`;
+ALTER TABLE `professores` ADD FULLTEXT INDEX `ft_professores_busca` (`nome`, `descricao`, `disciplinas_busca`);
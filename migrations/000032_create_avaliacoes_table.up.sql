@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS `avaliacoes` (
+  `id` BIGINT PRIMARY KEY AUTO_INCREMENT,
+  `aluno_id` BIGINT NOT NULL UNIQUE,
+  `professor_id` BIGINT NOT NULL,
+  `nota` TINYINT NOT NULL,
+  `comentario` TEXT NOT NULL,
+  `resposta` TEXT NULL,
+  `respondida_em` TIMESTAMP NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  KEY `idx_avaliacoes_professor_id_created_at` (`professor_id`, `created_at`)
+);
+
+ALTER TABLE `avaliacoes` ADD FOREIGN KEY (`aluno_id`) REFERENCES `alunos`(`id`) ON DELETE CASCADE;
+ALTER TABLE `avaliacoes` ADD FOREIGN KEY (`professor_id`) REFERENCES `professores`(`id`) ON DELETE CASCADE;
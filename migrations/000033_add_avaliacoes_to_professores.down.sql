@@ -0,0 +1,3 @@
+ALTER TABLE `professores` DROP INDEX `idx_professores_avaliacao_media`;
+ALTER TABLE `professores` DROP COLUMN `avaliacoes_count`;
+ALTER TABLE `professores` DROP COLUMN `avaliacao_media`;
@@ -0,0 +1,3 @@
+ALTER TABLE `professores` ADD COLUMN `avaliacao_media` DECIMAL(3,2) NOT NULL DEFAULT 0 AFTER `duracao_aula`;
+ALTER TABLE `professores` ADD COLUMN `avaliacoes_count` INT NOT NULL DEFAULT 0 AFTER `avaliacao_media`;
+ALTER TABLE `professores` ADD INDEX `idx_professores_avaliacao_media` (`avaliacao_media`, `avaliacoes_count`);
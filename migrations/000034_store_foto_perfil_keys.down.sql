@@ -0,0 +1 @@
+UPDATE `professores` SET `foto_perfil` = CONCAT('/uploads/', `foto_perfil`) WHERE `foto_perfil` IS NOT NULL;
@@ -0,0 +1 @@
+UPDATE `professores` SET `foto_perfil` = SUBSTRING_INDEX(`foto_perfil`, '/uploads/', -1) WHERE `foto_perfil` LIKE '%/uploads/%';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `estudante_email_verifications`;
+ALTER TABLE `estudantes` DROP COLUMN `email_verified_at`;
@@ -0,0 +1,15 @@
+ALTER TABLE `estudantes` ADD COLUMN `email_verified_at` TIMESTAMP NULL AFTER `password`;
+
+DROP TABLE IF EXISTS `estudante_email_verifications`;
+CREATE TABLE IF NOT EXISTS `estudante_email_verifications` (
+  `id` BIGINT PRIMARY KEY AUTO_INCREMENT,
+  `estudante_id` BIGINT NOT NULL,
+  `token_hash` CHAR(64) NOT NULL UNIQUE,
+  `expires_at` DATETIME NOT NULL,
+  `used_at` DATETIME NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE `estudante_email_verifications` ADD FOREIGN KEY (`estudante_id`) REFERENCES `estudantes`(`id`) ON DELETE CASCADE;
+
+UPDATE `alunos` SET `estudante_id` = NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE `professores` DROP INDEX `ft_professores_busca`;
+ALTER TABLE `professores` MODIFY `nome` VARCHAR(255) NOT NULL;
+ALTER TABLE `professores` MODIFY `descricao` TEXT NOT NULL;
+ALTER TABLE `professores` MODIFY `disciplinas_busca` TEXT NULL;
+ALTER TABLE `professores` ADD FULLTEXT INDEX `ft_professores_busca` (`nome`, `descricao`, `disciplinas_busca`);
@@ -0,0 +1,5 @@
+ALTER TABLE `professores` DROP INDEX `ft_professores_busca`;
+ALTER TABLE `professores` MODIFY `nome` VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL;
+ALTER TABLE `professores` MODIFY `descricao` TEXT CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL;
+ALTER TABLE `professores` MODIFY `disciplinas_busca` TEXT CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL;
+ALTER TABLE `professores` ADD FULLTEXT INDEX `ft_professores_busca` (`nome`, `descricao`, `disciplinas_busca`);